@@ -5,218 +5,188 @@ import (
 	"fmt"
 	tgbotapi "github.com/skinass/telegram-bot-api/v5"
 	"log"
-	"net/http"
-	"os"
+	"rutubeTest/configs"
+	"rutubeTest/pkg/greeting"
+	"rutubeTest/pkg/lock"
+	"rutubeTest/pkg/notify"
+	"rutubeTest/pkg/queue"
 	"rutubeTest/pkg/user"
 	"strconv"
 	"strings"
 	"time"
 )
 
-var (
-	// Нужен для работы тг бота в локалке
-	WebhookURL = "https://5f1f-188-32-207-71.ngrok-free.app"
-
-	commandHandlers = map[string]func(tgbotapi.Update, *user.UserMysqlRepository) []tgbotapi.MessageConfig{
-		"/subscribe":   subscribeHandler,
-		"/unsubscribe": unsubscribeHandler,
-		"/start":       startHandler,
-		"/users":       usersListHandler,
-	}
-)
-
-func usersListHandler(update tgbotapi.Update, userRepo *user.UserMysqlRepository) []tgbotapi.MessageConfig {
-	users, err := userRepo.GetUsers()
+func startHandler(update tgbotapi.Update, _ []string, me *user.User, userRepo user.UserRepo) []tgbotapi.MessageConfig {
+	lang := userLanguage(update.Message.From, me)
+	err := userRepo.UpdateUser(update.Message.From.ID, update.Message.From.UserName)
 	if err != nil {
-		return nil
-	}
-
-	messages := make([]tgbotapi.MessageConfig, 0, len(users)) // Предварительное выделение памяти с нужным размером
-
-	var msg tgbotapi.MessageConfig
-	var str string
-	for _, u := range users {
-		str = "ID: " + strconv.FormatInt(u.ID, 10) +
-			" ФИО: " + u.FirstName + " " + u.MiddleName + " " + u.LastName +
-			" " + u.Birthday + " " + u.Telegram
-		msg = tgbotapi.NewMessage(
-			update.Message.Chat.ID,
-			str,
-		)
-		messages = append(messages, msg)
+		log.Println("can't link telegram:", err)
+		return reply(update, messages.T(lang, msgNotRegistered))
 	}
-
-	return messages
+	return reply(update, messages.T(lang, msgWelcome))
 }
 
-func startHandler(update tgbotapi.Update, userRepo *user.UserMysqlRepository) []tgbotapi.MessageConfig {
-	err := userRepo.UpdateUser(update.Message.From.ID, update.Message.From.UserName)
-	if err != nil {
-		msg := tgbotapi.NewMessage(
-			update.Message.Chat.ID,
-			err.Error(),
-		)
-		return []tgbotapi.MessageConfig{msg}
-	}
-	msg := tgbotapi.NewMessage(
-		update.Message.Chat.ID,
-		"Добро пожаловать. Напишите /users, чтобы увидеть всех пользователей.\n"+
-			"Напишите /subscribe или /unsubscribe, а после id для подписки отписки на пользователя.\n"+
-			"Например, /subscribe 1",
-	)
-	return []tgbotapi.MessageConfig{msg}
+func subscribeHandler(update tgbotapi.Update, args []string, me *user.User, userRepo user.UserRepo) []tgbotapi.MessageConfig {
+	return changeSubscription(update, args, me, userRepo, 1)
 }
 
-func subscribeHandler(update tgbotapi.Update, userRepo *user.UserMysqlRepository) []tgbotapi.MessageConfig {
-	userID, err := strconv.Atoi(update.Message.Text[11:])
-	if err != nil {
-		msg := tgbotapi.NewMessage(
-			update.Message.Chat.ID,
-			err.Error(),
-		)
-		return []tgbotapi.MessageConfig{msg}
-	}
+func unsubscribeHandler(update tgbotapi.Update, args []string, me *user.User, userRepo user.UserRepo) []tgbotapi.MessageConfig {
+	return changeSubscription(update, args, me, userRepo, 0)
+}
 
-	u, err := userRepo.GetUserByTelegram("@" + update.Message.From.UserName)
-	if err != nil {
-		msg := tgbotapi.NewMessage(
-			update.Message.Chat.ID,
-			err.Error(),
-		)
-		return []tgbotapi.MessageConfig{msg}
+// changeSubscription подписывает (typeOf = 1) или отписывает (typeOf = 0) автора сообщения от пользователей из args.
+// Каждый аргумент - это id или @username; результат сообщается по каждому отдельной строкой.
+func changeSubscription(update tgbotapi.Update, args []string, me *user.User, userRepo user.UserRepo, typeOf int) []tgbotapi.MessageConfig {
+	if me == nil {
+		return notLinked(update)
 	}
+	lang := userLanguage(update.Message.From, me)
 
-	subUser, err := userRepo.Subscribe(int64(userID), u.ID, 1)
-	if err != nil {
-		msg := tgbotapi.NewMessage(
-			update.Message.Chat.ID,
-			err.Error(),
-		)
-		return []tgbotapi.MessageConfig{msg}
+	lines := make([]string, 0, len(args))
+	for _, target := range args {
+		lines = append(lines, changeTargetSubscription(lang, target, me.ID, userRepo, typeOf))
 	}
 
-	msg := tgbotapi.NewMessage(
-		update.Message.Chat.ID,
-		"Вы подписались на "+subUser.Telegram,
-	)
-	return []tgbotapi.MessageConfig{msg}
+	return reply(update, strings.Join(lines, "\n"))
 }
 
-func unsubscribeHandler(update tgbotapi.Update, userRepo *user.UserMysqlRepository) []tgbotapi.MessageConfig {
-	userID, err := strconv.Atoi(update.Message.Text[13:])
+func changeTargetSubscription(lang, target string, subscriberID int64, userRepo user.UserRepo, typeOf int) string {
+	userID, err := resolveUserID(target, userRepo)
 	if err != nil {
-		msg := tgbotapi.NewMessage(
-			update.Message.Chat.ID,
-			err.Error(),
-		)
-		return []tgbotapi.MessageConfig{msg}
+		return target + ": " + errorText(lang, err)
 	}
 
-	u, err := userRepo.GetUserByTelegram("@" + update.Message.From.UserName)
+	subUser, err := userRepo.Subscribe(userID, subscriberID, typeOf)
 	if err != nil {
-		msg := tgbotapi.NewMessage(
-			update.Message.Chat.ID,
-			err.Error(),
-		)
-		return []tgbotapi.MessageConfig{msg}
+		return target + ": " + errorText(lang, err)
 	}
 
-	subUser, err := userRepo.Subscribe(int64(userID), u.ID, 0)
-	if err != nil {
-		msg := tgbotapi.NewMessage(
-			update.Message.Chat.ID,
-			err.Error(),
-		)
-		return []tgbotapi.MessageConfig{msg}
-	}
-
-	msg := tgbotapi.NewMessage(
-		update.Message.Chat.ID,
-		"Вы отписались от "+subUser.Telegram,
-	)
-	return []tgbotapi.MessageConfig{msg}
+	return subscriptionChanged(lang, subUser, typeOf)
 }
 
-func updateHandler(update tgbotapi.Update, userRepo *user.UserMysqlRepository) []tgbotapi.MessageConfig {
-	if update.Message == nil {
-		return nil // Нет сообщения для обработки
+func subscriptionChanged(lang string, subUser *user.User, typeOf int) string {
+	if typeOf == 0 {
+		return messages.T(lang, msgUnsubscribed, subUser.Telegram)
 	}
+	return messages.T(lang, msgSubscribed, subUser.Telegram)
+}
 
-	text := update.Message.Text
-	for cmd, handler := range commandHandlers {
-		if strings.HasPrefix(text, cmd) {
-			return handler(update, userRepo)
+// resolveUserID возвращает id пользователя по числовому id или по @username в телеграме.
+func resolveUserID(target string, userRepo user.UserRepo) (int64, error) {
+	if strings.HasPrefix(target, "@") {
+		if len(target) == 1 {
+			return 0, errBadTarget
 		}
+		u, err := userRepo.GetUserByTelegram(target)
+		if err != nil {
+			return 0, err
+		}
+		return u.ID, nil
 	}
 
-	return nil
+	userID, err := strconv.ParseInt(target, 10, 64)
+	if err != nil || userID <= 0 {
+		return 0, errBadTarget
+	}
+	return userID, nil
 }
 
-func StartTaskBot(ctx context.Context, botToken string, userRepo *user.UserMysqlRepository) error {
+func StartTaskBot(ctx context.Context, config configs.Config, userRepo user.UserRepo, notifyRepo notify.NotifyRepo, templates greeting.TemplateRepo, jobs queue.Queue, locker lock.Locker) error {
 
-	bot, err := tgbotapi.NewBotAPI(botToken)
+	bot, err := tgbotapi.NewBotAPI(config.Bot.Token)
 	if err != nil {
 		log.Printf("NewBotAPI failed: %s", err)
 		return err
 	}
 
-	bot.Debug = true
+	bot.Debug = config.Bot.Debug
 	fmt.Printf("Authorized on account %s\n", bot.Self.UserName)
 
-	wh, err := tgbotapi.NewWebhook(WebhookURL)
-	if err != nil {
-		log.Printf("NewWebhook failed: %s", err)
-		return err
-	}
+	commands := newRouter(bot.Self.UserName, config.Birthday.LeapDay)
+
+	// Все запросы в телеграм идут через sender, чтобы не превысить лимиты телеграма.
+	sender := newRateLimitedSender(bot, config.Bot.GlobalRate, config.Bot.ChatRate)
 
-	_, err = bot.Request(wh)
+	var updates tgbotapi.UpdatesChannel
+	switch config.Bot.Mode {
+	case configs.BotModePolling:
+		updates, err = pollingUpdates(ctx, bot, config.Bot.PollTimeout)
+	default:
+		updates, err = webhookUpdates(bot, config)
+	}
 	if err != nil {
-		log.Printf("SetWebhook failed: %s", err)
 		return err
 	}
 
-	updates := bot.ListenForWebhook("/")
-
-	http.HandleFunc("/state", func(w http.ResponseWriter, r *http.Request) {
-		_, err = w.Write([]byte("all is working"))
-		if err != nil {
-			return
-		}
-	})
-
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8081"
+	notifier := &Notifier{
+		Users:        userRepo,
+		Log:          notifyRepo,
+		Queue:        jobs,
+		Sender:       sender,
+		Location:     config.Notify.Location,
+		Calendar:     config.Notify.Calendar,
+		AdminChatIDs: config.Bot.AdminChatIDs,
+		NudgeChatID:  config.Bot.NudgeChatID,
+		Templates:    templates,
+		LeapDay:      config.Birthday.LeapDay,
+		Locker:       locker,
+	}
+
+	zones := &zoneSchedulers{
+		users:   userRepo,
+		started: make(map[string]bool),
+		start: func(ctx context.Context, timezone string) error {
+			s, err := notificationsScheduler(config, notifier, notifyRepo, locker, timezone)
+			if err != nil {
+				return err
+			}
+			go s.run(ctx)
+			return nil
+		},
 	}
-	go func() {
-		log.Fatalln("http err:", http.ListenAndServe(":"+port, nil))
-	}()
-	fmt.Println("start listen :" + port)
 
-	ticker := time.NewTicker(24 * time.Hour)
-	defer ticker.Stop()
+	// Запуск сервиса уведомлений в горутине, отдельно для каждого часового пояса
+	go zones.run(ctx)
 
-	// Запуск сервиса уведомлений в горутине
+	// Повтор неудачных отправок в горутине
 	go func() {
+		ticker := time.NewTicker(retryInterval)
+		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
-				CheckAndSendNotifications(userRepo, bot)
+				notifier.RetryNotifications()
 			case <-ctx.Done():
 				return
 			}
 		}
 	}()
 
+	// Воркеры, которые забирают уведомления из очереди и отправляют их
+	go notifier.RunWorkers(ctx, config.Queue.Workers)
+
 	for {
 		select {
-		case update := <-updates:
+		case update, ok := <-updates:
+			if !ok {
+				// Источник обновлений закрывается только при отмене контекста.
+				updates = nil
+				continue
+			}
 			log.Printf("upd: %#v\n", update)
-			messages := updateHandler(update, userRepo)
+			messages := commands.handle(update, userRepo)
 			for _, v := range messages {
-				_, err = bot.Send(v)
+				// Ошибка отправки одного ответа, например если пользователь заблокировал бота, не должна останавливать бота.
+				_, err = sender.Send(v)
 				if err != nil {
-					return err
+					log.Println("reply failed:", err)
+				}
+			}
+			// Ответы на нажатия кнопок телеграм возвращает не как Message, поэтому они идут через Request.
+			for _, v := range callbackHandler(update, userRepo) {
+				_, err = sender.Request(v)
+				if err != nil {
+					log.Println("callback answer failed:", err)
 				}
 			}
 		case <-ctx.Done():
@@ -229,36 +199,3 @@ func StartTaskBot(ctx context.Context, botToken string, userRepo *user.UserMysql
 		}
 	}
 }
-
-func CheckAndSendNotifications(userRepo *user.UserMysqlRepository, bot *tgbotapi.BotAPI) {
-	today := time.Now()
-	month := int(today.Month())
-	day := today.Day()
-
-	users, err := userRepo.GetUserByBirthday(month, day)
-	if err != nil {
-		fmt.Println("Error fetching users:", err)
-		return
-	}
-
-	for _, u := range users {
-		subscribers, err := userRepo.GetSubscribedUsers(u.ID)
-		if err != nil {
-			fmt.Println("Error fetching subscribers:", err)
-			continue
-		}
-
-		var str string
-		for _, sub := range subscribers {
-			str = u.FirstName + " " + u.MiddleName + " " + u.LastName
-			sendTelegramNotification(bot, sub.TelegramID, str)
-		}
-	}
-}
-
-func sendTelegramNotification(bot *tgbotapi.BotAPI, chatID int64, employeeName string) {
-	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Сегодня день рождения у %s! Поздравьте его!", employeeName))
-	if _, err := bot.Send(msg); err != nil {
-		fmt.Println("Error sending Telegram message:", err)
-	}
-}
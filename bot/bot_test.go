@@ -0,0 +1,950 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"rutubeTest/pkg/calendar"
+	"rutubeTest/pkg/greeting"
+	"rutubeTest/pkg/notify"
+	"rutubeTest/pkg/queue"
+	"rutubeTest/pkg/user"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const (
+	testChatID  int64 = 42
+	testBotName       = "birthday_bot"
+)
+
+func newUpdate(text string) tgbotapi.Update {
+	return tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			Text: text,
+			Chat: &tgbotapi.Chat{ID: testChatID},
+			From: &tgbotapi.User{ID: 100, UserName: "tester"},
+		},
+	}
+}
+
+func TestStartHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+
+	tests := []struct {
+		name       string
+		setupMocks func()
+		wantText   string
+	}{
+		{
+			name: "Успешная привязка телеграма",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+				mockRepo.EXPECT().UpdateUser(int64(100), "tester").Return(nil)
+			},
+			wantText: "Добро пожаловать. Напишите /users, чтобы увидеть всех пользователей.\n" +
+				"Напишите /subscribe или /unsubscribe, а после id или @username для подписки отписки на пользователя.\n" +
+				"Например, /subscribe 1 @colleague\n" +
+				"Все команды: /help",
+		},
+		{
+			name: "Ошибка при привязке телеграма",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+				mockRepo.EXPECT().UpdateUser(int64(100), "tester").Return(fmt.Errorf("no rows updated"))
+			},
+			wantText: "Ваш телеграм не найден среди зарегистрированных пользователей. Укажите его при регистрации.",
+		},
+		{
+			name: "Ответ на выбранном языке",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1, Language: "en"}, nil)
+				mockRepo.EXPECT().UpdateUser(int64(100), "tester").Return(nil)
+			},
+			wantText: "Welcome. Send /users to see all users.\n" +
+				"Send /subscribe or /unsubscribe followed by an id or @username to subscribe to a user or unsubscribe.\n" +
+				"For example, /subscribe 1 @colleague\n" +
+				"All commands: /help",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			messages := newRouter(testBotName, user.LeapDayMar1).handle(newUpdate("/start"), mockRepo)
+
+			assert.Len(t, messages, 1)
+			assert.Equal(t, testChatID, messages[0].ChatID)
+			assert.Equal(t, tc.wantText, messages[0].Text)
+		})
+	}
+}
+
+func TestSubscribeHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+
+	tests := []struct {
+		name       string
+		text       string
+		setupMocks func()
+		wantText   string
+	}{
+		{
+			name: "Успешная подписка",
+			text: "/subscribe 2",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+				mockRepo.EXPECT().Subscribe(int64(2), int64(1), 1).Return(&user.User{ID: 2, Telegram: "@john"}, nil)
+			},
+			wantText: "Вы подписались на @john",
+		},
+		{
+			name: "Успешная отписка",
+			text: "/unsubscribe 2",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+				mockRepo.EXPECT().Subscribe(int64(2), int64(1), 0).Return(&user.User{ID: 2, Telegram: "@john"}, nil)
+			},
+			wantText: "Вы отписались от @john",
+		},
+		{
+			name: "Некорректный id при подписке",
+			text: "/subscribe abc",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+			},
+			wantText: "abc: Нужен положительный id или @username.",
+		},
+		{
+			name: "Подписка по @username",
+			text: "/subscribe @john",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+				mockRepo.EXPECT().GetUserByTelegram("@john").Return(&user.User{ID: 2, Telegram: "@john"}, nil)
+				mockRepo.EXPECT().Subscribe(int64(2), int64(1), 1).Return(&user.User{ID: 2, Telegram: "@john"}, nil)
+			},
+			wantText: "Вы подписались на @john",
+		},
+		{
+			name: "Подписка на несколько пользователей с ошибками",
+			text: "/subscribe 2 @ghost @ 3",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+				mockRepo.EXPECT().Subscribe(int64(2), int64(1), 1).Return(&user.User{ID: 2, Telegram: "@john"}, nil)
+				mockRepo.EXPECT().GetUserByTelegram("@ghost").Return(nil, user.ErrNoUser)
+				mockRepo.EXPECT().Subscribe(int64(3), int64(1), 1).Return(nil, user.ErrExists)
+			},
+			wantText: "Вы подписались на @john\n" +
+				"@ghost: Пользователь не найден.\n" +
+				"@: Нужен положительный id или @username.\n" +
+				"3: Подписка уже оформлена.",
+		},
+		{
+			name: "Отписка от нескольких пользователей по @username",
+			text: "/unsubscribe @john @jane",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+				mockRepo.EXPECT().GetUserByTelegram("@john").Return(&user.User{ID: 2}, nil)
+				mockRepo.EXPECT().Subscribe(int64(2), int64(1), 0).Return(&user.User{ID: 2, Telegram: "@john"}, nil)
+				mockRepo.EXPECT().GetUserByTelegram("@jane").Return(&user.User{ID: 3}, nil)
+				mockRepo.EXPECT().Subscribe(int64(3), int64(1), 0).Return(&user.User{ID: 3, Telegram: "@jane"}, nil)
+			},
+			wantText: "Вы отписались от @john\nВы отписались от @jane",
+		},
+		{
+			name: "Подписчик не зарегистрирован",
+			text: "/subscribe 2",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+			},
+			wantText: "Ваш телеграм не найден среди зарегистрированных пользователей.",
+		},
+		{
+			name: "Пользователь для отписки не найден",
+			text: "/unsubscribe 3",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+				mockRepo.EXPECT().Subscribe(int64(3), int64(1), 0).Return(nil, user.ErrNoUser)
+			},
+			wantText: "3: Пользователь не найден.",
+		},
+		{
+			name: "Повторная подписка",
+			text: "/subscribe 2",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+				mockRepo.EXPECT().Subscribe(int64(2), int64(1), 1).Return(nil, user.ErrExists)
+			},
+			wantText: "2: Подписка уже оформлена.",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			messages := newRouter(testBotName, user.LeapDayMar1).handle(newUpdate(tc.text), mockRepo)
+
+			assert.Len(t, messages, 1)
+			assert.Equal(t, testChatID, messages[0].ChatID)
+			assert.Equal(t, tc.wantText, messages[0].Text)
+		})
+	}
+}
+
+func TestUsersListHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+
+	users := []user.User{
+		{ID: 1, FirstName: "Test", LastName: "Tester", Birthday: "1995-05-05", Telegram: "@tester"},
+		{ID: 2, FirstName: "John", MiddleName: "M", LastName: "Doe", Birthday: "1990-01-01", Telegram: "@john"},
+		{ID: 3, FirstName: "Jane", MiddleName: "D", LastName: "Smith", Birthday: "1991-02-02", Telegram: "@jane"},
+	}
+	for i := 4; i <= 12; i++ {
+		users = append(users, user.User{ID: int64(i), FirstName: "User", LastName: strconv.Itoa(i)})
+	}
+
+	tests := []struct {
+		name         string
+		text         string
+		setupMocks   func()
+		wantText     string
+		wantKeyboard *tgbotapi.InlineKeyboardMarkup
+	}{
+		{
+			name: "Первая страница с кнопками подписки",
+			text: "/users",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUsers().Return(users, nil)
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&users[0], nil)
+				mockRepo.EXPECT().GetSubscriptions(int64(1)).Return([]user.User{users[1]}, nil)
+			},
+			wantText: "Пользователи (страница 1 из 2):\n" +
+				"ID: 1 ФИО: Test  Tester 1995-05-05 @tester\n" +
+				"ID: 2 ФИО: John M Doe 1990-01-01 @john\n" +
+				"ID: 3 ФИО: Jane D Smith 1991-02-02 @jane\n" +
+				"ID: 4 ФИО: User  4  \n" +
+				"ID: 5 ФИО: User  5  \n" +
+				"ID: 6 ФИО: User  6  \n" +
+				"ID: 7 ФИО: User  7  \n" +
+				"ID: 8 ФИО: User  8  \n" +
+				"ID: 9 ФИО: User  9  \n" +
+				"ID: 10 ФИО: User  10  ",
+		},
+		{
+			name: "Последняя страница без привязанного телеграма",
+			text: "/users 2",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUsers().Return(users, nil)
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+			},
+			wantText: "Пользователи (страница 2 из 2):\n" +
+				"ID: 11 ФИО: User  11  \n" +
+				"ID: 12 ФИО: User  12  ",
+			wantKeyboard: &tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{
+				tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", "users:0")),
+			}},
+		},
+		{
+			name: "Некорректный номер страницы",
+			text: "/users 0",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+			},
+			wantText: "Номер страницы должен быть положительным числом.",
+		},
+		{
+			name: "Ошибка при получении пользователей",
+			text: "/users",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+				mockRepo.EXPECT().GetUsers().Return(nil, user.ErrNoUser)
+			},
+			wantText: "Пользователь не найден.",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			messages := newRouter(testBotName, user.LeapDayMar1).handle(newUpdate(tc.text), mockRepo)
+
+			assert.Len(t, messages, 1)
+			assert.Equal(t, testChatID, messages[0].ChatID)
+			assert.Equal(t, tc.wantText, messages[0].Text)
+			if tc.wantKeyboard != nil {
+				assert.Equal(t, *tc.wantKeyboard, messages[0].ReplyMarkup)
+			}
+		})
+	}
+}
+
+func TestUsersPageKeyboard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+
+	mockRepo.EXPECT().GetUsers().Return([]user.User{
+		{ID: 1, FirstName: "Test", LastName: "Tester"},
+		{ID: 2, FirstName: "John", LastName: "Doe"},
+		{ID: 3, FirstName: "Jane", LastName: "Smith"},
+	}, nil)
+	mockRepo.EXPECT().GetSubscriptions(int64(1)).Return([]user.User{{ID: 2}}, nil)
+
+	_, keyboard, err := usersPage(mockRepo, &user.User{ID: 1}, "ru", 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("✅ John Doe", "unsub:2:0")),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("➕ Jane Smith", "sub:3:0")),
+	}, keyboard.InlineKeyboard)
+}
+
+func TestCallbackHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+
+	newCallback := func(data string) tgbotapi.Update {
+		return tgbotapi.Update{
+			CallbackQuery: &tgbotapi.CallbackQuery{
+				ID:      "cb",
+				From:    &tgbotapi.User{ID: 100, UserName: "tester"},
+				Message: &tgbotapi.Message{MessageID: 7, Chat: &tgbotapi.Chat{ID: testChatID}},
+				Data:    data,
+			},
+		}
+	}
+	users := []user.User{{ID: 1, FirstName: "Test"}, {ID: 2, FirstName: "John", Telegram: "@john"}}
+
+	tests := []struct {
+		name       string
+		update     tgbotapi.Update
+		setupMocks func()
+		wantAnswer string
+		wantEdit   bool
+	}{
+		{
+			name:   "Подписка кнопкой",
+			update: newCallback("sub:2:0"),
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&users[0], nil)
+				mockRepo.EXPECT().Subscribe(int64(2), int64(1), 1).Return(&users[1], nil)
+				mockRepo.EXPECT().GetUsers().Return(users, nil)
+				mockRepo.EXPECT().GetSubscriptions(int64(1)).Return([]user.User{users[1]}, nil)
+			},
+			wantAnswer: "Вы подписались на @john",
+			wantEdit:   true,
+		},
+		{
+			name:   "Отписка кнопкой",
+			update: newCallback("unsub:2:0"),
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&users[0], nil)
+				mockRepo.EXPECT().Subscribe(int64(2), int64(1), 0).Return(&users[1], nil)
+				mockRepo.EXPECT().GetUsers().Return(users, nil)
+				mockRepo.EXPECT().GetSubscriptions(int64(1)).Return(nil, user.ErrNoUser)
+			},
+			wantAnswer: "Вы отписались от @john",
+			wantEdit:   true,
+		},
+		{
+			name:   "Переход на страницу",
+			update: newCallback("users:1"),
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUsers().Return(users, nil)
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&users[0], nil)
+				mockRepo.EXPECT().GetSubscriptions(int64(1)).Return(nil, user.ErrNoUser)
+			},
+			wantEdit: true,
+		},
+		{
+			name:   "Неизвестные данные кнопки",
+			update: newCallback("garbage"),
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			result := callbackHandler(tc.update, mockRepo)
+
+			assert.Equal(t, tgbotapi.NewCallback("cb", tc.wantAnswer), result[0])
+			if tc.wantEdit {
+				assert.Len(t, result, 2)
+				edit, ok := result[1].(tgbotapi.EditMessageTextConfig)
+				assert.True(t, ok)
+				assert.Equal(t, 7, edit.MessageID)
+			} else {
+				assert.Len(t, result, 1)
+			}
+		})
+	}
+
+	assert.Nil(t, callbackHandler(newUpdate("/users"), mockRepo))
+}
+
+func TestRouter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+
+	tests := []struct {
+		name       string
+		text       string
+		setupMocks func()
+		wantText   string
+	}{
+		{
+			name: "Команда без аргумента",
+			text: "/subscribe",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+			},
+			wantText: "Использование: /subscribe <id|@username> ...",
+		},
+		{
+			name: "Лишние аргументы",
+			text: "/users 1 2",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+			},
+			wantText: "Использование: /users [страница]",
+		},
+		{
+			name: "Команда с похожим префиксом не путается с /subscribe",
+			text: "/subscribers",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+			},
+			wantText: "Неизвестная команда. Напишите /help, чтобы увидеть список команд.",
+		},
+		{
+			name: "Ответ на языке, выбранном в /language",
+			text: "/unknown",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1, Language: "en"}, nil)
+			},
+			wantText: "Unknown command. Send /help to see the list of commands.",
+		},
+		{
+			name: "Команда с именем бота из группы",
+			text: "/Subscribe@Birthday_Bot   2",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+				mockRepo.EXPECT().Subscribe(int64(2), int64(1), 1).Return(&user.User{ID: 2, Telegram: "@john"}, nil)
+			},
+			wantText: "Вы подписались на @john",
+		},
+		{
+			name: "Справка по командам",
+			text: "/help",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+			},
+			wantText: "Доступные команды:\n" +
+				"/start - привязать телеграм к учётной записи\n" +
+				"/users [страница] - список всех пользователей с кнопками подписки\n" +
+				"/subscribe <id|@username> ... - подписаться на день рождения пользователя\n" +
+				"/unsubscribe <id|@username> ... - отписаться от дня рождения пользователя\n" +
+				"/remind <id|@username> <дней> ... - за сколько дней до дня рождения напоминать, 0 - в сам день\n" +
+				"/congrats <id|@username> [anon] <текст> - оставить поздравление, которое бот перешлёт в день рождения; anon - без имени\n" +
+				"/timezone [часовой пояс] - мой часовой пояс, например Asia/Vladivostok\n" +
+				"/language [ru|en|auto] - язык сообщений бота, auto - язык телеграма\n" +
+				"/settings [hour|quiet|mute|digest|summary|greet ...] - время, тихие часы и формат напоминаний\n" +
+				"/mysubscriptions - на кого я подписан и когда у них дни рождения\n" +
+				"/mysubscribers - кто подписан на меня\n" +
+				"/upcoming [дней] [my] - ближайшие дни рождения, my - только из моих подписок\n" +
+				"/help - список команд",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			messages := newRouter(testBotName, user.LeapDayMar1).handle(newUpdate(tc.text), mockRepo)
+
+			assert.Len(t, messages, 1)
+			assert.Equal(t, tc.wantText, messages[0].Text)
+		})
+	}
+}
+
+func TestRouterIgnoresMessages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+
+	assert.Nil(t, newRouter(testBotName, user.LeapDayMar1).handle(tgbotapi.Update{}, mockRepo))
+	assert.Nil(t, newRouter(testBotName, user.LeapDayMar1).handle(newUpdate("просто текст"), mockRepo))
+	assert.Nil(t, newRouter(testBotName, user.LeapDayMar1).handle(newUpdate("/users@other_bot"), mockRepo))
+}
+
+func TestCheckAndSendNotifications(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+	mockNotify := notify.NewMockNotifyRepo(ctrl)
+	mockQueue := queue.NewMockQueue(ctrl)
+	mockSender := NewMockSender(ctrl)
+
+	now = func() time.Time { return time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+	today := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+	sendAt := 9 * time.Hour
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	assert.NoError(t, err)
+	la := time.Date(2024, 6, 10, 0, 0, 0, 0, losAngeles)
+
+	birthdayUser := user.User{ID: 1, FirstName: "John", MiddleName: "M", LastName: "Doe", Birthday: "1990-06-10"}
+	weekUser := user.User{ID: 4, FirstName: "Jane", LastName: "Smith", Birthday: "1991-06-17"}
+
+	notification := func(userID int64, sub user.User, day time.Time, daysBefore int, text string) *notify.Notification {
+		return &notify.Notification{UserID: userID, SubscriberID: sub.ID, Day: day, DaysBefore: daysBefore, ChatID: sub.TelegramID, Text: text}
+	}
+	// payload - задача в очереди для уведомления n, записанного в журнал под номером id.
+	payload := func(n *notify.Notification, id int64) []byte {
+		job := *n
+		job.ID = id
+		data, err := json.Marshal(&job)
+		assert.NoError(t, err)
+		return data
+	}
+	const johnToday = "Сегодня день рождения у John M Doe! Не забудьте поздравить!"
+	sub2 := user.User{ID: 2, TelegramID: 200}
+	sub3 := user.User{ID: 3, TelegramID: 300}
+
+	tests := []struct {
+		name        string
+		timezone    string
+		day         time.Time
+		nudgeChatID int64
+		setupMocks  func()
+		wantErr     bool
+	}{
+		{
+			name: "Уведомления отправляются всем подписчикам",
+			day:  today,
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUpcomingBirthdays(today.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return([]user.User{birthdayUser}, nil)
+				subscribers := []user.User{sub2, sub3}
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(1), 0, "").Return(subscribers, nil)
+				for i, sub := range subscribers {
+					id := int64(i + 10)
+					n := notification(1, sub, today, 0, johnToday)
+					mockNotify.EXPECT().CreateNotification(n).Return(id, nil)
+					mockQueue.EXPECT().Enqueue(payload(n, id)).Return(strconv.Itoa(i+1), nil)
+				}
+			},
+		},
+		{
+			name: "Напоминание заранее уходит только тем, кто просил",
+			day:  today,
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUpcomingBirthdays(today.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return([]user.User{birthdayUser, weekUser}, nil)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(1), 0, "").Return(nil, user.ErrNoUser)
+				janeWeek := "Через 7 дн. (17.06) день рождения у Jane Smith. Самое время подготовить подарок!"
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(4), 7, "").Return([]user.User{sub2}, nil)
+				n := notification(4, sub2, today, 7, janeWeek)
+				mockNotify.EXPECT().CreateNotification(n).Return(int64(10), nil)
+				mockQueue.EXPECT().Enqueue(payload(n, 10)).Return("1", nil)
+			},
+		},
+		{
+			name: "Уже отправленное уведомление не дублируется",
+			day:  today,
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUpcomingBirthdays(today.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return([]user.User{birthdayUser}, nil)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(1), 0, "").Return([]user.User{sub2}, nil)
+				mockNotify.EXPECT().CreateNotification(notification(1, sub2, today, 0, johnToday)).Return(int64(0), notify.ErrDuplicate)
+			},
+		},
+		{
+			name: "Ошибка журнала не даёт поставить уведомление в очередь",
+			day:  today,
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUpcomingBirthdays(today.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return([]user.User{birthdayUser}, nil)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(1), 0, "").Return([]user.User{sub2}, nil)
+				mockNotify.EXPECT().CreateNotification(notification(1, sub2, today, 0, johnToday)).Return(int64(0), fmt.Errorf("database error"))
+			},
+		},
+		{
+			name: "Недоступная очередь откладывает уведомление и не прерывает рассылку",
+			day:  today,
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUpcomingBirthdays(today.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return([]user.User{birthdayUser}, nil)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(1), 0, "").Return([]user.User{sub2, sub3}, nil)
+				mockNotify.EXPECT().CreateNotification(notification(1, sub2, today, 0, johnToday)).Return(int64(10), nil)
+				mockQueue.EXPECT().Enqueue(gomock.Any()).Return("", fmt.Errorf("connection refused"))
+				mockNotify.EXPECT().MarkRetry(int64(10), now().Add(retryBaseDelay), "connection refused").Return(nil)
+				mockNotify.EXPECT().CreateNotification(notification(1, sub3, today, 0, johnToday)).Return(int64(11), nil)
+				mockQueue.EXPECT().Enqueue(gomock.Any()).Return("1", nil)
+			},
+		},
+		{
+			name: "Пропущенный день догоняется с текстом на сегодня",
+			day:  yesterday,
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUpcomingBirthdays(yesterday.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return([]user.User{birthdayUser}, nil)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(1), 1, "").Return([]user.User{sub2}, nil)
+				n := notification(1, sub2, yesterday, 1, johnToday)
+				mockNotify.EXPECT().CreateNotification(n).Return(int64(10), nil)
+				mockQueue.EXPECT().Enqueue(payload(n, 10)).Return("1", nil)
+			},
+		},
+		{
+			name:        "Подписчику без телеграма не пишем, а просим привязать его в общем чате",
+			day:         today,
+			nudgeChatID: 900,
+			setupMocks: func() {
+				unlinked := user.User{ID: 5, Telegram: "@nolink"}
+				mockRepo.EXPECT().GetUpcomingBirthdays(today.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return([]user.User{birthdayUser, weekUser}, nil)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(1), 0, "").Return([]user.User{unlinked, sub2}, nil)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(4), 7, "").Return([]user.User{unlinked}, nil)
+
+				n := notification(1, unlinked, today, 0, johnToday)
+				n.Status = notify.StatusUnlinked
+				n.LastError = "telegram is not linked"
+				mockNotify.EXPECT().CreateNotification(n).Return(int64(10), nil)
+
+				linked := notification(1, sub2, today, 0, johnToday)
+				mockNotify.EXPECT().CreateNotification(linked).Return(int64(11), nil)
+				mockQueue.EXPECT().Enqueue(payload(linked, 11)).Return("1", nil)
+
+				mockNotify.EXPECT().CreateNotification(gomock.Any()).Return(int64(12), nil)
+
+				mockSender.EXPECT().Send(tgbotapi.NewMessage(900, "@nolink, вам пришли напоминания о днях рождения коллег, но бот не может вам написать. "+
+					"Откройте бота и отправьте /start, чтобы получать их.")).Return(tgbotapi.Message{}, nil)
+			},
+		},
+		{
+			name:        "Повторный запуск не просит привязать телеграм ещё раз",
+			day:         today,
+			nudgeChatID: 900,
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUpcomingBirthdays(today.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return([]user.User{birthdayUser}, nil)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(1), 0, "").Return([]user.User{{ID: 5, Telegram: "@nolink"}}, nil)
+				mockNotify.EXPECT().CreateNotification(gomock.Any()).Return(int64(0), notify.ErrDuplicate)
+			},
+		},
+		{
+			name:     "Дни до дня рождения считаются по часовому поясу именинника",
+			timezone: "America/Los_Angeles",
+			day:      la,
+			setupMocks: func() {
+				// В 9:00 10 июня в Лос-Анджелесе во Владивостоке уже 11 июня.
+				vladivostokUser := user.User{ID: 6, FirstName: "Ivan", LastName: "Petrov", Birthday: "1990-06-11", TimeZone: "Asia/Vladivostok"}
+				mockRepo.EXPECT().GetUpcomingBirthdays(la.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).
+					Return([]user.User{birthdayUser, vladivostokUser}, nil)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(1), 0, "America/Los_Angeles").Return(nil, user.ErrNoUser)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(6), 0, "America/Los_Angeles").Return([]user.User{sub2}, nil)
+				// 9:00 в Лос-Анджелесе ещё не наступило, поэтому уведомление ждёт своего времени.
+				n := notification(6, sub2, la, 0, "Сегодня день рождения у Ivan Petrov! Не забудьте поздравить!")
+				n.Status = notify.StatusScheduled
+				n.NextAttempt = la.Add(sendAt)
+				mockNotify.EXPECT().CreateNotification(n).Return(int64(10), nil)
+			},
+		},
+		{
+			name: "Нет ближайших дней рождения",
+			day:  today,
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUpcomingBirthdays(today.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return(nil, user.ErrNoUser)
+			},
+		},
+		{
+			name: "Ошибка чтения именинников",
+			day:  today,
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUpcomingBirthdays(today.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return(nil, fmt.Errorf("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			notifier := &Notifier{Users: mockRepo, Log: mockNotify, Queue: mockQueue, Sender: mockSender, Location: time.UTC, NudgeChatID: tc.nudgeChatID}
+			err := notifier.CheckAndSendNotifications(tc.timezone, tc.day.Add(sendAt))
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestCheckAndSendNotificationsTemplates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+	mockNotify := notify.NewMockNotifyRepo(ctrl)
+	mockQueue := queue.NewMockQueue(ctrl)
+	mockTemplates := greeting.NewMockTemplateRepo(ctrl)
+
+	now = func() time.Time { return time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+	today := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	birthdayUser := user.User{ID: 1, FirstName: "John", LastName: "Doe", Birthday: "1990-06-10", Department: "Dev"}
+	qa := user.User{ID: 2, TelegramID: 200, Department: "QA"}
+	english := user.User{ID: 3, TelegramID: 300, Language: "en"}
+
+	tests := []struct {
+		name       string
+		setupMocks func()
+		wantTexts  map[int64]string
+	}{
+		{
+			name: "Шаблон выбирается по языку и отделу подписчика",
+			setupMocks: func() {
+				mockTemplates.EXPECT().GetTemplates().Return([]greeting.Template{
+					{Kind: greeting.KindToday, Language: "ru", Team: "QA", Text: "{{.FirstName}} из {{.Department}} празднует {{.Age}}-летие!"},
+				}, nil)
+			},
+			wantTexts: map[int64]string{
+				2: "John из Dev празднует 34-летие!",
+				3: "Today is John Doe's birthday! Don't forget to congratulate them!",
+			},
+		},
+		{
+			name: "Без шаблонов в базе используются шаблоны по умолчанию",
+			setupMocks: func() {
+				mockTemplates.EXPECT().GetTemplates().Return(nil, fmt.Errorf("database error"))
+			},
+			wantTexts: map[int64]string{
+				2: "Сегодня день рождения у John Doe! Не забудьте поздравить!",
+				3: "Today is John Doe's birthday! Don't forget to congratulate them!",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			mockRepo.EXPECT().GetUpcomingBirthdays(today.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return([]user.User{birthdayUser}, nil)
+			mockRepo.EXPECT().GetSubscribersToRemind(int64(1), 0, "").Return([]user.User{qa, english}, nil)
+			for _, sub := range []user.User{qa, english} {
+				n := &notify.Notification{UserID: 1, SubscriberID: sub.ID, Day: today, ChatID: sub.TelegramID, Text: tc.wantTexts[sub.ID]}
+				mockNotify.EXPECT().CreateNotification(n).Return(int64(0), notify.ErrDuplicate)
+			}
+
+			notifier := &Notifier{Users: mockRepo, Log: mockNotify, Queue: mockQueue, Templates: mockTemplates, Location: time.UTC}
+			notifier.CheckAndSendNotifications("", today.Add(9*time.Hour))
+		})
+	}
+}
+
+func TestCheckAndSendNotificationsWorkCalendar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+	mockNotify := notify.NewMockNotifyRepo(ctrl)
+	mockQueue := queue.NewMockQueue(ctrl)
+
+	friday := time.Date(2024, 6, 14, 0, 0, 0, 0, time.UTC)
+	saturday := friday.AddDate(0, 0, 1)
+	sendAt := 9 * time.Hour
+	cal, err := calendar.New([]string{"2024-06-12"}, nil)
+	assert.NoError(t, err)
+
+	saturdayUser := user.User{ID: 1, FirstName: "John", LastName: "Doe", Birthday: "1990-06-15"}
+	holidayUser := user.User{ID: 2, FirstName: "Jane", LastName: "Smith", Birthday: "1991-06-12"}
+	sub := user.User{ID: 3, TelegramID: 300}
+	notification := func(userID int64, day time.Time, daysBefore int, text string) *notify.Notification {
+		return &notify.Notification{UserID: userID, SubscriberID: sub.ID, Day: day, DaysBefore: daysBefore, ChatID: sub.TelegramID, Text: text}
+	}
+
+	tests := []struct {
+		name       string
+		day        time.Time
+		setupMocks func()
+	}{
+		{
+			name: "Поздравление с субботы приходит в пятницу",
+			day:  friday,
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUpcomingBirthdays(friday.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return([]user.User{saturdayUser}, nil)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(1), 0, "").Return([]user.User{sub}, nil)
+				n := notification(1, friday, 1, "В субботу (15.06) день рождения у John Doe, это нерабочий день. Поздравьте сегодня!")
+				mockNotify.EXPECT().CreateNotification(n).Return(int64(10), nil)
+				mockQueue.EXPECT().Enqueue(gomock.Any()).Return("1", nil)
+				// Тому же подписчику напоминание за день уже не нужно.
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(1), 1, "").Return([]user.User{sub}, nil)
+				mockNotify.EXPECT().CreateNotification(notification(1, friday, 1, "Завтра (15.06) день рождения у John Doe. Не забудьте поздравить!")).
+					Return(int64(0), notify.ErrDuplicate)
+			},
+		},
+		{
+			name: "В сам нерабочий день поздравление не дублируется",
+			day:  saturday,
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUpcomingBirthdays(saturday.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return([]user.User{saturdayUser}, nil)
+			},
+		},
+		{
+			name: "Праздник в середине недели переносится на предыдущий рабочий день",
+			day:  friday.AddDate(0, 0, -3),
+			setupMocks: func() {
+				tuesday := friday.AddDate(0, 0, -3)
+				mockRepo.EXPECT().GetUpcomingBirthdays(tuesday.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return([]user.User{holidayUser}, nil)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(2), 0, "").Return(nil, user.ErrNoUser)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(2), 1, "").Return(nil, user.ErrNoUser)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			now = func() time.Time { return tc.day.Add(sendAt) }
+			defer func() { now = time.Now }()
+			tc.setupMocks()
+
+			notifier := &Notifier{Users: mockRepo, Log: mockNotify, Queue: mockQueue, Location: time.UTC, Calendar: cal}
+			notifier.CheckAndSendNotifications("", tc.day.Add(sendAt))
+		})
+	}
+}
+
+func TestCheckAndSendNotificationsSettings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+	mockNotify := notify.NewMockNotifyRepo(ctrl)
+	mockQueue := queue.NewMockQueue(ctrl)
+	mockSender := NewMockSender(ctrl)
+
+	// Рассылка планируется в полночь, а уходит в 9:00.
+	today := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return today }
+	defer func() { now = time.Now }()
+	at := today.Add(9 * time.Hour)
+
+	john := user.User{ID: 1, FirstName: "John", LastName: "Doe", Birthday: "1990-06-10"}
+	jane := user.User{ID: 2, FirstName: "Jane", LastName: "Smith", Birthday: "1991-06-11"}
+	const johnToday = "Сегодня день рождения у John Doe! Не забудьте поздравить!"
+	const janeTomorrow = "Завтра (11.06) день рождения у Jane Smith. Не забудьте поздравить!"
+	scheduled := func(userID int64, sub user.User, daysBefore int, text string, deliverAt time.Time) *notify.Notification {
+		return &notify.Notification{UserID: userID, SubscriberID: sub.ID, Day: today, DaysBefore: daysBefore, ChatID: sub.TelegramID,
+			Text: text, Status: notify.StatusScheduled, NextAttempt: deliverAt}
+	}
+
+	tests := []struct {
+		name        string
+		nudgeChatID int64
+		setupMocks  func()
+	}{
+		{
+			name: "Уведомление ждёт часа, выбранного подписчиком, а отключившие напоминания и выбравшие сводку его не получают",
+			setupMocks: func() {
+				early := user.User{ID: 3, TelegramID: 300, Settings: &user.Settings{NotifyHour: 7}}
+				quiet := user.User{ID: 4, TelegramID: 400, Settings: &user.Settings{NotifyHour: user.DefaultHour, QuietFrom: 8, QuietTo: 12}}
+				muted := user.User{ID: 5, TelegramID: 500, Settings: &user.Settings{NotifyHour: user.DefaultHour, MutedUntil: "2024-06-10"}}
+				plain := user.User{ID: 6, TelegramID: 600}
+				weekly := user.User{ID: 7, TelegramID: 700, Settings: &user.Settings{NotifyHour: user.DefaultHour, Summary: user.SummaryWeekly}}
+
+				mockRepo.EXPECT().GetUpcomingBirthdays(today.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return([]user.User{john}, nil)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(1), 0, "").Return([]user.User{early, quiet, muted, plain, weekly}, nil)
+				mockNotify.EXPECT().CreateNotification(scheduled(1, early, 0, johnToday, today.Add(7*time.Hour))).Return(int64(10), nil)
+				mockNotify.EXPECT().CreateNotification(scheduled(1, quiet, 0, johnToday, today.Add(12*time.Hour))).Return(int64(11), nil)
+				mockNotify.EXPECT().CreateNotification(scheduled(1, plain, 0, johnToday, at)).Return(int64(12), nil)
+			},
+		},
+		{
+			name: "Дайджест собирает напоминания в одно сообщение",
+			setupMocks: func() {
+				digest := user.User{ID: 3, TelegramID: 300, Settings: &user.Settings{NotifyHour: user.DefaultHour, Digest: true}}
+
+				mockRepo.EXPECT().GetUpcomingBirthdays(today.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return([]user.User{john, jane}, nil)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(1), 0, "").Return([]user.User{digest}, nil)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(2), 1, "").Return([]user.User{digest}, nil)
+
+				carrier := scheduled(1, digest, 0, "Напоминания о днях рождения:\n"+johnToday+"\n"+janeTomorrow, at)
+				mockNotify.EXPECT().CreateNotification(carrier).Return(int64(10), nil)
+				rest := &notify.Notification{UserID: 2, SubscriberID: 3, Day: today, DaysBefore: 1, ChatID: 300, Text: janeTomorrow, Status: notify.StatusDigest}
+				mockNotify.EXPECT().CreateNotification(rest).Return(int64(11), nil)
+			},
+		},
+		{
+			name: "Если дайджест уже начат, его несёт следующее новое уведомление",
+			setupMocks: func() {
+				digest := user.User{ID: 3, TelegramID: 300, Settings: &user.Settings{NotifyHour: user.DefaultHour, Digest: true}}
+
+				mockRepo.EXPECT().GetUpcomingBirthdays(today.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return([]user.User{john, jane}, nil)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(1), 0, "").Return([]user.User{digest}, nil)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(2), 1, "").Return([]user.User{digest}, nil)
+
+				mockNotify.EXPECT().CreateNotification(gomock.Any()).Return(int64(0), notify.ErrDuplicate)
+				mockNotify.EXPECT().CreateNotification(scheduled(2, digest, 1, janeTomorrow, at)).Return(int64(11), nil)
+			},
+		},
+		{
+			name:        "Просьба привязать телеграм ждёт времени рассылки",
+			nudgeChatID: 900,
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUpcomingBirthdays(today.AddDate(0, 0, -1), user.MaxReminderDays+2, int64(0)).Return([]user.User{john}, nil)
+				mockRepo.EXPECT().GetSubscribersToRemind(int64(1), 0, "").Return([]user.User{{ID: 5, Telegram: "@nolink"}}, nil)
+				mockNotify.EXPECT().CreateNotification(gomock.Any()).Return(int64(10), nil)
+			},
+		},
+	}
+
+	notifier := &Notifier{Users: mockRepo, Log: mockNotify, Queue: mockQueue, Sender: mockSender, Location: time.UTC}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			notifier.NudgeChatID = tc.nudgeChatID
+			notifier.CheckAndSendNotifications("", at)
+		})
+	}
+
+	// В 9:00 отложенная просьба уходит в общий чат.
+	notifier.sendDueNudges()
+	now = func() time.Time { return at }
+	mockSender.EXPECT().Send(tgbotapi.NewMessage(900, nudgeText([]string{"@nolink"}))).Return(tgbotapi.Message{}, nil)
+	notifier.sendDueNudges()
+	notifier.sendDueNudges()
+}
+
+func TestReminderKind(t *testing.T) {
+	assert.Equal(t, greeting.KindLate, reminderKind(-2))
+	assert.Equal(t, greeting.KindToday, reminderKind(0))
+	assert.Equal(t, greeting.KindTomorrow, reminderKind(1))
+	assert.Equal(t, greeting.KindSoon, reminderKind(3))
+}
+
+func TestGreetingData(t *testing.T) {
+	u := user.User{FirstName: "John", MiddleName: "M", LastName: "Doe", Birthday: "1990-06-11", Department: "QA"}
+	next := time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, greeting.Data{Name: "John M Doe", FirstName: "John", MiddleName: "M", LastName: "Doe", Age: 34, Department: "QA",
+		Date: "11.06", Weekday: time.Tuesday},
+		greetingData(u, next))
+}
@@ -0,0 +1,60 @@
+package bot
+
+import (
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"rutubeTest/pkg/user"
+	"strings"
+	"unicode"
+)
+
+// anonymousArg - аргумент /congrats перед текстом, с которым поздравление пересылается без имени автора.
+const anonymousArg = "anon"
+
+// congratsHandler сохраняет поздравление пользователю из первого аргумента, которое бот перешлёт ему
+// в день рождения: "/congrats @colleague С днём рождения!" или "/congrats @colleague anon С днём рождения!".
+// Оставить поздравление может только подписчик; повторное поздравление заменяет предыдущее.
+func congratsHandler(update tgbotapi.Update, args []string, me *user.User, userRepo user.UserRepo) []tgbotapi.MessageConfig {
+	if me == nil {
+		return notLinked(update)
+	}
+	lang := userLanguage(update.Message.From, me)
+
+	skip := 2
+	anonymous := strings.EqualFold(args[1], anonymousArg)
+	if anonymous {
+		if len(args) == 2 {
+			// "/congrats @colleague anon" - текста нет, "anon" тут не поздравление.
+			return reply(update, messages.T(lang, msgUsage, "/congrats "+messages.T(lang, usageCongrats)))
+		}
+		skip++
+	}
+
+	userID, err := resolveUserID(args[0], userRepo)
+	if err != nil {
+		return reply(update, args[0]+": "+errorText(lang, err))
+	}
+
+	err = userRepo.SaveCongratulation(user.Congratulation{
+		UserID:    userID,
+		AuthorID:  me.ID,
+		Anonymous: anonymous,
+		Text:      skipFields(update.Message.Text, skip),
+	})
+	if err != nil {
+		return reply(update, args[0]+": "+errorText(lang, err))
+	}
+	return reply(update, messages.T(lang, msgCongratulationSaved, args[0]))
+}
+
+// skipFields отрезает от text первые n слов. Остаток возвращается как есть, с переносами строк.
+func skipFields(text string, n int) string {
+	for i := 0; i < n; i++ {
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+		end := strings.IndexFunc(text, unicode.IsSpace)
+		if end == -1 {
+			return ""
+		}
+		text = text[end:]
+	}
+	return strings.TrimSpace(text)
+}
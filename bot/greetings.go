@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"rutubeTest/pkg/greeting"
+	"rutubeTest/pkg/notify"
+	"rutubeTest/pkg/user"
+	"time"
+)
+
+// SendGreetings поздравляет именинников из часового пояса timezone, которые включили поздравления в настройках,
+// и пересылает всем именинникам поздравления, оставленные подписчиками, даже если поздравления от бота
+// выключены: их ждёт автор, а не бот. at - время рассылки по их местному времени;
+// время отправки и тихие часы берутся из настроек именинника. Пропущенные дни не догоняются:
+// поздравлять после дня рождения поздно, поэтому оставленные поздравления ждут следующего года.
+// Пересланное поздравление удаляется, а журнал не даёт отправить его дважды.
+// Ошибка чтения именинников возвращается, чтобы день повторили.
+func (nt *Notifier) SendGreetings(timezone string, at time.Time) error {
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+	if !sameDate(day, now().In(day.Location())) {
+		return nil
+	}
+
+	users, err := nt.Users.GetCelebrantsToGreet(day, timezone)
+	if errors.Is(err, user.ErrNoUser) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("can't fetch celebrants: %w", err)
+	}
+
+	catalog := nt.catalog()
+	current := now()
+	for _, u := range users {
+		if u.TelegramID == 0 {
+			continue
+		}
+		settings := user.DefaultSettings()
+		if u.Settings != nil {
+			settings = *u.Settings
+		}
+		lang := userLanguage(nil, &u)
+		deliverAt := settings.DeliverAt(day, at, current)
+
+		if settings.Greet {
+			nt.greet(catalog, u, lang, day, deliverAt, current)
+		}
+
+		congratulations, err := nt.Users.GetCongratulations(u.ID)
+		if err != nil {
+			fmt.Println("Error fetching congratulations:", err)
+			continue
+		}
+		for _, c := range congratulations {
+			err = nt.logNotification(scheduled(&notify.Notification{
+				UserID:       u.ID,
+				SubscriberID: c.AuthorID,
+				Day:          day,
+				Kind:         notify.KindCongratulation,
+				ChatID:       u.TelegramID,
+				Text:         congratulationText(lang, c),
+			}, deliverAt, current))
+			if err != nil && !errors.Is(err, notify.ErrDuplicate) {
+				continue
+			}
+			if err = nt.Users.DeleteCongratulation(c.ID); err != nil {
+				fmt.Println("Error deleting congratulation:", err)
+			}
+		}
+	}
+	return nil
+}
+
+// greet отправляет имениннику u поздравление от бота из шаблона вида birthday.
+func (nt *Notifier) greet(catalog *greeting.Catalog, u user.User, lang string, day, deliverAt, current time.Time) {
+	text, err := catalog.Render(greeting.KindBirthday, lang, u.Department, greetingData(u, day))
+	if err != nil {
+		fmt.Println("Error rendering template:", err)
+		return
+	}
+	nt.deliverNotification(scheduled(&notify.Notification{
+		UserID:       u.ID,
+		SubscriberID: u.ID,
+		Day:          day,
+		Kind:         notify.KindGreeting,
+		ChatID:       u.TelegramID,
+		Text:         text,
+	}, deliverAt, current))
+}
+
+// scheduled планирует уведомление n на deliverAt, если это время ещё не наступило.
+func scheduled(n *notify.Notification, deliverAt, current time.Time) *notify.Notification {
+	if deliverAt.After(current) {
+		n.Status = notify.StatusScheduled
+		n.NextAttempt = deliverAt
+	}
+	return n
+}
+
+// congratulationText - текст пересланного поздравления на языке именинника lang.
+func congratulationText(lang string, c user.Congratulation) string {
+	if c.Anonymous {
+		return messages.T(lang, msgCongratulationAnonymous, c.Text)
+	}
+	return messages.T(lang, msgCongratulationFrom, c.Author, c.Text)
+}
@@ -0,0 +1,207 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"rutubeTest/pkg/notify"
+	"rutubeTest/pkg/queue"
+	"rutubeTest/pkg/user"
+	"testing"
+	"time"
+)
+
+func TestSendGreetings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+	mockNotify := notify.NewMockNotifyRepo(ctrl)
+	mockQueue := queue.NewMockQueue(ctrl)
+
+	day := time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC)
+	sendAt := 9 * time.Hour
+	john := user.User{ID: 1, FirstName: "John", LastName: "Doe", Birthday: "1990-06-11", TelegramID: 100,
+		Settings: &user.Settings{NotifyHour: user.DefaultHour, Greet: true}}
+	greetingText := "John, с днём рождения! Желаем здоровья, счастья и успехов!"
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		setupMocks func()
+		wantErr    bool
+	}{
+		{
+			name: "Поздравление и пересланные поздравления",
+			now:  day.Add(sendAt),
+			setupMocks: func() {
+				mockRepo.EXPECT().GetCelebrantsToGreet(day, "").Return([]user.User{john, {ID: 2, FirstName: "Jane"}}, nil)
+				n := &notify.Notification{UserID: 1, SubscriberID: 1, Day: day, Kind: notify.KindGreeting, ChatID: 100, Text: greetingText}
+				mockNotify.EXPECT().CreateNotification(n).Return(int64(10), nil)
+				mockQueue.EXPECT().Enqueue(gomock.Any()).Return("1", nil)
+
+				mockRepo.EXPECT().GetCongratulations(int64(1)).Return([]user.Congratulation{
+					{ID: 5, UserID: 1, AuthorID: 3, Author: "Jane Smith", Text: "Всего наилучшего!"},
+					{ID: 6, UserID: 1, AuthorID: 4, Author: "Ivan Petrov", Anonymous: true, Text: "Ура!"},
+					{ID: 7, UserID: 1, AuthorID: 5, Author: "Anna Ivanova", Text: "Поздравляю!"},
+				}, nil)
+				mockNotify.EXPECT().CreateNotification(&notify.Notification{UserID: 1, SubscriberID: 3, Day: day, Kind: notify.KindCongratulation,
+					ChatID: 100, Text: "Поздравление от Jane Smith:\nВсего наилучшего!"}).Return(int64(11), nil)
+				mockQueue.EXPECT().Enqueue(gomock.Any()).Return("2", nil)
+				mockRepo.EXPECT().DeleteCongratulation(int64(5)).Return(nil)
+				mockNotify.EXPECT().CreateNotification(&notify.Notification{UserID: 1, SubscriberID: 4, Day: day, Kind: notify.KindCongratulation,
+					ChatID: 100, Text: "Анонимное поздравление:\nУра!"}).Return(int64(0), notify.ErrDuplicate)
+				mockRepo.EXPECT().DeleteCongratulation(int64(6)).Return(nil)
+				mockNotify.EXPECT().CreateNotification(gomock.Any()).Return(int64(0), fmt.Errorf("database error"))
+			},
+		},
+		{
+			name: "Поздравление по настройкам именинника",
+			now:  day.Add(time.Hour),
+			setupMocks: func() {
+				en := john
+				en.Language = "en"
+				en.Settings = &user.Settings{NotifyHour: 10, Greet: true}
+				mockRepo.EXPECT().GetCelebrantsToGreet(day, "").Return([]user.User{en}, nil)
+				n := &notify.Notification{UserID: 1, SubscriberID: 1, Day: day, Kind: notify.KindGreeting, ChatID: 100,
+					Text: "Happy birthday, John! We wish you health, happiness and success!", Status: notify.StatusScheduled, NextAttempt: day.Add(10 * time.Hour)}
+				mockNotify.EXPECT().CreateNotification(n).Return(int64(10), nil)
+				mockRepo.EXPECT().GetCongratulations(int64(1)).Return(nil, nil)
+			},
+		},
+		{
+			name: "Поздравления без поздравления от бота",
+			now:  day.Add(sendAt),
+			setupMocks: func() {
+				off := john
+				off.Settings = nil
+				mockRepo.EXPECT().GetCelebrantsToGreet(day, "").Return([]user.User{off}, nil)
+				mockRepo.EXPECT().GetCongratulations(int64(1)).Return([]user.Congratulation{
+					{ID: 5, UserID: 1, AuthorID: 3, Author: "Jane Smith", Text: "Всего наилучшего!"},
+				}, nil)
+				mockNotify.EXPECT().CreateNotification(&notify.Notification{UserID: 1, SubscriberID: 3, Day: day, Kind: notify.KindCongratulation,
+					ChatID: 100, Text: "Поздравление от Jane Smith:\nВсего наилучшего!"}).Return(int64(11), nil)
+				mockQueue.EXPECT().Enqueue(gomock.Any()).Return("2", nil)
+				mockRepo.EXPECT().DeleteCongratulation(int64(5)).Return(nil)
+			},
+		},
+		{
+			name: "Именинников нет",
+			now:  day.Add(sendAt),
+			setupMocks: func() {
+				mockRepo.EXPECT().GetCelebrantsToGreet(day, "").Return(nil, user.ErrNoUser)
+			},
+		},
+		{
+			name: "Ошибка чтения именинников",
+			now:  day.Add(sendAt),
+			setupMocks: func() {
+				mockRepo.EXPECT().GetCelebrantsToGreet(day, "").Return(nil, fmt.Errorf("database error"))
+			},
+			wantErr: true,
+		},
+		{
+			name:       "Пропущенный день не догоняется",
+			now:        day.AddDate(0, 0, 1).Add(sendAt),
+			setupMocks: func() {},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			now = func() time.Time { return tc.now }
+			defer func() { now = time.Now }()
+			tc.setupMocks()
+
+			notifier := &Notifier{Users: mockRepo, Log: mockNotify, Queue: mockQueue, Location: time.UTC}
+			err := notifier.SendGreetings("", day.Add(sendAt))
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestCongratsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+
+	me := &user.User{ID: 1, Telegram: "@tester"}
+
+	tests := []struct {
+		name       string
+		text       string
+		setupMocks func()
+		wantText   string
+	}{
+		{
+			name: "Поздравление по @username",
+			text: "/congrats @john С днём рождения!\nЖелаю успехов.",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().GetUserByTelegram("@john").Return(&user.User{ID: 2, Telegram: "@john"}, nil)
+				mockRepo.EXPECT().SaveCongratulation(user.Congratulation{UserID: 2, AuthorID: 1, Text: "С днём рождения!\nЖелаю успехов."}).Return(nil)
+			},
+			wantText: "@john: бот перешлёт ваше поздравление в день рождения.",
+		},
+		{
+			name: "Анонимное поздравление",
+			text: "/congrats 2 anon Ура!",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().SaveCongratulation(user.Congratulation{UserID: 2, AuthorID: 1, Anonymous: true, Text: "Ура!"}).Return(nil)
+			},
+			wantText: "2: бот перешлёт ваше поздравление в день рождения.",
+		},
+		{
+			name: "Нет подписки",
+			text: "/congrats 2 Ура!",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().SaveCongratulation(user.Congratulation{UserID: 2, AuthorID: 1, Text: "Ура!"}).Return(user.ErrNoSubscription)
+			},
+			wantText: "2: Сначала подпишитесь на пользователя.",
+		},
+		{
+			name: "Только anon без текста",
+			text: "/congrats 2 anon",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+			},
+			wantText: "Использование: /congrats <id|@username> [anon] <текст>",
+		},
+		{
+			name: "Слишком длинный текст",
+			text: "/congrats 2 Ура!",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().SaveCongratulation(gomock.Any()).Return(user.ErrBadCongratulation)
+			},
+			wantText: "2: Текст поздравления - от 1 до 500 символов.",
+		},
+		{
+			name: "Без текста",
+			text: "/congrats 2",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+			},
+			wantText: "Использование: /congrats <id|@username> [anon] <текст>",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			messages := newRouter(testBotName, user.LeapDayMar1).handle(newUpdate(tc.text), mockRepo)
+
+			assert.Len(t, messages, 1)
+			assert.Equal(t, tc.wantText, messages[0].Text)
+		})
+	}
+}
+
+func TestSkipFields(t *testing.T) {
+	assert.Equal(t, "С днём\nрождения!", skipFields("/congrats  @john \n С днём\nрождения! ", 2))
+	assert.Equal(t, "", skipFields("/congrats @john", 2))
+}
@@ -0,0 +1,174 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"rutubeTest/pkg/user"
+	"strconv"
+	"strings"
+)
+
+// usersPageSize - сколько пользователей показывается на одной странице /users.
+const usersPageSize = 10
+
+// Префиксы данных inline-кнопок. Полные данные выглядят как "users:<page>", "sub:<id>:<page>" и "unsub:<id>:<page>".
+const (
+	callbackUsersPage   = "users"
+	callbackSubscribe   = "sub"
+	callbackUnsubscribe = "unsub"
+)
+
+func usersListHandler(update tgbotapi.Update, args []string, me *user.User, userRepo user.UserRepo) []tgbotapi.MessageConfig {
+	lang := userLanguage(update.Message.From, me)
+	page := 0
+	if len(args) > 0 {
+		p, err := strconv.Atoi(args[0])
+		if err != nil || p < 1 {
+			return reply(update, messages.T(lang, msgBadPage))
+		}
+		page = p - 1
+	}
+
+	text, keyboard, err := usersPage(userRepo, me, lang, page)
+	if err != nil {
+		return reply(update, errorText(lang, err))
+	}
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
+	if len(keyboard.InlineKeyboard) > 0 {
+		msg.ReplyMarkup = keyboard
+	}
+	return []tgbotapi.MessageConfig{msg}
+}
+
+// usersPage формирует страницу списка пользователей и клавиатуру к ней на языке lang.
+// Кнопки подписки показываются только пользователю me, чей телеграм привязан к учётной записи.
+func usersPage(userRepo user.UserRepo, me *user.User, lang string, page int) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	users, err := userRepo.GetUsers()
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	pages := (len(users) + usersPageSize - 1) / usersPageSize
+	if page >= pages {
+		page = pages - 1
+	}
+
+	subscribed := make(map[int64]bool)
+	if me != nil {
+		subs, err := userRepo.GetSubscriptions(me.ID)
+		if err != nil && !errors.Is(err, user.ErrNoUser) {
+			return "", tgbotapi.InlineKeyboardMarkup{}, err
+		}
+		for _, s := range subs {
+			subscribed[s.ID] = true
+		}
+	}
+
+	start := page * usersPageSize
+	end := min(start+usersPageSize, len(users))
+
+	var sb strings.Builder
+	sb.WriteString(messages.T(lang, msgUsers, page+1, pages))
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, u := range users[start:end] {
+		sb.WriteString("\n" + userLine(lang, u))
+
+		if me == nil || me.ID == u.ID {
+			continue
+		}
+		name := u.FirstName + " " + u.LastName
+		var btn tgbotapi.InlineKeyboardButton
+		if subscribed[u.ID] {
+			btn = tgbotapi.NewInlineKeyboardButtonData("✅ "+name, fmt.Sprintf("%s:%d:%d", callbackUnsubscribe, u.ID, page))
+		} else {
+			btn = tgbotapi.NewInlineKeyboardButtonData("➕ "+name, fmt.Sprintf("%s:%d:%d", callbackSubscribe, u.ID, page))
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(btn))
+	}
+
+	var nav []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(messages.T(lang, msgBack), fmt.Sprintf("%s:%d", callbackUsersPage, page-1)))
+	}
+	if page < pages-1 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(messages.T(lang, msgForward), fmt.Sprintf("%s:%d", callbackUsersPage, page+1)))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+
+	return sb.String(), tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
+
+func userLine(lang string, u user.User) string {
+	return messages.T(lang, msgUserLine, u.ID, u.FirstName, u.MiddleName, u.LastName, u.Birthday, u.Telegram)
+}
+
+// callbackHandler обрабатывает нажатия на inline-кнопки: отвечает на callback и перерисовывает страницу списка.
+// Кнопки /settings обрабатывает settingsCallback.
+func callbackHandler(update tgbotapi.Update, userRepo user.UserRepo) []tgbotapi.Chattable {
+	cb := update.CallbackQuery
+	if cb == nil {
+		return nil
+	}
+
+	me := linkedUser(cb.From, userRepo)
+	lang := userLanguage(cb.From, me)
+
+	parts := strings.Split(cb.Data, ":")
+	var page int
+	var answer string
+
+	switch {
+	case len(parts) == 2 && parts[0] == callbackSettings:
+		return settingsCallback(cb, parts[1], me, userRepo)
+	case len(parts) == 2 && parts[0] == callbackUsersPage:
+		page, _ = strconv.Atoi(parts[1])
+	case len(parts) == 3 && (parts[0] == callbackSubscribe || parts[0] == callbackUnsubscribe):
+		userID, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return []tgbotapi.Chattable{tgbotapi.NewCallback(cb.ID, "")}
+		}
+		page, _ = strconv.Atoi(parts[2])
+
+		typeOf := 1
+		if parts[0] == callbackUnsubscribe {
+			typeOf = 0
+		}
+		answer = callbackSubscription(lang, me, userID, typeOf, userRepo)
+	default:
+		return []tgbotapi.Chattable{tgbotapi.NewCallback(cb.ID, "")}
+	}
+
+	result := []tgbotapi.Chattable{tgbotapi.NewCallback(cb.ID, answer)}
+	if cb.Message == nil {
+		return result
+	}
+
+	text, keyboard, err := usersPage(userRepo, me, lang, max(page, 0))
+	if err != nil {
+		return result
+	}
+	if len(keyboard.InlineKeyboard) > 0 {
+		result = append(result, tgbotapi.NewEditMessageTextAndMarkup(cb.Message.Chat.ID, cb.Message.MessageID, text, keyboard))
+	} else {
+		result = append(result, tgbotapi.NewEditMessageText(cb.Message.Chat.ID, cb.Message.MessageID, text))
+	}
+	return result
+}
+
+func callbackSubscription(lang string, me *user.User, userID int64, typeOf int, userRepo user.UserRepo) string {
+	if me == nil {
+		return messages.T(lang, msgNotLinked)
+	}
+
+	subUser, err := userRepo.Subscribe(userID, me.ID, typeOf)
+	if err != nil {
+		return errorText(lang, err)
+	}
+
+	return subscriptionChanged(lang, subUser, typeOf)
+}
@@ -0,0 +1,40 @@
+package bot
+
+import (
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"rutubeTest/pkg/user"
+)
+
+// languageAuto - аргумент /language, который сбрасывает выбранный язык: бот отвечает на языке телеграма.
+const languageAuto = "auto"
+
+// languageHandler показывает язык сообщений автора, а с аргументом - выбирает его.
+// На выбранном языке бот отвечает на команды и присылает напоминания, если для языка есть шаблоны.
+func languageHandler(update tgbotapi.Update, args []string, me *user.User, userRepo user.UserRepo) []tgbotapi.MessageConfig {
+	if me == nil {
+		return notLinked(update)
+	}
+	lang := userLanguage(update.Message.From, me)
+
+	if len(args) == 0 {
+		if me.Language == "" {
+			return reply(update, messages.T(lang, msgLanguageAuto))
+		}
+		return reply(update, messages.T(lang, msgLanguage, me.Language))
+	}
+
+	language := args[0]
+	if language == languageAuto {
+		language = ""
+	}
+	if err := userRepo.SetLanguage(me.ID, language); err != nil {
+		return reply(update, errorText(lang, err))
+	}
+
+	me.Language = language
+	lang = userLanguage(update.Message.From, me)
+	if language == "" {
+		return reply(update, messages.T(lang, msgLanguageAuto))
+	}
+	return reply(update, messages.T(lang, msgLanguageChanged, language))
+}
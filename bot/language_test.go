@@ -0,0 +1,127 @@
+package bot
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"rutubeTest/pkg/user"
+	"testing"
+)
+
+func TestLanguageHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+
+	tests := []struct {
+		name         string
+		text         string
+		languageCode string
+		setupMocks   func()
+		wantText     string
+	}{
+		{
+			name: "Язык не выбран",
+			text: "/language",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+			},
+			wantText: "Язык не выбран, бот отвечает на языке вашего телеграма. Выбрать его можно так: /language en",
+		},
+		{
+			name:         "Язык телеграма",
+			text:         "/language",
+			languageCode: "en-US",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+			},
+			wantText: "No language selected, the bot replies in the language of your Telegram. You can select one like this: /language ru",
+		},
+		{
+			name:         "Выбранный язык важнее языка телеграма",
+			text:         "/language",
+			languageCode: "ru",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1, Language: "en"}, nil)
+			},
+			wantText: "Message language: en",
+		},
+		{
+			name: "Язык изменён",
+			text: "/language en",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+				mockRepo.EXPECT().SetLanguage(int64(1), "en").Return(nil)
+			},
+			wantText: "Message language changed to en.",
+		},
+		{
+			name:         "Сброс на язык телеграма",
+			text:         "/language auto",
+			languageCode: "en",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1, Language: "ru"}, nil)
+				mockRepo.EXPECT().SetLanguage(int64(1), "").Return(nil)
+			},
+			wantText: "No language selected, the bot replies in the language of your Telegram. You can select one like this: /language ru",
+		},
+		{
+			name: "Неверный язык",
+			text: "/language English",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+				mockRepo.EXPECT().SetLanguage(int64(1), "English").Return(user.ErrBadLanguage)
+			},
+			wantText: "Язык - двухбуквенный код, например ru или en, или auto.",
+		},
+		{
+			name:         "Телеграм не привязан",
+			text:         "/language en",
+			languageCode: "en",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+			},
+			wantText: "Your Telegram account is not among registered users.",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			update := newUpdate(tc.text)
+			update.Message.From.LanguageCode = tc.languageCode
+			messages := newRouter(testBotName, user.LeapDayMar1).handle(update, mockRepo)
+
+			assert.Len(t, messages, 1)
+			assert.Equal(t, tc.wantText, messages[0].Text)
+		})
+	}
+}
+
+func TestRouterLanguage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+
+	mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+	update := newUpdate("/subscribe")
+	update.Message.From.LanguageCode = "en"
+	replies := newRouter(testBotName, user.LeapDayMar1).handle(update, mockRepo)
+	assert.Equal(t, "Usage: /subscribe <id|@username> ...", replies[0].Text)
+
+	// Выбранный язык важнее языка телеграма и в ответах, которые не доходят до обработчика команды.
+	mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1, Language: "ru"}, nil)
+	replies = newRouter(testBotName, user.LeapDayMar1).handle(update, mockRepo)
+	assert.Equal(t, "Использование: /subscribe <id|@username> ...", replies[0].Text)
+
+	mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1, Language: "en"}, nil)
+	mockRepo.EXPECT().SetReminders(int64(2), int64(1), []int{7, 1, 0}).Return([]int{7, 1, 0}, nil)
+	replies = newRouter(testBotName, user.LeapDayMar1).handle(newUpdate("/remind 2 7 1 0"), mockRepo)
+	assert.Equal(t, "2: I will remind 7 days before, the day before and on the birthday", replies[0].Text)
+}
+
+func TestMessages(t *testing.T) {
+	assert.Empty(t, messages.Missing())
+}
@@ -0,0 +1,374 @@
+package bot
+
+import (
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"rutubeTest/pkg/i18n"
+	"rutubeTest/pkg/user"
+)
+
+// Ключи сообщений бота.
+const (
+	msgUnknownCommand = "unknownCommand"
+	msgUsage          = "usage"
+	msgHelp           = "help"
+	msgInternal       = "internal"
+
+	msgNotRegistered = "notRegistered"
+	msgNotLinked     = "notLinked"
+	msgWelcome       = "welcome"
+
+	msgNoUser         = "noUser"
+	msgExists         = "exists"
+	msgNoSubscription = "noSubscription"
+	msgBadReminders   = "badReminders"
+	msgBadTimeZone    = "badTimeZone"
+	msgBadSettings    = "badSettings"
+	msgSettingsUsage  = "settingsUsage"
+	msgBadTarget      = "badTarget"
+	msgBadLanguage    = "badLanguage"
+
+	msgBadCongratulation       = "badCongratulation"
+	msgCongratulationSaved     = "congratulationSaved"
+	msgCongratulationFrom      = "congratulationFrom"
+	msgCongratulationAnonymous = "congratulationAnonymous"
+
+	msgSubscribed      = "subscribed"
+	msgUnsubscribed    = "unsubscribed"
+	msgNoSubscriptions = "noSubscriptions"
+	msgSubscriptions   = "subscriptions"
+	msgNoSubscribers   = "noSubscribers"
+	msgSubscribers     = "subscribers"
+	msgBadDays         = "badDays"
+	msgNoUpcoming      = "noUpcoming"
+	msgUpcoming        = "upcoming"
+	msgToday           = "today"
+	msgTomorrow        = "tomorrow"
+	msgInDays          = "inDays"
+
+	msgNoTimeZone      = "noTimeZone"
+	msgTimeZone        = "timeZone"
+	msgTimeZoneChanged = "timeZoneChanged"
+
+	msgLanguageAuto    = "languageAuto"
+	msgLanguage        = "language"
+	msgLanguageChanged = "languageChanged"
+
+	msgRemindersSet = "remindersSet"
+	msgOnBirthday   = "onBirthday"
+	msgDayBefore    = "dayBefore"
+	msgDaysBefore   = "daysBefore"
+	msgAnd          = "and"
+
+	msgSettings        = "settings"
+	msgHourDefault     = "hourDefault"
+	msgHour            = "hour"
+	msgNoQuiet         = "noQuiet"
+	msgQuiet           = "quiet"
+	msgMuted           = "muted"
+	msgEnabled         = "enabled"
+	msgFormatWeekly    = "formatWeekly"
+	msgFormatMonthly   = "formatMonthly"
+	msgFormatDigest    = "formatDigest"
+	msgFormatSingle    = "formatSingle"
+	msgGreetOn         = "greetOn"
+	msgGreetOff        = "greetOff"
+	msgSettingsChange  = "settingsChange"
+	msgDigestOn        = "digestOn"
+	msgDigestOff       = "digestOff"
+	msgMute            = "mute"
+	msgUnmute          = "unmute"
+	msgSettingsSaved   = "settingsSaved"
+	msgBadPage         = "badPage"
+	msgUsers           = "users"
+	msgUserLine        = "userLine"
+	msgBack            = "back"
+	msgForward         = "forward"
+	msgSummary         = "summary"
+	msgDigest          = "digest"
+	msgNudge           = "nudge"
+	msgDeliveryFailure = "deliveryFailure"
+
+	msgMonday    = "monday"
+	msgTuesday   = "tuesday"
+	msgWednesday = "wednesday"
+	msgThursday  = "thursday"
+	msgFriday    = "friday"
+	msgSaturday  = "saturday"
+	msgSunday    = "sunday"
+
+	// Справка по командам: cmd* - описание, usage* - аргументы.
+	cmdStart         = "cmdStart"
+	cmdUsers         = "cmdUsers"
+	usageUsers       = "usageUsers"
+	cmdSubscribe     = "cmdSubscribe"
+	cmdUnsubscribe   = "cmdUnsubscribe"
+	usageTargets     = "usageTargets"
+	cmdRemind        = "cmdRemind"
+	usageRemind      = "usageRemind"
+	cmdCongrats      = "cmdCongrats"
+	usageCongrats    = "usageCongrats"
+	cmdTimezone      = "cmdTimezone"
+	usageTimezone    = "usageTimezone"
+	cmdLanguage      = "cmdLanguage"
+	usageLanguage    = "usageLanguage"
+	cmdSettings      = "cmdSettings"
+	usageSettings    = "usageSettings"
+	cmdSubscriptions = "cmdSubscriptions"
+	cmdSubscribers   = "cmdSubscribers"
+	cmdUpcoming      = "cmdUpcoming"
+	usageUpcoming    = "usageUpcoming"
+	cmdHelp          = "cmdHelp"
+)
+
+// messages - тексты ответов и уведомлений бота.
+var messages = i18n.Messages{
+	"ru": {
+		msgUnknownCommand: "Неизвестная команда. Напишите /help, чтобы увидеть список команд.",
+		msgUsage:          "Использование: %s",
+		msgHelp:           "Доступные команды:",
+		msgInternal:       "Что-то пошло не так, попробуйте позже.",
+
+		msgNotRegistered: "Ваш телеграм не найден среди зарегистрированных пользователей. Укажите его при регистрации.",
+		msgNotLinked:     "Ваш телеграм не найден среди зарегистрированных пользователей.",
+		msgWelcome: "Добро пожаловать. Напишите /users, чтобы увидеть всех пользователей.\n" +
+			"Напишите /subscribe или /unsubscribe, а после id или @username для подписки отписки на пользователя.\n" +
+			"Например, /subscribe 1 @colleague\n" +
+			"Все команды: /help",
+
+		msgNoUser:         "Пользователь не найден.",
+		msgExists:         "Подписка уже оформлена.",
+		msgNoSubscription: "Сначала подпишитесь на пользователя.",
+		msgBadReminders:   "Укажите, за сколько дней напомнить: числа от 0 до %d.",
+		msgBadTimeZone:    "Неизвестный часовой пояс. Укажите его из базы IANA, например Europe/Moscow или Asia/Vladivostok.",
+		msgBadSettings:    "Часы - числа от 0 до 23, дата - в формате ГГГГ-ММ-ДД, сводка - weekly или monthly.",
+		msgSettingsUsage: "Использование: /settings hour <час|default>, /settings quiet <с> <до>|off, " +
+			"/settings mute <ГГГГ-ММ-ДД>|off, /settings digest on|off, /settings summary weekly|monthly|off, /settings greet on|off",
+		msgBadTarget:   "Нужен положительный id или @username.",
+		msgBadLanguage: "Язык - двухбуквенный код, например ru или en, или auto.",
+
+		msgBadCongratulation:       "Текст поздравления - от 1 до %d символов.",
+		msgCongratulationSaved:     "%s: бот перешлёт ваше поздравление в день рождения.",
+		msgCongratulationFrom:      "Поздравление от %s:\n%s",
+		msgCongratulationAnonymous: "Анонимное поздравление:\n%s",
+
+		msgSubscribed:      "Вы подписались на %s",
+		msgUnsubscribed:    "Вы отписались от %s",
+		msgNoSubscriptions: "Вы ни на кого не подписаны. Подписаться можно через /users.",
+		msgSubscriptions:   "Вы подписаны на:\n%s",
+		msgNoSubscribers:   "На вас пока никто не подписан.",
+		msgSubscribers:     "На вас подписаны:\n%s",
+		msgBadDays:         "Число дней должно быть от 0 до 366.",
+		msgNoUpcoming:      "В ближайшие %d дн. дней рождения нет.",
+		msgUpcoming:        "Дни рождения в ближайшие %d дн.:\n%s",
+		msgToday:           "сегодня",
+		msgTomorrow:        "завтра",
+		msgInDays:          "через %d дн.",
+
+		msgNoTimeZone: "Часовой пояс не задан, напоминания приходят по часовому поясу сервиса. " +
+			"Задать его можно так: /timezone Europe/Moscow",
+		msgTimeZone:        "Ваш часовой пояс: %s",
+		msgTimeZoneChanged: "Часовой пояс изменён на %s. Напоминания будут приходить по вашему местному времени.",
+
+		msgLanguageAuto:    "Язык не выбран, бот отвечает на языке вашего телеграма. Выбрать его можно так: /language en",
+		msgLanguage:        "Язык сообщений: %s",
+		msgLanguageChanged: "Язык сообщений изменён на %s.",
+
+		msgRemindersSet: "%s: буду напоминать %s",
+		msgOnBirthday:   "в день рождения",
+		msgDayBefore:    "накануне",
+		msgDaysBefore:   "за %d дн.",
+		msgAnd:          " и ",
+
+		msgSettings:      "Настройки напоминаний:",
+		msgHourDefault:   "Время: как у сервиса",
+		msgHour:          "Время: %02d:00",
+		msgNoQuiet:       "Тихие часы: нет",
+		msgQuiet:         "Тихие часы: с %02d:00 до %02d:00",
+		msgMuted:         "Напоминания: отключены по %s",
+		msgEnabled:       "Напоминания: включены",
+		msgFormatWeekly:  "Формат: сводка по понедельникам о днях рождения на неделе",
+		msgFormatMonthly: "Формат: сводка первого числа о днях рождения в месяце",
+		msgFormatDigest:  "Формат: одно сообщение в день",
+		msgFormatSingle:  "Формат: отдельное сообщение о каждом дне рождения",
+		msgGreetOn:       "Поздравление в мой день рождения: включено",
+		msgGreetOff:      "Поздравление в мой день рождения: выключено",
+		msgSettingsChange: "Изменить: /settings hour <час|default>, /settings quiet <с> <до>|off, " +
+			"/settings mute <ГГГГ-ММ-ДД>|off, /settings digest on|off, /settings summary weekly|monthly|off, /settings greet on|off",
+		msgDigestOn:      "Присылать одним сообщением",
+		msgDigestOff:     "Присылать отдельными сообщениями",
+		msgMute:          "Отключить на %d дней",
+		msgUnmute:        "Включить напоминания",
+		msgSettingsSaved: "Настройки сохранены",
+		msgBadPage:       "Номер страницы должен быть положительным числом.",
+		msgUsers:         "Пользователи (страница %d из %d):",
+		msgUserLine:      "ID: %d ФИО: %s %s %s %s %s",
+		msgBack:          "◀️ Назад",
+		msgForward:       "Вперёд ▶️",
+		msgSummary:       "Дни рождения с %s по %s:",
+		msgDigest:        "Напоминания о днях рождения:",
+		msgNudge: "%s, вам пришли напоминания о днях рождения коллег, но бот не может вам написать. " +
+			"Откройте бота и отправьте /start, чтобы получать их.",
+		msgDeliveryFailure: "Не удалось доставить напоминание пользователю с id %d (именинник id %d, %s) после %d попыток: %s",
+
+		msgMonday:    "понедельник",
+		msgTuesday:   "вторник",
+		msgWednesday: "среда",
+		msgThursday:  "четверг",
+		msgFriday:    "пятница",
+		msgSaturday:  "суббота",
+		msgSunday:    "воскресенье",
+
+		cmdStart:         "привязать телеграм к учётной записи",
+		cmdUsers:         "список всех пользователей с кнопками подписки",
+		usageUsers:       "[страница]",
+		cmdSubscribe:     "подписаться на день рождения пользователя",
+		cmdUnsubscribe:   "отписаться от дня рождения пользователя",
+		usageTargets:     "<id|@username> ...",
+		cmdRemind:        "за сколько дней до дня рождения напоминать, 0 - в сам день",
+		usageRemind:      "<id|@username> <дней> ...",
+		cmdCongrats:      "оставить поздравление, которое бот перешлёт в день рождения; anon - без имени",
+		usageCongrats:    "<id|@username> [anon] <текст>",
+		cmdTimezone:      "мой часовой пояс, например Asia/Vladivostok",
+		usageTimezone:    "[часовой пояс]",
+		cmdLanguage:      "язык сообщений бота, auto - язык телеграма",
+		usageLanguage:    "[ru|en|auto]",
+		cmdSettings:      "время, тихие часы и формат напоминаний",
+		usageSettings:    "[hour|quiet|mute|digest|summary|greet ...]",
+		cmdSubscriptions: "на кого я подписан и когда у них дни рождения",
+		cmdSubscribers:   "кто подписан на меня",
+		cmdUpcoming:      "ближайшие дни рождения, my - только из моих подписок",
+		usageUpcoming:    "[дней] [my]",
+		cmdHelp:          "список команд",
+	},
+	"en": {
+		msgUnknownCommand: "Unknown command. Send /help to see the list of commands.",
+		msgUsage:          "Usage: %s",
+		msgHelp:           "Available commands:",
+		msgInternal:       "Something went wrong, please try again later.",
+
+		msgNotRegistered: "Your Telegram account is not among registered users. Specify it when you register.",
+		msgNotLinked:     "Your Telegram account is not among registered users.",
+		msgWelcome: "Welcome. Send /users to see all users.\n" +
+			"Send /subscribe or /unsubscribe followed by an id or @username to subscribe to a user or unsubscribe.\n" +
+			"For example, /subscribe 1 @colleague\n" +
+			"All commands: /help",
+
+		msgNoUser:         "User not found.",
+		msgExists:         "You are already subscribed.",
+		msgNoSubscription: "Subscribe to the user first.",
+		msgBadReminders:   "Specify how many days before to remind: numbers from 0 to %d.",
+		msgBadTimeZone:    "Unknown time zone. Use a name from the IANA database, like Europe/Moscow or Asia/Vladivostok.",
+		msgBadSettings:    "Hours are numbers from 0 to 23, a date is YYYY-MM-DD, a summary is weekly or monthly.",
+		msgSettingsUsage: "Usage: /settings hour <hour|default>, /settings quiet <from> <to>|off, " +
+			"/settings mute <YYYY-MM-DD>|off, /settings digest on|off, /settings summary weekly|monthly|off, /settings greet on|off",
+		msgBadTarget:   "A positive id or @username is required.",
+		msgBadLanguage: "A language is a two-letter code like ru or en, or auto.",
+
+		msgBadCongratulation:       "A congratulation must be from 1 to %d characters.",
+		msgCongratulationSaved:     "%s: the bot will forward your congratulation on the birthday.",
+		msgCongratulationFrom:      "Congratulation from %s:\n%s",
+		msgCongratulationAnonymous: "Anonymous congratulation:\n%s",
+
+		msgSubscribed:      "You subscribed to %s",
+		msgUnsubscribed:    "You unsubscribed from %s",
+		msgNoSubscriptions: "You are not subscribed to anyone. You can subscribe via /users.",
+		msgSubscriptions:   "You are subscribed to:\n%s",
+		msgNoSubscribers:   "Nobody is subscribed to you yet.",
+		msgSubscribers:     "Subscribed to you:\n%s",
+		msgBadDays:         "The number of days must be from 0 to 366.",
+		msgNoUpcoming:      "No birthdays in the next %d days.",
+		msgUpcoming:        "Birthdays in the next %d days:\n%s",
+		msgToday:           "today",
+		msgTomorrow:        "tomorrow",
+		msgInDays:          "in %d days",
+
+		msgNoTimeZone: "Your time zone is not set, reminders come in the service time zone. " +
+			"You can set it like this: /timezone Europe/Moscow",
+		msgTimeZone:        "Your time zone: %s",
+		msgTimeZoneChanged: "Time zone changed to %s. Reminders will come at your local time.",
+
+		msgLanguageAuto:    "No language selected, the bot replies in the language of your Telegram. You can select one like this: /language ru",
+		msgLanguage:        "Message language: %s",
+		msgLanguageChanged: "Message language changed to %s.",
+
+		msgRemindersSet: "%s: I will remind %s",
+		msgOnBirthday:   "on the birthday",
+		msgDayBefore:    "the day before",
+		msgDaysBefore:   "%d days before",
+		msgAnd:          " and ",
+
+		msgSettings:      "Reminder settings:",
+		msgHourDefault:   "Time: same as the service",
+		msgHour:          "Time: %02d:00",
+		msgNoQuiet:       "Quiet hours: none",
+		msgQuiet:         "Quiet hours: from %02d:00 to %02d:00",
+		msgMuted:         "Reminders: off until %s",
+		msgEnabled:       "Reminders: on",
+		msgFormatWeekly:  "Format: a summary of the week's birthdays on Mondays",
+		msgFormatMonthly: "Format: a summary of the month's birthdays on the first day",
+		msgFormatDigest:  "Format: one message a day",
+		msgFormatSingle:  "Format: a separate message for each birthday",
+		msgGreetOn:       "Greeting on my birthday: on",
+		msgGreetOff:      "Greeting on my birthday: off",
+		msgSettingsChange: "Change: /settings hour <hour|default>, /settings quiet <from> <to>|off, " +
+			"/settings mute <YYYY-MM-DD>|off, /settings digest on|off, /settings summary weekly|monthly|off, /settings greet on|off",
+		msgDigestOn:      "Send in one message",
+		msgDigestOff:     "Send in separate messages",
+		msgMute:          "Mute for %d days",
+		msgUnmute:        "Turn reminders on",
+		msgSettingsSaved: "Settings saved",
+		msgBadPage:       "The page number must be a positive number.",
+		msgUsers:         "Users (page %d of %d):",
+		msgUserLine:      "ID: %d Name: %s %s %s %s %s",
+		msgBack:          "◀️ Back",
+		msgForward:       "Next ▶️",
+		msgSummary:       "Birthdays from %s to %s:",
+		msgDigest:        "Birthday reminders:",
+		msgNudge: "%s, you have birthday reminders, but the bot can't message you. " +
+			"Open the bot and send /start to receive them.",
+		msgDeliveryFailure: "Failed to deliver a reminder to user id %d (birthday of user id %d, %s) after %d attempts: %s",
+
+		msgMonday:    "Monday",
+		msgTuesday:   "Tuesday",
+		msgWednesday: "Wednesday",
+		msgThursday:  "Thursday",
+		msgFriday:    "Friday",
+		msgSaturday:  "Saturday",
+		msgSunday:    "Sunday",
+
+		cmdStart:         "link your Telegram to your account",
+		cmdUsers:         "all users with subscribe buttons",
+		usageUsers:       "[page]",
+		cmdSubscribe:     "subscribe to a user's birthday",
+		cmdUnsubscribe:   "unsubscribe from a user's birthday",
+		usageTargets:     "<id|@username> ...",
+		cmdRemind:        "how many days before a birthday to remind, 0 - on the day",
+		usageRemind:      "<id|@username> <days> ...",
+		cmdCongrats:      "leave a congratulation the bot will forward on the birthday; anon - without your name",
+		usageCongrats:    "<id|@username> [anon] <text>",
+		cmdTimezone:      "my time zone, like Asia/Vladivostok",
+		usageTimezone:    "[time zone]",
+		cmdLanguage:      "bot message language, auto - the language of Telegram",
+		usageLanguage:    "[ru|en|auto]",
+		cmdSettings:      "reminder time, quiet hours and format",
+		usageSettings:    "[hour|quiet|mute|digest|summary|greet ...]",
+		cmdSubscriptions: "who I am subscribed to and when their birthdays are",
+		cmdSubscribers:   "who is subscribed to me",
+		cmdUpcoming:      "upcoming birthdays, my - only from my subscriptions",
+		usageUpcoming:    "[days] [my]",
+		cmdHelp:          "list of commands",
+	},
+}
+
+// userLanguage возвращает язык ответов пользователю: выбранный в его учётной записи me, а если он не выбран
+// или пользователь неизвестен (me == nil) - язык его телеграма.
+func userLanguage(from *tgbotapi.User, me *user.User) string {
+	var languages []string
+	if me != nil {
+		languages = append(languages, me.Language)
+	}
+	if from != nil {
+		languages = append(languages, from.LanguageCode)
+	}
+	return messages.Language(languages...)
+}
@@ -0,0 +1,400 @@
+package bot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"rutubeTest/pkg/calendar"
+	"rutubeTest/pkg/greeting"
+	"rutubeTest/pkg/i18n"
+	"rutubeTest/pkg/lock"
+	"rutubeTest/pkg/notify"
+	"rutubeTest/pkg/queue"
+	"rutubeTest/pkg/user"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errUnlinked - подписчик не писал боту /start, поэтому его chat id неизвестен.
+var errUnlinked = errors.New("telegram is not linked")
+
+// Notifier рассылает напоминания о днях рождения. Ежедневный запуск и повторы только записывают
+// уведомления в журнал Log и ставят их в очередь Queue, а отправляют их воркеры.
+type Notifier struct {
+	Users  user.UserRepo
+	Log    notify.NotifyRepo
+	Queue  queue.Queue
+	Sender Sender
+	// Location - часовой пояс сервиса, он используется для пользователей, которые не задали свой.
+	Location *time.Location
+	// Calendar - производственный календарь. Если он задан, поздравления в нерабочие дни
+	// приходят в последний рабочий день перед ними.
+	Calendar *calendar.Calendar
+	// AdminChatIDs - чаты, куда сообщается о недоставленных уведомлениях.
+	AdminChatIDs []int64
+	// NudgeChatID - общий чат, где бот просит привязать телеграм подписчиков, которым не может написать.
+	NudgeChatID int64
+	// Templates - шаблоны текстов напоминаний, которые задали администраторы; nil - только шаблоны по умолчанию.
+	Templates greeting.TemplateRepo
+	// LeapDay - когда поздравлять родившихся 29 февраля в невисокосный год.
+	LeapDay user.LeapDayPolicy
+	// Locker - блокировка, под которой администраторам уходят отчёты о недоставленных уведомлениях,
+	// чтобы реплики не дублировали их; nil - без блокировки.
+	Locker lock.Locker
+
+	mu     sync.Mutex
+	nudges []pendingNudge // Просьбы привязать телеграм, время которых ещё не наступило.
+}
+
+// pendingNudge - просьба привязать телеграм, которую нужно отправить в at.
+type pendingNudge struct {
+	text string
+	at   time.Time
+}
+
+// CheckAndSendNotifications рассылает напоминания о днях рождения подписчикам из часового пояса timezone;
+// at - время рассылки по их местному времени. Ошибка чтения именинников возвращается, чтобы день повторили.
+func (nt *Notifier) CheckAndSendNotifications(timezone string, at time.Time) error {
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+	// Если рассылка догоняет простой, текст напоминания считается относительно сегодняшнего дня.
+	late := user.DaysUntil(now().In(day.Location()), day)
+
+	// У именинника в другом часовом поясе может быть уже завтра или ещё вчера.
+	users, err := nt.Users.GetUpcomingBirthdays(day.AddDate(0, 0, -1), user.MaxReminderDays+2, 0)
+	if errors.Is(err, user.ErrNoUser) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("can't fetch users: %w", err)
+	}
+
+	catalog := nt.catalog()
+	current := now()
+	var unlinked []string
+	seen := make(map[int64]bool)
+	digests := make(map[int64][]*notify.Notification)
+	languages := make(map[int64]string)
+	var digestOrder []int64
+	for _, u := range users {
+		// Дни до дня рождения считаются по часовому поясу именинника в момент at.
+		local := at.In(nt.location(u.TimeZone))
+		next, err := user.NextBirthday(u.Birthday, local, nt.LeapDay)
+		if err != nil {
+			fmt.Println("Error parsing birthday:", err)
+			continue
+		}
+		daysBefore := user.DaysUntil(next, local)
+		if daysBefore > user.MaxReminderDays {
+			continue
+		}
+
+		for _, r := range nt.reminders(greetingData(u, next), next, daysBefore, day, late) {
+			subscribers, err := nt.Users.GetSubscribersToRemind(u.ID, r.offset, timezone)
+			if err != nil {
+				if !errors.Is(err, user.ErrNoUser) {
+					fmt.Println("Error fetching subscribers:", err)
+				}
+				continue
+			}
+
+			for _, sub := range subscribers {
+				settings := user.DefaultSettings()
+				if sub.Settings != nil {
+					settings = *sub.Settings
+				}
+				// Выбравшим сводку о днях рождения сообщает SendSummaries.
+				if settings.Muted(day) || settings.Summary != user.SummaryNone {
+					continue
+				}
+
+				text, err := catalog.Render(r.kind, sub.Language, sub.Department, r.data)
+				if err != nil {
+					fmt.Println("Error rendering template:", err)
+					continue
+				}
+
+				n := &notify.Notification{
+					UserID:       u.ID,
+					SubscriberID: sub.ID,
+					Day:          day,
+					DaysBefore:   daysBefore,
+					ChatID:       sub.TelegramID,
+					Text:         text,
+				}
+				// Подписчику без привязанного телеграма бот не пишет, а просит его в NudgeChatID написать боту.
+				if sub.TelegramID == 0 {
+					n.Status = notify.StatusUnlinked
+					n.LastError = errUnlinked.Error()
+					if nt.deliverNotification(n) && !seen[sub.ID] {
+						seen[sub.ID] = true
+						unlinked = append(unlinked, sub.Telegram)
+					}
+					continue
+				}
+
+				// Время отправки и тихие часы подписчика решают, когда уйдёт напоминание, а дайджест - каким сообщением.
+				if deliverAt := settings.DeliverAt(day, at, current); deliverAt.After(current) {
+					n.Status = notify.StatusScheduled
+					n.NextAttempt = deliverAt
+				}
+				if !settings.Digest {
+					nt.deliverNotification(n)
+					continue
+				}
+				if len(digests[sub.ID]) == 0 {
+					digestOrder = append(digestOrder, sub.ID)
+					languages[sub.ID] = userLanguage(nil, &sub)
+				}
+				if !hasCelebrant(digests[sub.ID], u.ID) {
+					digests[sub.ID] = append(digests[sub.ID], n)
+				}
+			}
+		}
+	}
+
+	for _, subID := range digestOrder {
+		nt.deliverDigest(languages[subID], digests[subID])
+	}
+
+	if nt.NudgeChatID != 0 && len(unlinked) > 0 {
+		nt.nudge(nudgeText(unlinked), at)
+	}
+	return nil
+}
+
+// hasCelebrant сообщает, есть ли среди уведомлений напоминание об имениннике userID.
+func hasCelebrant(list []*notify.Notification, userID int64) bool {
+	for _, n := range list {
+		if n.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// deliverDigest отправляет напоминания одному подписчику за день одним сообщением на языке lang. Его несёт
+// первое новое уведомление, а остальные записываются в журнал со статусом digest, чтобы повторный запуск их не отправил.
+func (nt *Notifier) deliverDigest(lang string, list []*notify.Notification) {
+	for i, n := range list {
+		n.Text = digestText(lang, list[i:])
+		if !nt.deliverNotification(n) {
+			continue
+		}
+		for _, rest := range list[i+1:] {
+			rest.Status = notify.StatusDigest
+			rest.NextAttempt = time.Time{}
+			nt.deliverNotification(rest)
+		}
+		return
+	}
+}
+
+// digestText объединяет тексты напоминаний в одно сообщение.
+func digestText(lang string, list []*notify.Notification) string {
+	if len(list) == 1 {
+		return list[0].Text
+	}
+	texts := make([]string, 0, len(list))
+	for _, n := range list {
+		texts = append(texts, n.Text)
+	}
+	return messages.T(lang, msgDigest) + "\n" + strings.Join(texts, "\n")
+}
+
+// catalog загружает шаблоны напоминаний. Если их не удалось загрузить, используются шаблоны по умолчанию.
+func (nt *Notifier) catalog() *greeting.Catalog {
+	if nt.Templates == nil {
+		return greeting.NewCatalog(nil)
+	}
+	templates, err := nt.Templates.GetTemplates()
+	if err != nil {
+		fmt.Println("Error fetching templates:", err)
+	}
+	return greeting.NewCatalog(templates)
+}
+
+// greetingData возвращает поля шаблона напоминания о дне рождения next именинника u.
+func greetingData(u user.User, next time.Time) greeting.Data {
+	data := greeting.Data{
+		Name:       fullName(u),
+		FirstName:  u.FirstName,
+		MiddleName: u.MiddleName,
+		LastName:   u.LastName,
+		Gender:     u.Gender,
+		Department: u.Department,
+		Date:       next.Format("02.01"),
+		Weekday:    next.Weekday(),
+	}
+	if born, err := time.Parse(user.BirthdayLayout, u.Birthday); err == nil {
+		data.Age = next.Year() - born.Year()
+	}
+	return data
+}
+
+// reminder - напоминание подписчикам, которые просили напомнить за offset дней до дня рождения.
+// Текст подставляется в шаблон вида kind для каждого подписчика.
+type reminder struct {
+	offset int
+	kind   string
+	data   greeting.Data
+}
+
+// reminders возвращает напоминания о дне рождения next, который у именинника с данными data наступит через daysBefore дней.
+// Поздравление в нерабочий день переносится на последний рабочий день перед ним: в этот день подписчики,
+// просившие напомнить в сам день, получают его вместе с обычными напоминаниями, а в сам день - нет.
+func (nt *Notifier) reminders(data greeting.Data, next time.Time, daysBefore int, day time.Time, late int) []reminder {
+	regular := reminder{offset: daysBefore, kind: reminderKind(daysBefore - late), data: data}
+	regular.data.DaysUntil = daysBefore - late
+	if nt.Calendar == nil || nt.Calendar.IsWorkday(next) {
+		return []reminder{regular}
+	}
+
+	workday, ok := nt.Calendar.LastWorkdayBefore(next)
+	if !ok {
+		return []reminder{regular}
+	}
+	if daysBefore == 0 {
+		return nil
+	}
+	if !sameDate(workday, day) {
+		return []reminder{regular}
+	}
+
+	shifted := reminder{offset: 0, kind: greeting.KindDayOff, data: data}
+	shifted.data.DaysUntil = daysBefore
+	if late > 0 {
+		shifted.kind, shifted.data = regular.kind, regular.data
+	}
+	// Перенесённое поздравление идёт первым: если подписчик просил и его, и напоминание за несколько дней,
+	// журнал пропустит второе уведомление за тот же день.
+	return []reminder{shifted, regular}
+}
+
+// sameDate сообщает, совпадают ли календарные даты a и b без учёта часовых поясов.
+func sameDate(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.Month() == b.Month() && a.Day() == b.Day()
+}
+
+// location возвращает часовой пояс пользователя. Пустой или неизвестный часовой пояс заменяется часовым поясом сервиса.
+func (nt *Notifier) location(timezone string) *time.Location {
+	if timezone == "" {
+		return nt.Location
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		fmt.Println("Error loading time zone:", err)
+		return nt.Location
+	}
+	return loc
+}
+
+// deliverNotification записывает уведомление в журнал и ставит его в очередь, если подписчику можно написать.
+// Возвращает false, если уведомление уже было в журнале или его не удалось записать: благодаря журналу
+// повторный запуск рассылки за тот же день ничего не дублирует.
+func (nt *Notifier) deliverNotification(n *notify.Notification) bool {
+	return nt.logNotification(n) == nil
+}
+
+// logNotification делает то же, что deliverNotification, но возвращает ошибку записи в журнал:
+// notify.ErrDuplicate, если уведомление уже было в журнале.
+func (nt *Notifier) logNotification(n *notify.Notification) error {
+	id, err := nt.Log.CreateNotification(n)
+	if errors.Is(err, notify.ErrDuplicate) {
+		return err
+	}
+	if err != nil {
+		fmt.Println("Error saving notification:", err)
+		return err
+	}
+
+	n.ID = id
+	// Запланированные уведомления поставит в очередь RetryNotifications, остальные отправлять не нужно.
+	if n.Status == "" {
+		nt.enqueue(n)
+	}
+	return nil
+}
+
+// enqueue ставит уведомление в очередь. Если очередь недоступна, уведомление откладывается,
+// и его поставит в очередь RetryNotifications.
+func (nt *Notifier) enqueue(n *notify.Notification) {
+	payload, err := json.Marshal(n)
+	if err == nil {
+		_, err = nt.Queue.Enqueue(payload)
+	}
+	if err == nil {
+		return
+	}
+
+	fmt.Println("Error enqueueing notification:", err)
+	if err = nt.Log.MarkRetry(n.ID, now().Add(retryBaseDelay), err.Error()); err != nil {
+		fmt.Println("Error updating notification:", err)
+	}
+}
+
+// nudge отправляет просьбу привязать телеграм в общий чат не раньше at: рассылка планируется с начала дня,
+// и писать в общий чат ночью не нужно. Отложенные просьбы отправляет RetryNotifications; при перезапуске они теряются.
+func (nt *Notifier) nudge(text string, at time.Time) {
+	if at.After(now()) {
+		nt.mu.Lock()
+		nt.nudges = append(nt.nudges, pendingNudge{text: text, at: at})
+		nt.mu.Unlock()
+		return
+	}
+
+	if _, err := nt.Sender.Send(tgbotapi.NewMessage(nt.NudgeChatID, text)); err != nil {
+		fmt.Println("Error sending nudge:", err)
+	}
+}
+
+// sendDueNudges отправляет отложенные просьбы привязать телеграм, время которых наступило.
+func (nt *Notifier) sendDueNudges() {
+	nt.mu.Lock()
+	var due, rest []pendingNudge
+	for _, p := range nt.nudges {
+		if p.at.After(now()) {
+			rest = append(rest, p)
+		} else {
+			due = append(due, p)
+		}
+	}
+	nt.nudges = rest
+	nt.mu.Unlock()
+
+	for _, p := range due {
+		nt.nudge(p.text, p.at)
+	}
+}
+
+// nudgeText просит подписчиков без привязанного телеграма написать боту. Просьба уходит в общий чат,
+// поэтому она на языке по умолчанию.
+func nudgeText(telegrams []string) string {
+	return messages.T(i18n.DefaultLanguage, msgNudge, strings.Join(telegrams, ", "))
+}
+
+// reminderKind возвращает вид шаблона напоминания о дне рождения, который наступит через daysBefore дней.
+// Отрицательное daysBefore значит, что день рождения уже прошёл.
+func reminderKind(daysBefore int) string {
+	switch {
+	case daysBefore < 0:
+		return greeting.KindLate
+	case daysBefore == 0:
+		return greeting.KindToday
+	case daysBefore == 1:
+		return greeting.KindTomorrow
+	default:
+		return greeting.KindSoon
+	}
+}
+
+func sendTelegramNotification(bot Sender, chatID int64, text string) (int, error) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	sent, err := bot.Send(msg)
+	if err != nil {
+		fmt.Println("Error sending Telegram message:", err)
+		return 0, err
+	}
+	return sent.MessageID, nil
+}
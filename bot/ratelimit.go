@@ -0,0 +1,146 @@
+package bot
+
+import (
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"sync"
+	"time"
+)
+
+// maxIdleChats - при таком числе корзин по чатам простаивающие корзины удаляются.
+const maxIdleChats = 1000
+
+// telegramAPI - методы *tgbotapi.BotAPI, через которые бот отправляет запросы в телеграм.
+type telegramAPI interface {
+	Sender
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+}
+
+// tokenBucket пополняется на rate токенов в секунду и вмещает не больше burst токенов.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst float64, t time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: t}
+}
+
+// refill начисляет токены, накопившиеся к моменту t.
+func (b *tokenBucket) refill(t time.Time) {
+	if t.After(b.last) {
+		b.tokens = min(b.burst, b.tokens+t.Sub(b.last).Seconds()*b.rate)
+		b.last = t
+	}
+}
+
+// delay возвращает, через сколько после t в корзине появится токен.
+func (b *tokenBucket) delay(t time.Time) time.Duration {
+	b.refill(t)
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) full(t time.Time) bool {
+	b.refill(t)
+	return b.tokens >= b.burst
+}
+
+// rateLimitedSender ограничивает частоту запросов в телеграм общим лимитом бота и лимитом на каждый чат.
+// Вызовы блокируются, пока лимиты не позволят отправить запрос. Безопасен для использования из нескольких горутин.
+type rateLimitedSender struct {
+	api      telegramAPI
+	mu       sync.Mutex
+	global   *tokenBucket
+	chats    map[int64]*tokenBucket
+	chatRate float64
+	clock    func() time.Time
+	sleep    func(time.Duration)
+}
+
+// newRateLimitedSender создаёт отправителя, который шлёт не больше globalRate запросов в секунду
+// всего и не больше chatRate запросов в секунду в один чат.
+func newRateLimitedSender(api telegramAPI, globalRate, chatRate int) *rateLimitedSender {
+	return &rateLimitedSender{
+		api:      api,
+		global:   newTokenBucket(float64(globalRate), float64(globalRate), time.Now()),
+		chats:    make(map[int64]*tokenBucket),
+		chatRate: float64(chatRate),
+		clock:    time.Now,
+		sleep:    time.Sleep,
+	}
+}
+
+func (s *rateLimitedSender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	s.wait(chatID(c))
+	return s.api.Send(c)
+}
+
+func (s *rateLimitedSender) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	s.wait(chatID(c))
+	return s.api.Request(c)
+}
+
+// wait ждёт, пока и в общей корзине, и в корзине чата появится токен, и забирает их.
+// Запросы без чата, например ответы на нажатия кнопок, ограничиваются только общим лимитом.
+func (s *rateLimitedSender) wait(chat int64) {
+	for {
+		s.mu.Lock()
+		t := s.clock()
+		delay := s.global.delay(t)
+
+		var bucket *tokenBucket
+		if chat != 0 {
+			bucket = s.chatBucket(chat, t)
+			delay = max(delay, bucket.delay(t))
+		}
+
+		if delay == 0 {
+			s.global.tokens--
+			if bucket != nil {
+				bucket.tokens--
+			}
+			s.mu.Unlock()
+			return
+		}
+		s.mu.Unlock()
+
+		s.sleep(delay)
+	}
+}
+
+func (s *rateLimitedSender) chatBucket(chat int64, t time.Time) *tokenBucket {
+	bucket, ok := s.chats[chat]
+	if ok {
+		return bucket
+	}
+
+	if len(s.chats) >= maxIdleChats {
+		for id, b := range s.chats {
+			if b.full(t) {
+				delete(s.chats, id)
+			}
+		}
+	}
+
+	bucket = newTokenBucket(s.chatRate, 1, t)
+	s.chats[chat] = bucket
+	return bucket
+}
+
+// chatID возвращает чат, в который уходит запрос, или 0, если запрос не адресован чату.
+func chatID(c tgbotapi.Chattable) int64 {
+	switch v := c.(type) {
+	case tgbotapi.MessageConfig:
+		return v.ChatID
+	case tgbotapi.EditMessageTextConfig:
+		return v.ChatID
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		return v.ChatID
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,70 @@
+package bot
+
+import (
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// fakeAPI запоминает, в какой момент по часам теста пришёл каждый запрос.
+type fakeAPI struct {
+	clock *time.Time
+	calls []time.Duration
+	start time.Time
+}
+
+func (f *fakeAPI) Send(tgbotapi.Chattable) (tgbotapi.Message, error) {
+	f.calls = append(f.calls, f.clock.Sub(f.start))
+	return tgbotapi.Message{}, nil
+}
+
+func (f *fakeAPI) Request(tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	f.calls = append(f.calls, f.clock.Sub(f.start))
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func newTestLimiter(globalRate, chatRate int) (*rateLimitedSender, *fakeAPI) {
+	clock := time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+	api := &fakeAPI{clock: &clock, start: clock}
+
+	s := newRateLimitedSender(api, globalRate, chatRate)
+	s.global = newTokenBucket(float64(globalRate), float64(globalRate), clock)
+	s.clock = func() time.Time { return clock }
+	s.sleep = func(d time.Duration) { clock = clock.Add(d) }
+	return s, api
+}
+
+func TestRateLimitedSenderPerChat(t *testing.T) {
+	s, api := newTestLimiter(30, 1)
+
+	for i := 0; i < 3; i++ {
+		_, err := s.Send(tgbotapi.NewMessage(1, "hi"))
+		assert.NoError(t, err)
+	}
+	// В другой чат можно писать сразу.
+	_, err := s.Send(tgbotapi.NewMessage(2, "hi"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []time.Duration{0, time.Second, 2 * time.Second, 2 * time.Second}, api.calls)
+}
+
+func TestRateLimitedSenderGlobal(t *testing.T) {
+	s, api := newTestLimiter(2, 1)
+
+	for chat := int64(1); chat <= 4; chat++ {
+		_, err := s.Send(tgbotapi.NewMessage(chat, "hi"))
+		assert.NoError(t, err)
+	}
+	// Ответ на нажатие кнопки не привязан к чату и ограничен только общим лимитом.
+	_, err := s.Request(tgbotapi.NewCallback("1", ""))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []time.Duration{0, 0, 500 * time.Millisecond, time.Second, 1500 * time.Millisecond}, api.calls)
+}
+
+func TestChatID(t *testing.T) {
+	assert.Equal(t, int64(42), chatID(tgbotapi.NewMessage(42, "hi")))
+	assert.Equal(t, int64(42), chatID(tgbotapi.NewEditMessageText(42, 1, "hi")))
+	assert.Equal(t, int64(0), chatID(tgbotapi.NewCallback("1", "")))
+}
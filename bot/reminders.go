@@ -0,0 +1,57 @@
+package bot
+
+import (
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"rutubeTest/pkg/user"
+	"strconv"
+	"strings"
+)
+
+// remindHandler задаёт, за сколько дней до дня рождения пользователя из первого аргумента присылать напоминания.
+// Например, "/remind @colleague 7 1 0" - за неделю, накануне и в сам день.
+func remindHandler(update tgbotapi.Update, args []string, me *user.User, userRepo user.UserRepo) []tgbotapi.MessageConfig {
+	if me == nil {
+		return notLinked(update)
+	}
+	lang := userLanguage(update.Message.From, me)
+
+	userID, err := resolveUserID(args[0], userRepo)
+	if err != nil {
+		return reply(update, args[0]+": "+errorText(lang, err))
+	}
+
+	reminders := make([]int, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		d, err := strconv.Atoi(arg)
+		if err != nil {
+			return reply(update, errorText(lang, user.ErrBadReminders))
+		}
+		reminders = append(reminders, d)
+	}
+
+	reminders, err = userRepo.SetReminders(userID, me.ID, reminders)
+	if err != nil {
+		return reply(update, args[0]+": "+errorText(lang, err))
+	}
+
+	return reply(update, messages.T(lang, msgRemindersSet, args[0], remindersText(lang, reminders)))
+}
+
+// remindersText перечисляет смещения напоминаний словами, например "за 7 дн., накануне и в день рождения".
+func remindersText(lang string, reminders []int) string {
+	parts := make([]string, 0, len(reminders))
+	for _, r := range reminders {
+		switch r {
+		case 0:
+			parts = append(parts, messages.T(lang, msgOnBirthday))
+		case 1:
+			parts = append(parts, messages.T(lang, msgDayBefore))
+		default:
+			parts = append(parts, messages.T(lang, msgDaysBefore, r))
+		}
+	}
+	if len(parts) < 2 {
+		return strings.Join(parts, "")
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + messages.T(lang, msgAnd) + parts[len(parts)-1]
+}
@@ -0,0 +1,80 @@
+package bot
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"rutubeTest/pkg/user"
+	"testing"
+)
+
+func TestRemindHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+
+	me := &user.User{ID: 1, Telegram: "@tester"}
+
+	tests := []struct {
+		name       string
+		text       string
+		setupMocks func()
+		wantText   string
+	}{
+		{
+			name: "Напоминания по @username",
+			text: "/remind @john 0 7 1",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().GetUserByTelegram("@john").Return(&user.User{ID: 2, Telegram: "@john"}, nil)
+				mockRepo.EXPECT().SetReminders(int64(2), int64(1), []int{0, 7, 1}).Return([]int{7, 1, 0}, nil)
+			},
+			wantText: "@john: буду напоминать за 7 дн., накануне и в день рождения",
+		},
+		{
+			name: "Одно напоминание по id",
+			text: "/remind 2 3",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().SetReminders(int64(2), int64(1), []int{3}).Return([]int{3}, nil)
+			},
+			wantText: "2: буду напоминать за 3 дн.",
+		},
+		{
+			name: "Нет подписки",
+			text: "/remind 2 1",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().SetReminders(int64(2), int64(1), []int{1}).Return(nil, user.ErrNoSubscription)
+			},
+			wantText: "2: Сначала подпишитесь на пользователя.",
+		},
+		{
+			name: "Не число",
+			text: "/remind 2 неделя",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+			},
+			wantText: "Укажите, за сколько дней напомнить: числа от 0 до 30.",
+		},
+		{
+			name: "Не хватает аргументов",
+			text: "/remind 2",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+			},
+			wantText: "Использование: /remind <id|@username> <дней> ...",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			messages := newRouter(testBotName, user.LeapDayMar1).handle(newUpdate(tc.text), mockRepo)
+
+			assert.Len(t, messages, 1)
+			assert.Equal(t, tc.wantText, messages[0].Text)
+		})
+	}
+}
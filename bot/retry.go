@@ -0,0 +1,177 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"net/http"
+	"rutubeTest/pkg/i18n"
+	"rutubeTest/pkg/notify"
+	"time"
+)
+
+const (
+	// maxSendAttempts - после стольких неудачных попыток уведомление считается недоставленным.
+	maxSendAttempts = 6
+	// retryBaseDelay - пауза перед первым повтором, дальше она удваивается.
+	retryBaseDelay = 30 * time.Second
+	// retryMaxDelay - пауза между повторами не растёт больше этого значения.
+	retryMaxDelay = time.Hour
+	// retryInterval - как часто проверяются уведомления, которые пора отправить повторно.
+	retryInterval = time.Minute
+	// sendingTimeout - через столько уведомление, которое воркер забрал, но не отметил отправленным,
+	// считается брошенным упавшим воркером.
+	sendingTimeout = 10 * time.Minute
+	// reportLock - имя блокировки отчётов о недоставленных уведомлениях. Она своя, а не блокировка рассылки,
+	// чтобы ежеминутный отчёт не откладывал ежедневную рассылку на время TTL.
+	reportLock = "failure-report"
+)
+
+// attemptDelivery отправляет уведомление и записывает результат: при временной ошибке
+// откладывает повтор с экспоненциальной паузой, при постоянной отмечает уведомление недоставленным.
+func (nt *Notifier) attemptDelivery(n *notify.Notification) {
+	messageID, err := sendTelegramNotification(nt.Sender, n.ChatID, n.Text)
+	if err == nil {
+		err = nt.Log.MarkSent(n.ID, messageID)
+		if err != nil {
+			fmt.Println("Error updating notification:", err)
+		}
+		return
+	}
+
+	attempts := n.Attempts + 1
+	retryAfter, permanent := classifySendError(err)
+	if permanent || attempts >= maxSendAttempts {
+		err = nt.Log.MarkFailed(n.ID, err.Error())
+	} else {
+		err = nt.Log.MarkRetry(n.ID, now().Add(retryDelay(attempts, retryAfter)), err.Error())
+	}
+	if err != nil {
+		fmt.Println("Error updating notification:", err)
+	}
+}
+
+// classifySendError разбирает ошибку телеграма. permanent означает, что повтор не поможет,
+// например пользователь заблокировал бота; retryAfter - пауза, которую просит телеграм при 429.
+func classifySendError(err error) (retryAfter time.Duration, permanent bool) {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		// Сетевые ошибки и ответы, которые не удалось разобрать, считаются временными.
+		return 0, false
+	}
+
+	retryAfter = time.Duration(tgErr.RetryAfter) * time.Second
+	switch {
+	case tgErr.Code == http.StatusTooManyRequests:
+		return retryAfter, false
+	case tgErr.Code >= http.StatusInternalServerError:
+		return retryAfter, false
+	case tgErr.Code >= http.StatusBadRequest:
+		return 0, true
+	default:
+		return retryAfter, false
+	}
+}
+
+// retryDelay возвращает паузу перед попыткой номер attempts+1: retryBaseDelay, удвоенная на каждую
+// неудачную попытку и ограниченная retryMaxDelay, но не меньше retryAfter.
+func retryDelay(attempts int, retryAfter time.Duration) time.Duration {
+	delay := retryMaxDelay
+	if attempts < 32 {
+		delay = min(retryBaseDelay<<(attempts-1), retryMaxDelay)
+	}
+	return max(delay, retryAfter)
+}
+
+// RetryNotifications ставит в очередь отложенные и запланированные уведомления, отправляет отложенные
+// просьбы привязать телеграм и сообщает администраторам о тех уведомлениях, которые доставить не удалось.
+func (nt *Notifier) RetryNotifications() {
+	current := now()
+	nt.reclaimStaleSending(current)
+
+	due, err := nt.Log.GetDueRetries(current)
+	if err != nil {
+		fmt.Println("Error fetching notifications to retry:", err)
+		return
+	}
+
+	for i := range due {
+		// Другая реплика могла забрать уведомление раньше.
+		claimed, err := nt.Log.ClaimRetry(due[i].ID)
+		if err != nil {
+			fmt.Println("Error claiming notification:", err)
+			continue
+		}
+		if claimed {
+			nt.enqueue(&due[i])
+		}
+	}
+
+	nt.sendDueNudges()
+	nt.reportFailures()
+}
+
+// reclaimStaleSending возвращает на повтор уведомления, которые дольше sendingTimeout остаются в sending:
+// воркер упал между ClaimSending и записью результата. Если воркер успел отправить сообщение,
+// повтор его продублирует, но без этого уведомление не ушло бы никогда.
+func (nt *Notifier) reclaimStaleSending(current time.Time) {
+	stale, err := nt.Log.ReclaimStaleSending(current.Add(-sendingTimeout), maxSendAttempts, current)
+	if err != nil {
+		fmt.Println("Error reclaiming stale notifications:", err)
+		return
+	}
+	if stale > 0 {
+		fmt.Printf("Reclaimed stale notifications: %d\n", stale)
+	}
+}
+
+// reportFailures отправляет администраторам недоставленные уведомления.
+// Уведомление отмечается сообщённым, только если его получили все администраторы.
+// Отчёт отправляет только реплика, взявшая блокировку reportLock.
+func (nt *Notifier) reportFailures() {
+	if len(nt.AdminChatIDs) == 0 {
+		return
+	}
+
+	if nt.Locker != nil {
+		// Отчёт занимает секунды, поэтому блокировка не продлевается: её TTL - интервал проверки повторов.
+		token, ok, err := nt.Locker.TryLock(reportLock, retryInterval)
+		if err != nil {
+			fmt.Println("Error taking report lock:", err)
+			return
+		}
+		if !ok {
+			return // Отчёт отправляет другая реплика.
+		}
+		defer func() {
+			if err := nt.Locker.Unlock(reportLock, token); err != nil {
+				fmt.Println("Error releasing report lock:", err)
+			}
+		}()
+	}
+
+	failed, err := nt.Log.GetUnreportedFailures()
+	if err != nil {
+		fmt.Println("Error fetching failed notifications:", err)
+		return
+	}
+
+	for _, n := range failed {
+		text := messages.T(i18n.DefaultLanguage, msgDeliveryFailure,
+			n.SubscriberID, n.UserID, n.Day.Format(notify.DateLayout), n.Attempts, n.LastError)
+
+		reported := true
+		for _, chatID := range nt.AdminChatIDs {
+			if _, err = nt.Sender.Send(tgbotapi.NewMessage(chatID, text)); err != nil {
+				fmt.Println("Error sending report to admin:", err)
+				reported = false
+			}
+		}
+		if !reported {
+			continue
+		}
+		if err = nt.Log.MarkReported(n.ID); err != nil {
+			fmt.Println("Error updating notification:", err)
+		}
+	}
+}
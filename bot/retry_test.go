@@ -0,0 +1,221 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"rutubeTest/pkg/lock"
+	"rutubeTest/pkg/notify"
+	"rutubeTest/pkg/queue"
+	"testing"
+	"time"
+)
+
+func TestClassifySendError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantRetryAfter time.Duration
+		wantPermanent  bool
+	}{
+		{
+			name:           "Слишком много запросов",
+			err:            &tgbotapi.Error{Code: 429, Message: "Too Many Requests", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 15}},
+			wantRetryAfter: 15 * time.Second,
+		},
+		{
+			name: "Ошибка сервера телеграма",
+			err:  &tgbotapi.Error{Code: 502, Message: "Bad Gateway"},
+		},
+		{
+			name:          "Пользователь заблокировал бота",
+			err:           &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"},
+			wantPermanent: true,
+		},
+		{
+			name:          "Чат не найден",
+			err:           &tgbotapi.Error{Code: 400, Message: "Bad Request: chat not found"},
+			wantPermanent: true,
+		},
+		{
+			name: "Сетевая ошибка",
+			err:  fmt.Errorf("connection reset by peer"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			retryAfter, permanent := classifySendError(tc.err)
+			assert.Equal(t, tc.wantRetryAfter, retryAfter)
+			assert.Equal(t, tc.wantPermanent, permanent)
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, retryBaseDelay, retryDelay(1, 0))
+	assert.Equal(t, 4*retryBaseDelay, retryDelay(3, 0))
+	assert.Equal(t, retryMaxDelay, retryDelay(20, 0))
+	assert.Equal(t, retryMaxDelay, retryDelay(100, 0))
+	assert.Equal(t, 2*time.Minute, retryDelay(1, 2*time.Minute))
+}
+
+func TestRetryNotifications(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockNotify := notify.NewMockNotifyRepo(ctrl)
+	mockQueue := queue.NewMockQueue(ctrl)
+	mockSender := NewMockSender(ctrl)
+
+	now = func() time.Time { return time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+	today := now()
+
+	pending := notify.Notification{ID: 10, UserID: 1, SubscriberID: 2, ChatID: 200, Text: "Сегодня день рождения у John!", Attempts: 2}
+	payload, err := json.Marshal(&pending)
+	assert.NoError(t, err)
+	failed := notify.Notification{
+		ID: 11, UserID: 1, SubscriberID: 3, Day: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC),
+		Attempts: 1, LastError: "Forbidden: bot was blocked by the user",
+	}
+	report := "Не удалось доставить напоминание пользователю с id 3 (именинник id 1, 2024-06-10) после 1 попыток: Forbidden: bot was blocked by the user"
+
+	tests := []struct {
+		name       string
+		admins     []int64
+		setupMocks func()
+	}{
+		{
+			name: "Отложенное уведомление снова ставится в очередь",
+			setupMocks: func() {
+				mockNotify.EXPECT().ReclaimStaleSending(today.Add(-sendingTimeout), maxSendAttempts, today).Return(int64(0), nil)
+				mockNotify.EXPECT().GetDueRetries(today).Return([]notify.Notification{pending}, nil)
+				mockNotify.EXPECT().ClaimRetry(int64(10)).Return(true, nil)
+				mockQueue.EXPECT().Enqueue(payload).Return("1", nil)
+			},
+		},
+		{
+			name: "Очередь недоступна",
+			setupMocks: func() {
+				mockNotify.EXPECT().ReclaimStaleSending(today.Add(-sendingTimeout), maxSendAttempts, today).Return(int64(0), nil)
+				mockNotify.EXPECT().GetDueRetries(today).Return([]notify.Notification{pending}, nil)
+				mockNotify.EXPECT().ClaimRetry(int64(10)).Return(true, nil)
+				mockQueue.EXPECT().Enqueue(payload).Return("", fmt.Errorf("connection refused"))
+				mockNotify.EXPECT().MarkRetry(int64(10), today.Add(retryBaseDelay), "connection refused").Return(nil)
+			},
+		},
+		{
+			name: "Уведомление забрала другая реплика",
+			setupMocks: func() {
+				mockNotify.EXPECT().ReclaimStaleSending(today.Add(-sendingTimeout), maxSendAttempts, today).Return(int64(0), nil)
+				mockNotify.EXPECT().GetDueRetries(today).Return([]notify.Notification{pending}, nil)
+				mockNotify.EXPECT().ClaimRetry(int64(10)).Return(false, nil)
+			},
+		},
+		{
+			name:   "Администраторы получают недоставленные уведомления",
+			admins: []int64{900, 901},
+			setupMocks: func() {
+				mockNotify.EXPECT().ReclaimStaleSending(today.Add(-sendingTimeout), maxSendAttempts, today).Return(int64(0), nil)
+				mockNotify.EXPECT().GetDueRetries(today).Return(nil, nil)
+				mockNotify.EXPECT().GetUnreportedFailures().Return([]notify.Notification{failed}, nil)
+				mockSender.EXPECT().Send(tgbotapi.NewMessage(900, report)).Return(tgbotapi.Message{}, nil)
+				mockSender.EXPECT().Send(tgbotapi.NewMessage(901, report)).Return(tgbotapi.Message{}, nil)
+				mockNotify.EXPECT().MarkReported(int64(11)).Return(nil)
+			},
+		},
+		{
+			name:   "Отчёт не отмечается, если админ его не получил",
+			admins: []int64{900},
+			setupMocks: func() {
+				mockNotify.EXPECT().ReclaimStaleSending(today.Add(-sendingTimeout), maxSendAttempts, today).Return(int64(0), nil)
+				mockNotify.EXPECT().GetDueRetries(today).Return(nil, nil)
+				mockNotify.EXPECT().GetUnreportedFailures().Return([]notify.Notification{failed}, nil)
+				mockSender.EXPECT().Send(tgbotapi.NewMessage(900, report)).Return(tgbotapi.Message{}, fmt.Errorf("timeout"))
+			},
+		},
+		{
+			name: "Брошенное воркером уведомление снова ставится в очередь",
+			setupMocks: func() {
+				mockNotify.EXPECT().ReclaimStaleSending(today.Add(-sendingTimeout), maxSendAttempts, today).Return(int64(1), nil)
+				mockNotify.EXPECT().GetDueRetries(today).Return([]notify.Notification{pending}, nil)
+				mockNotify.EXPECT().ClaimRetry(int64(10)).Return(true, nil)
+				mockQueue.EXPECT().Enqueue(payload).Return("1", nil)
+			},
+		},
+		{
+			name: "Ошибка возврата брошенных уведомлений не мешает повторам",
+			setupMocks: func() {
+				mockNotify.EXPECT().ReclaimStaleSending(today.Add(-sendingTimeout), maxSendAttempts, today).Return(int64(0), fmt.Errorf("database error"))
+				mockNotify.EXPECT().GetDueRetries(today).Return(nil, nil)
+			},
+		},
+		{
+			name: "Ошибка чтения журнала",
+			setupMocks: func() {
+				mockNotify.EXPECT().ReclaimStaleSending(today.Add(-sendingTimeout), maxSendAttempts, today).Return(int64(0), nil)
+				mockNotify.EXPECT().GetDueRetries(today).Return(nil, fmt.Errorf("database error"))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			notifier := &Notifier{Log: mockNotify, Queue: mockQueue, Sender: mockSender, AdminChatIDs: tc.admins}
+			notifier.RetryNotifications()
+		})
+	}
+}
+
+func TestReportFailuresLock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockNotify := notify.NewMockNotifyRepo(ctrl)
+	mockSender := NewMockSender(ctrl)
+	mockLocker := lock.NewMockLocker(ctrl)
+
+	failed := notify.Notification{ID: 11, UserID: 1, SubscriberID: 3, Day: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), Attempts: 6}
+
+	tests := []struct {
+		name       string
+		setupMocks func()
+	}{
+		{
+			name: "Отчёт отправляется под блокировкой",
+			setupMocks: func() {
+				mockLocker.EXPECT().TryLock(reportLock, retryInterval).Return("token", true, nil)
+				mockNotify.EXPECT().GetUnreportedFailures().Return([]notify.Notification{failed}, nil)
+				mockSender.EXPECT().Send(gomock.Any()).Return(tgbotapi.Message{}, nil)
+				mockNotify.EXPECT().MarkReported(int64(11)).Return(nil)
+				mockLocker.EXPECT().Unlock(reportLock, "token").Return(nil)
+			},
+		},
+		{
+			name: "Отчёт отправляет другая реплика",
+			setupMocks: func() {
+				mockLocker.EXPECT().TryLock(reportLock, retryInterval).Return("", false, nil)
+			},
+		},
+		{
+			name: "Блокировка недоступна",
+			setupMocks: func() {
+				mockLocker.EXPECT().TryLock(reportLock, retryInterval).Return("", false, fmt.Errorf("connection refused"))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			notifier := &Notifier{Log: mockNotify, Sender: mockSender, AdminChatIDs: []int64{900}, Locker: mockLocker}
+			notifier.reportFailures()
+		})
+	}
+}
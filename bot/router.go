@@ -0,0 +1,254 @@
+package bot
+
+import (
+	"errors"
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"log"
+	"rutubeTest/pkg/user"
+	"strings"
+)
+
+// errBadTarget - аргумент команды не похож ни на id, ни на @username.
+var errBadTarget = errors.New("bad target")
+
+// handlerFunc обрабатывает команду. args - аргументы команды без её имени, me - учётная запись автора сообщения
+// или nil, если его телеграм не привязан.
+type handlerFunc func(update tgbotapi.Update, args []string, me *user.User, userRepo user.UserRepo) []tgbotapi.MessageConfig
+
+// command описывает команду бота. Usage и Description - ключи сообщений из messages.
+type command struct {
+	Name        string
+	Usage       string // Аргументы команды для справки, например usageTargets; пустая строка - без аргументов.
+	Description string
+	MinArgs     int
+	MaxArgs     int // Отрицательное значение снимает ограничение сверху.
+	Handler     handlerFunc
+}
+
+// router разбирает текст сообщения и передаёт его обработчику зарегистрированной команды.
+type router struct {
+	botName  string
+	leapDay  user.LeapDayPolicy // Правило для 29 февраля в списках дней рождения.
+	commands []*command
+	byName   map[string]*command
+}
+
+// newRouter создаёт роутер со всеми командами бота.
+// botName нужен, чтобы в группах отвечать только на команды вида /cmd@botname, адресованные этому боту.
+func newRouter(botName string, leapDay user.LeapDayPolicy) *router {
+	r := &router{
+		botName: strings.ToLower(botName),
+		leapDay: leapDay,
+		byName:  make(map[string]*command),
+	}
+
+	r.register(&command{
+		Name:        "/start",
+		Description: cmdStart,
+		MaxArgs:     -1, // Телеграм может передать параметр deep link.
+		Handler:     startHandler,
+	})
+	r.register(&command{
+		Name:        "/users",
+		Usage:       usageUsers,
+		Description: cmdUsers,
+		MaxArgs:     1,
+		Handler:     usersListHandler,
+	})
+	r.register(&command{
+		Name:        "/subscribe",
+		Usage:       usageTargets,
+		Description: cmdSubscribe,
+		MinArgs:     1,
+		MaxArgs:     -1,
+		Handler:     subscribeHandler,
+	})
+	r.register(&command{
+		Name:        "/unsubscribe",
+		Usage:       usageTargets,
+		Description: cmdUnsubscribe,
+		MinArgs:     1,
+		MaxArgs:     -1,
+		Handler:     unsubscribeHandler,
+	})
+	r.register(&command{
+		Name:        "/remind",
+		Usage:       usageRemind,
+		Description: cmdRemind,
+		MinArgs:     2,
+		MaxArgs:     -1,
+		Handler:     remindHandler,
+	})
+	r.register(&command{
+		Name:        "/congrats",
+		Usage:       usageCongrats,
+		Description: cmdCongrats,
+		MinArgs:     2,
+		MaxArgs:     -1,
+		Handler:     congratsHandler,
+	})
+	r.register(&command{
+		Name:        "/timezone",
+		Usage:       usageTimezone,
+		Description: cmdTimezone,
+		MaxArgs:     1,
+		Handler:     timezoneHandler,
+	})
+	r.register(&command{
+		Name:        "/language",
+		Usage:       usageLanguage,
+		Description: cmdLanguage,
+		MaxArgs:     1,
+		Handler:     languageHandler,
+	})
+	r.register(&command{
+		Name:        "/settings",
+		Usage:       usageSettings,
+		Description: cmdSettings,
+		MaxArgs:     3,
+		Handler:     settingsHandler,
+	})
+	r.register(&command{
+		Name:        "/mysubscriptions",
+		Description: cmdSubscriptions,
+		Handler:     r.mySubscriptionsHandler,
+	})
+	r.register(&command{
+		Name:        "/mysubscribers",
+		Description: cmdSubscribers,
+		Handler:     r.mySubscribersHandler,
+	})
+	r.register(&command{
+		Name:        "/upcoming",
+		Usage:       usageUpcoming,
+		Description: cmdUpcoming,
+		MaxArgs:     2,
+		Handler:     r.upcomingHandler,
+	})
+	r.register(&command{
+		Name:        "/help",
+		Description: cmdHelp,
+		Handler:     r.helpHandler,
+	})
+
+	return r
+}
+
+func (r *router) register(cmd *command) {
+	r.commands = append(r.commands, cmd)
+	r.byName[cmd.Name] = cmd
+}
+
+// parseCommand выделяет из текста имя команды и аргументы.
+// Суффикс @botname отрезается; если команда адресована другому боту, ok будет false.
+func (r *router) parseCommand(text string) (name string, args []string, ok bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", nil, false
+	}
+
+	name = strings.ToLower(fields[0])
+	if at := strings.Index(name, "@"); at != -1 {
+		if name[at+1:] != r.botName {
+			return "", nil, false
+		}
+		name = name[:at]
+	}
+
+	return name, fields[1:], true
+}
+
+func (r *router) handle(update tgbotapi.Update, userRepo user.UserRepo) []tgbotapi.MessageConfig {
+	if update.Message == nil {
+		return nil // Нет сообщения для обработки
+	}
+
+	name, args, ok := r.parseCommand(update.Message.Text)
+	if !ok {
+		return nil
+	}
+
+	// Автора ищем один раз: от его учётной записи зависит язык всех ответов, включая ошибки разбора команды.
+	me := linkedUser(update.Message.From, userRepo)
+	lang := userLanguage(update.Message.From, me)
+	cmd, found := r.byName[name]
+	if !found {
+		return reply(update, messages.T(lang, msgUnknownCommand))
+	}
+
+	if len(args) < cmd.MinArgs || (cmd.MaxArgs >= 0 && len(args) > cmd.MaxArgs) {
+		return reply(update, messages.T(lang, msgUsage, cmd.usageLine(lang)))
+	}
+
+	return cmd.Handler(update, args, me, userRepo)
+}
+
+// linkedUser возвращает учётную запись, к которой привязан телеграм from, или nil, если её нет.
+func linkedUser(from *tgbotapi.User, userRepo user.UserRepo) *user.User {
+	me, err := userRepo.GetUserByTelegram("@" + from.UserName)
+	if err != nil {
+		if !errors.Is(err, user.ErrNoUser) {
+			log.Println("can't get user by telegram:", err)
+		}
+		return nil
+	}
+	return me
+}
+
+func (cmd *command) usageLine(lang string) string {
+	if cmd.Usage == "" {
+		return cmd.Name
+	}
+	return cmd.Name + " " + messages.T(lang, cmd.Usage)
+}
+
+func (r *router) helpHandler(update tgbotapi.Update, _ []string, me *user.User, _ user.UserRepo) []tgbotapi.MessageConfig {
+	lang := userLanguage(update.Message.From, me)
+
+	var sb strings.Builder
+	sb.WriteString(messages.T(lang, msgHelp))
+	for _, cmd := range r.commands {
+		sb.WriteString("\n" + cmd.usageLine(lang) + " - " + messages.T(lang, cmd.Description))
+	}
+	return reply(update, sb.String())
+}
+
+// reply формирует ответ в чат, из которого пришло сообщение.
+func reply(update tgbotapi.Update, text string) []tgbotapi.MessageConfig {
+	return []tgbotapi.MessageConfig{tgbotapi.NewMessage(update.Message.Chat.ID, text)}
+}
+
+// notLinked отвечает пользователю, чей телеграм не привязан к учётной записи. Выбранного языка у него нет,
+// поэтому ответ на языке телеграма.
+func notLinked(update tgbotapi.Update) []tgbotapi.MessageConfig {
+	return reply(update, messages.T(userLanguage(update.Message.From, nil), msgNotLinked))
+}
+
+// errorText переводит ошибку в понятный пользователю текст на языке lang. Неизвестные ошибки логируются.
+func errorText(lang string, err error) string {
+	switch {
+	case errors.Is(err, user.ErrNoUser):
+		return messages.T(lang, msgNoUser)
+	case errors.Is(err, user.ErrExists):
+		return messages.T(lang, msgExists)
+	case errors.Is(err, user.ErrNoSubscription):
+		return messages.T(lang, msgNoSubscription)
+	case errors.Is(err, user.ErrBadReminders):
+		return messages.T(lang, msgBadReminders, user.MaxReminderDays)
+	case errors.Is(err, user.ErrBadTimeZone):
+		return messages.T(lang, msgBadTimeZone)
+	case errors.Is(err, user.ErrBadSettings):
+		return messages.T(lang, msgBadSettings)
+	case errors.Is(err, user.ErrBadLanguage):
+		return messages.T(lang, msgBadLanguage)
+	case errors.Is(err, user.ErrBadCongratulation):
+		return messages.T(lang, msgBadCongratulation, user.MaxCongratulationLength)
+	case errors.Is(err, errBadSettingsArgs):
+		return messages.T(lang, msgSettingsUsage)
+	case errors.Is(err, errBadTarget):
+		return messages.T(lang, msgBadTarget)
+	default:
+		log.Println("bot command failed:", err)
+		return messages.T(lang, msgInternal)
+	}
+}
@@ -0,0 +1,183 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log"
+	"rutubeTest/pkg/lock"
+	"rutubeTest/pkg/notify"
+	"time"
+)
+
+// notificationsJob - имя задачи рассылки в таблице запусков.
+const notificationsJob = "notifications"
+
+// scheduler запускает job раз в день в заданное время и помнит последний выполненный день,
+// чтобы после простоя догнать пропущенные дни. День, за который job вернул ошибку, не считается выполненным. Если задан locker, дни обрабатывает только
+// та реплика, которая взяла блокировку с именем name.
+type scheduler struct {
+	name       string
+	sendAt     time.Duration // Смещение от полуночи в часовом поясе loc.
+	loc        *time.Location
+	maxCatchUp int // Сколько пропущенных дней можно догнать, более старые отбрасываются.
+	runs       notify.NotifyRepo
+	job        func(day time.Time) error
+	locker     lock.Locker
+	lockTTL    time.Duration
+}
+
+// run догоняет пропущенные дни и дальше запускает job каждый день в sendAt, пока не отменён ctx.
+func (s *scheduler) run(ctx context.Context) {
+	for {
+		done := s.runLocked(ctx)
+
+		wait := s.nextRun(now()).Sub(now())
+		if !done && s.lockTTL < wait {
+			// Дни обрабатывает другая реплика или день не удался. Пробуем снова через TTL,
+			// чтобы подхватить работу упавшей реплики или повторить день.
+			wait = s.lockTTL
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// runLocked выполняет catchUp под блокировкой и продлевает её, пока job работает.
+// Возвращает false, если блокировку взять не удалось или она была потеряна либо догнать все дни не получилось.
+func (s *scheduler) runLocked(ctx context.Context) bool {
+	if s.locker == nil {
+		return s.catchUp(ctx)
+	}
+
+	token, ok, err := s.locker.TryLock(s.name, s.lockTTL)
+	if err != nil {
+		log.Println("can't take lock:", err)
+		return false
+	}
+	if !ok {
+		log.Printf("%s: job is running on another replica", s.name)
+		return false
+	}
+
+	lockCtx, cancel := context.WithCancel(ctx)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		s.keepLock(lockCtx, cancel, token)
+	}()
+
+	done := s.catchUp(lockCtx)
+
+	lost := lockCtx.Err() != nil && ctx.Err() == nil
+	cancel()
+	<-renewed
+	if lost {
+		return false
+	}
+
+	if err = s.locker.Unlock(s.name, token); err != nil {
+		log.Println("can't release lock:", err)
+	}
+	return done
+}
+
+// keepLock продлевает блокировку каждую треть TTL, пока не отменён ctx.
+// Если блокировку забрали, вызывает lost, чтобы catchUp не начинал следующий день.
+func (s *scheduler) keepLock(ctx context.Context, lost context.CancelFunc, token string) {
+	ticker := time.NewTicker(s.lockTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			err := s.locker.Refresh(s.name, token, s.lockTTL)
+			if errors.Is(err, lock.ErrNotHeld) {
+				log.Printf("%s: lock lost", s.name)
+				lost()
+				return
+			}
+			if err != nil {
+				// Если redis недоступен дольше TTL, следующий Refresh вернёт ErrNotHeld.
+				log.Println("can't refresh lock:", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// catchUp выполняет job за каждый день после последнего запуска, для которого время рассылки уже наступило.
+// Отмена ctx останавливает догон перед очередным днём. Возвращает false, если догон остановила ошибка:
+// день, на котором она случилась, выполнится снова при следующем запуске.
+func (s *scheduler) catchUp(ctx context.Context) bool {
+	due := s.lastDue(now())
+	from := due
+
+	last, err := s.runs.LastRun(s.name)
+	switch {
+	case errors.Is(err, notify.ErrNoRun):
+		// Первый запуск: прошлые дни не догоняем.
+	case err != nil:
+		log.Println("can't get last run:", err)
+		return false
+	default:
+		// Дата из базы без часового пояса, поэтому переносим её в loc по календарным полям.
+		from = time.Date(last.Year(), last.Month(), last.Day()+1, 0, 0, 0, 0, s.loc)
+	}
+
+	if oldest := due.AddDate(0, 0, -s.maxCatchUp); from.Before(oldest) {
+		log.Printf("%s: skipping days from %s to %s", s.name, from.Format(notify.DateLayout), oldest.AddDate(0, 0, -1).Format(notify.DateLayout))
+		from = oldest
+	}
+
+	for day := from; !day.After(due); day = day.AddDate(0, 0, 1) {
+		if ctx.Err() != nil {
+			log.Printf("%s: stopped before %s", s.name, day.Format(notify.DateLayout))
+			return true
+		}
+		if err = s.job(day); err != nil {
+			log.Printf("%s: %s failed: %s", s.name, day.Format(notify.DateLayout), err)
+			return false
+		}
+		if err = s.runs.SetLastRun(s.name, day); err != nil {
+			log.Println("can't save last run:", err)
+			return false
+		}
+	}
+	return true
+}
+
+// sendTime возвращает время рассылки в день day.
+func (s *scheduler) sendTime(day time.Time) time.Time {
+	// Минуты передаются в time.Date, а не прибавляются к полуночи, чтобы переход на летнее время не сдвигал рассылку.
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, int(s.sendAt/time.Minute), 0, 0, s.loc)
+}
+
+// nextRun возвращает ближайшее время рассылки строго после t.
+func (s *scheduler) nextRun(t time.Time) time.Time {
+	next := s.sendTime(s.day(t))
+	if !next.After(t) {
+		next = s.sendTime(s.day(t).AddDate(0, 0, 1))
+	}
+	return next
+}
+
+// lastDue возвращает последний день, время рассылки в который уже наступило к моменту t.
+func (s *scheduler) lastDue(t time.Time) time.Time {
+	today := s.day(t)
+	if s.sendTime(today).After(t) {
+		return today.AddDate(0, 0, -1)
+	}
+	return today
+}
+
+// day возвращает полночь дня t в часовом поясе планировщика.
+func (s *scheduler) day(t time.Time) time.Time {
+	t = t.In(s.loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.loc)
+}
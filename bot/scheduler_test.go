@@ -0,0 +1,241 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"rutubeTest/pkg/lock"
+	"rutubeTest/pkg/notify"
+	"testing"
+	"time"
+)
+
+func TestSchedulerTimes(t *testing.T) {
+	s := &scheduler{sendAt: 9*time.Hour + 30*time.Minute, loc: time.UTC}
+
+	before := time.Date(2024, 6, 10, 8, 0, 0, 0, time.UTC)
+	after := time.Date(2024, 6, 10, 10, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 6, 10, 9, 30, 0, 0, time.UTC), s.nextRun(before))
+	assert.Equal(t, time.Date(2024, 6, 11, 9, 30, 0, 0, time.UTC), s.nextRun(after))
+	assert.Equal(t, time.Date(2024, 6, 11, 9, 30, 0, 0, time.UTC), s.nextRun(time.Date(2024, 6, 10, 9, 30, 0, 0, time.UTC)))
+
+	assert.Equal(t, time.Date(2024, 6, 9, 0, 0, 0, 0, time.UTC), s.lastDue(before))
+	assert.Equal(t, time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), s.lastDue(after))
+}
+
+func TestSchedulerCatchUp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRuns := notify.NewMockNotifyRepo(ctrl)
+
+	now = func() time.Time { return time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name       string
+		setupMocks func()
+		failDay    time.Time
+		wantDays   []time.Time
+		wantOK     bool
+	}{
+		{
+			name: "Первый запуск выполняет только сегодняшний день",
+			setupMocks: func() {
+				mockRuns.EXPECT().LastRun(notificationsJob).Return(time.Time{}, notify.ErrNoRun)
+				mockRuns.EXPECT().SetLastRun(notificationsJob, day(10)).Return(nil)
+			},
+			wantDays: []time.Time{day(10)},
+			wantOK:   true,
+		},
+		{
+			name: "Пропущенные дни догоняются по порядку",
+			setupMocks: func() {
+				mockRuns.EXPECT().LastRun(notificationsJob).Return(day(7), nil)
+				for _, d := range []int{8, 9, 10} {
+					mockRuns.EXPECT().SetLastRun(notificationsJob, day(d)).Return(nil)
+				}
+			},
+			wantDays: []time.Time{day(8), day(9), day(10)},
+			wantOK:   true,
+		},
+		{
+			name: "Сегодня уже выполнено",
+			setupMocks: func() {
+				mockRuns.EXPECT().LastRun(notificationsJob).Return(day(10), nil)
+			},
+			wantDays: nil,
+			wantOK:   true,
+		},
+		{
+			name: "Слишком старые дни отбрасываются",
+			setupMocks: func() {
+				mockRuns.EXPECT().LastRun(notificationsJob).Return(day(1), nil)
+				for _, d := range []int{8, 9, 10} {
+					mockRuns.EXPECT().SetLastRun(notificationsJob, day(d)).Return(nil)
+				}
+			},
+			wantDays: []time.Time{day(8), day(9), day(10)},
+			wantOK:   true,
+		},
+		{
+			name: "Ошибка сохранения останавливает догон",
+			setupMocks: func() {
+				mockRuns.EXPECT().LastRun(notificationsJob).Return(day(8), nil)
+				mockRuns.EXPECT().SetLastRun(notificationsJob, day(9)).Return(fmt.Errorf("database error"))
+			},
+			wantDays: []time.Time{day(9)},
+		},
+		{
+			name: "Неудачный день не отмечается выполненным",
+			setupMocks: func() {
+				mockRuns.EXPECT().LastRun(notificationsJob).Return(day(7), nil)
+				mockRuns.EXPECT().SetLastRun(notificationsJob, day(8)).Return(nil)
+			},
+			failDay:  day(9),
+			wantDays: []time.Time{day(8), day(9)},
+		},
+		{
+			name: "Ошибка чтения последнего запуска",
+			setupMocks: func() {
+				mockRuns.EXPECT().LastRun(notificationsJob).Return(time.Time{}, fmt.Errorf("database error"))
+			},
+			wantDays: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			var days []time.Time
+			s := &scheduler{
+				name:       notificationsJob,
+				sendAt:     9 * time.Hour,
+				loc:        time.UTC,
+				maxCatchUp: 2,
+				runs:       mockRuns,
+				job: func(day time.Time) error {
+					days = append(days, day)
+					if day.Equal(tc.failDay) {
+						return fmt.Errorf("database error")
+					}
+					return nil
+				},
+			}
+
+			assert.Equal(t, tc.wantOK, s.catchUp(context.Background()))
+			assert.Equal(t, tc.wantDays, days)
+		})
+	}
+}
+
+func TestSchedulerRunLocked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRuns := notify.NewMockNotifyRepo(ctrl)
+	mockLocker := lock.NewMockLocker(ctrl)
+
+	now = func() time.Time { return time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 0, 0, 0, 0, time.UTC) }
+	const ttl = 3 * time.Millisecond
+
+	tests := []struct {
+		name       string
+		setupMocks func()
+		jobTime    time.Duration
+		jobErr     error
+		wantDays   []time.Time
+		wantDone   bool
+	}{
+		{
+			name: "Реплика с блокировкой выполняет дни и снимает её",
+			setupMocks: func() {
+				mockLocker.EXPECT().TryLock(notificationsJob, ttl).Return("token", true, nil)
+				mockRuns.EXPECT().LastRun(notificationsJob).Return(day(9), nil)
+				mockRuns.EXPECT().SetLastRun(notificationsJob, day(10)).Return(nil)
+				mockLocker.EXPECT().Unlock(notificationsJob, "token").Return(nil)
+			},
+			wantDays: []time.Time{day(10)},
+			wantDone: true,
+		},
+		{
+			name: "Блокировку держит другая реплика",
+			setupMocks: func() {
+				mockLocker.EXPECT().TryLock(notificationsJob, ttl).Return("", false, nil)
+			},
+		},
+		{
+			name: "Redis недоступен",
+			setupMocks: func() {
+				mockLocker.EXPECT().TryLock(notificationsJob, ttl).Return("", false, fmt.Errorf("connection refused"))
+			},
+		},
+		{
+			name: "Потерянная блокировка останавливает догон",
+			setupMocks: func() {
+				mockLocker.EXPECT().TryLock(notificationsJob, ttl).Return("token", true, nil)
+				mockRuns.EXPECT().LastRun(notificationsJob).Return(day(8), nil)
+				mockLocker.EXPECT().Refresh(notificationsJob, "token", ttl).Return(lock.ErrNotHeld)
+				mockRuns.EXPECT().SetLastRun(notificationsJob, day(9)).Return(nil)
+			},
+			jobTime:  20 * time.Millisecond,
+			wantDays: []time.Time{day(9)},
+		},
+		{
+			name: "Блокировка продлевается, пока работа идёт",
+			setupMocks: func() {
+				mockLocker.EXPECT().TryLock(notificationsJob, ttl).Return("token", true, nil)
+				mockRuns.EXPECT().LastRun(notificationsJob).Return(day(9), nil)
+				mockLocker.EXPECT().Refresh(notificationsJob, "token", ttl).Return(nil).MinTimes(1)
+				mockRuns.EXPECT().SetLastRun(notificationsJob, day(10)).Return(nil)
+				mockLocker.EXPECT().Unlock(notificationsJob, "token").Return(nil)
+			},
+			jobTime:  20 * time.Millisecond,
+			wantDays: []time.Time{day(10)},
+			wantDone: true,
+		},
+		{
+			name: "Неудачный день повторяется при следующем запуске",
+			setupMocks: func() {
+				mockLocker.EXPECT().TryLock(notificationsJob, ttl).Return("token", true, nil)
+				mockRuns.EXPECT().LastRun(notificationsJob).Return(day(9), nil)
+				mockLocker.EXPECT().Unlock(notificationsJob, "token").Return(nil)
+			},
+			jobErr:   fmt.Errorf("database error"),
+			wantDays: []time.Time{day(10)},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			var days []time.Time
+			s := &scheduler{
+				name:       notificationsJob,
+				sendAt:     9 * time.Hour,
+				loc:        time.UTC,
+				maxCatchUp: 2,
+				runs:       mockRuns,
+				job: func(day time.Time) error {
+					days = append(days, day)
+					time.Sleep(tc.jobTime)
+					return tc.jobErr
+				},
+				locker:  mockLocker,
+				lockTTL: ttl,
+			}
+
+			assert.Equal(t, tc.wantDone, s.runLocked(context.Background()))
+			assert.Equal(t, tc.wantDays, days)
+		})
+	}
+}
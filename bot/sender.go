@@ -0,0 +1,11 @@
+package bot
+
+import (
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+)
+
+// Sender отправляет сообщения в телеграм. Его реализуют *tgbotapi.BotAPI и rateLimitedSender,
+// через который бот шлёт сообщения с учётом лимитов телеграма.
+type Sender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+}
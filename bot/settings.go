@@ -0,0 +1,193 @@
+package bot
+
+import (
+	"errors"
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"rutubeTest/pkg/user"
+	"strconv"
+	"strings"
+)
+
+// muteDays - на сколько дней кнопка в /settings отключает напоминания.
+const muteDays = 7
+
+// Данные кнопок /settings: "settings:digest", "settings:mute" и "settings:unmute".
+const (
+	callbackSettings = "settings"
+	settingsDigest   = "digest"
+	settingsMute     = "mute"
+	settingsUnmute   = "unmute"
+)
+
+// errBadSettingsArgs - аргументы /settings не подходят ни под одну настройку.
+var errBadSettingsArgs = errors.New("bad settings arguments")
+
+// settingsHandler показывает настройки напоминаний автора сообщения, а с аргументами - меняет одну из них:
+// /settings hour <час|default>, /settings quiet <с> <до>|off, /settings mute <YYYY-MM-DD>|off, /settings digest on|off,
+// /settings summary weekly|monthly|off, /settings greet on|off.
+func settingsHandler(update tgbotapi.Update, args []string, me *user.User, userRepo user.UserRepo) []tgbotapi.MessageConfig {
+	if me == nil {
+		return notLinked(update)
+	}
+	lang := userLanguage(update.Message.From, me)
+
+	settings, err := userRepo.GetSettings(me.ID)
+	if err != nil {
+		return reply(update, errorText(lang, err))
+	}
+
+	if len(args) > 0 {
+		if err = applySettingsArgs(settings, args); err != nil {
+			return reply(update, errorText(lang, err))
+		}
+		if err = userRepo.SaveSettings(me.ID, *settings); err != nil {
+			return reply(update, errorText(lang, err))
+		}
+	}
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, settingsText(lang, settings))
+	msg.ReplyMarkup = settingsKeyboard(lang, settings)
+	return []tgbotapi.MessageConfig{msg}
+}
+
+// applySettingsArgs меняет в settings настройку, заданную аргументами /settings.
+func applySettingsArgs(settings *user.Settings, args []string) error {
+	switch {
+	case len(args) == 2 && args[0] == "hour":
+		if args[1] == "default" {
+			settings.NotifyHour = user.DefaultHour
+			return nil
+		}
+		return parseHour(args[1], &settings.NotifyHour)
+	case len(args) == 2 && args[0] == "quiet" && args[1] == "off":
+		settings.QuietFrom, settings.QuietTo = 0, 0
+		return nil
+	case len(args) == 3 && args[0] == "quiet":
+		if err := parseHour(args[1], &settings.QuietFrom); err != nil {
+			return err
+		}
+		return parseHour(args[2], &settings.QuietTo)
+	case len(args) == 2 && args[0] == "mute":
+		if args[1] == "off" {
+			settings.MutedUntil = ""
+		} else {
+			settings.MutedUntil = args[1]
+		}
+		return nil
+	case len(args) == 2 && args[0] == "digest" && (args[1] == "on" || args[1] == "off"):
+		settings.Digest = args[1] == "on"
+		return nil
+	case len(args) == 2 && args[0] == "greet" && (args[1] == "on" || args[1] == "off"):
+		settings.Greet = args[1] == "on"
+		return nil
+	case len(args) == 2 && args[0] == "summary":
+		if args[1] == "off" {
+			settings.Summary = user.SummaryNone
+		} else {
+			settings.Summary = args[1]
+		}
+		return nil
+	default:
+		return errBadSettingsArgs
+	}
+}
+
+// parseHour разбирает час; диапазон проверяет SaveSettings.
+func parseHour(s string, hour *int) error {
+	h, err := strconv.Atoi(s)
+	if err != nil {
+		return user.ErrBadSettings
+	}
+	*hour = h
+	return nil
+}
+
+func settingsText(lang string, s *user.Settings) string {
+	lines := []string{messages.T(lang, msgSettings)}
+
+	if s.NotifyHour == user.DefaultHour {
+		lines = append(lines, messages.T(lang, msgHourDefault))
+	} else {
+		lines = append(lines, messages.T(lang, msgHour, s.NotifyHour))
+	}
+
+	if s.QuietFrom == s.QuietTo {
+		lines = append(lines, messages.T(lang, msgNoQuiet))
+	} else {
+		lines = append(lines, messages.T(lang, msgQuiet, s.QuietFrom, s.QuietTo))
+	}
+
+	if s.Muted(now()) {
+		lines = append(lines, messages.T(lang, msgMuted, s.MutedUntil))
+	} else {
+		lines = append(lines, messages.T(lang, msgEnabled))
+	}
+
+	switch {
+	case s.Summary == user.SummaryWeekly:
+		lines = append(lines, messages.T(lang, msgFormatWeekly))
+	case s.Summary == user.SummaryMonthly:
+		lines = append(lines, messages.T(lang, msgFormatMonthly))
+	case s.Digest:
+		lines = append(lines, messages.T(lang, msgFormatDigest))
+	default:
+		lines = append(lines, messages.T(lang, msgFormatSingle))
+	}
+
+	if s.Greet {
+		lines = append(lines, messages.T(lang, msgGreetOn))
+	} else {
+		lines = append(lines, messages.T(lang, msgGreetOff))
+	}
+
+	lines = append(lines, "", messages.T(lang, msgSettingsChange))
+	return strings.Join(lines, "\n")
+}
+
+func settingsKeyboard(lang string, s *user.Settings) tgbotapi.InlineKeyboardMarkup {
+	digest := tgbotapi.NewInlineKeyboardButtonData(messages.T(lang, msgDigestOn), callbackSettings+":"+settingsDigest)
+	if s.Digest {
+		digest = tgbotapi.NewInlineKeyboardButtonData(messages.T(lang, msgDigestOff), callbackSettings+":"+settingsDigest)
+	}
+
+	mute := tgbotapi.NewInlineKeyboardButtonData(messages.T(lang, msgMute, muteDays), callbackSettings+":"+settingsMute)
+	if s.Muted(now()) {
+		mute = tgbotapi.NewInlineKeyboardButtonData(messages.T(lang, msgUnmute), callbackSettings+":"+settingsUnmute)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(digest), tgbotapi.NewInlineKeyboardRow(mute))
+}
+
+// settingsCallback обрабатывает кнопки /settings: меняет настройку и перерисовывает сообщение с настройками.
+func settingsCallback(cb *tgbotapi.CallbackQuery, action string, me *user.User, userRepo user.UserRepo) []tgbotapi.Chattable {
+	lang := userLanguage(cb.From, me)
+	if me == nil {
+		return []tgbotapi.Chattable{tgbotapi.NewCallback(cb.ID, messages.T(lang, msgNotLinked))}
+	}
+
+	settings, err := userRepo.GetSettings(me.ID)
+	if err != nil {
+		return []tgbotapi.Chattable{tgbotapi.NewCallback(cb.ID, errorText(lang, err))}
+	}
+
+	switch action {
+	case settingsDigest:
+		settings.Digest = !settings.Digest
+	case settingsMute:
+		settings.MutedUntil = now().AddDate(0, 0, muteDays).Format(user.BirthdayLayout)
+	case settingsUnmute:
+		settings.MutedUntil = ""
+	default:
+		return []tgbotapi.Chattable{tgbotapi.NewCallback(cb.ID, "")}
+	}
+
+	if err = userRepo.SaveSettings(me.ID, *settings); err != nil {
+		return []tgbotapi.Chattable{tgbotapi.NewCallback(cb.ID, errorText(lang, err))}
+	}
+
+	result := []tgbotapi.Chattable{tgbotapi.NewCallback(cb.ID, messages.T(lang, msgSettingsSaved))}
+	if cb.Message != nil {
+		result = append(result, tgbotapi.NewEditMessageTextAndMarkup(cb.Message.Chat.ID, cb.Message.MessageID, settingsText(lang, settings), settingsKeyboard(lang, settings)))
+	}
+	return result
+}
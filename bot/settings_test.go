@@ -0,0 +1,210 @@
+package bot
+
+import (
+	"github.com/golang/mock/gomock"
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"rutubeTest/pkg/user"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSettingsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+
+	now = func() time.Time { return time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+	me := &user.User{ID: 1}
+	defaults := func() *user.Settings {
+		s := user.DefaultSettings()
+		return &s
+	}
+
+	tests := []struct {
+		name       string
+		text       string
+		setupMocks func()
+		wantLines  []string
+	}{
+		{
+			name: "Настройки по умолчанию",
+			text: "/settings",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().GetSettings(int64(1)).Return(defaults(), nil)
+			},
+			wantLines: []string{"Время: как у сервиса", "Тихие часы: нет", "Напоминания: включены", "Формат: отдельное сообщение о каждом дне рождения"},
+		},
+		{
+			name: "Час рассылки",
+			text: "/settings hour 8",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().GetSettings(int64(1)).Return(defaults(), nil)
+				mockRepo.EXPECT().SaveSettings(int64(1), user.Settings{NotifyHour: 8}).Return(nil)
+			},
+			wantLines: []string{"Время: 08:00"},
+		},
+		{
+			name: "Тихие часы",
+			text: "/settings quiet 22 7",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().GetSettings(int64(1)).Return(defaults(), nil)
+				mockRepo.EXPECT().SaveSettings(int64(1), user.Settings{NotifyHour: user.DefaultHour, QuietFrom: 22, QuietTo: 7}).Return(nil)
+			},
+			wantLines: []string{"Тихие часы: с 22:00 до 07:00"},
+		},
+		{
+			name: "Отключение напоминаний",
+			text: "/settings mute 2024-06-20",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().GetSettings(int64(1)).Return(defaults(), nil)
+				mockRepo.EXPECT().SaveSettings(int64(1), user.Settings{NotifyHour: user.DefaultHour, MutedUntil: "2024-06-20"}).Return(nil)
+			},
+			wantLines: []string{"Напоминания: отключены по 2024-06-20"},
+		},
+		{
+			name: "Дайджест",
+			text: "/settings digest on",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().GetSettings(int64(1)).Return(defaults(), nil)
+				mockRepo.EXPECT().SaveSettings(int64(1), user.Settings{NotifyHour: user.DefaultHour, Digest: true}).Return(nil)
+			},
+			wantLines: []string{"Формат: одно сообщение в день"},
+		},
+		{
+			name: "Недельная сводка",
+			text: "/settings summary weekly",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().GetSettings(int64(1)).Return(defaults(), nil)
+				mockRepo.EXPECT().SaveSettings(int64(1), user.Settings{NotifyHour: user.DefaultHour, Summary: user.SummaryWeekly}).Return(nil)
+			},
+			wantLines: []string{"Формат: сводка по понедельникам о днях рождения на неделе"},
+		},
+		{
+			name: "Поздравление в день рождения",
+			text: "/settings greet on",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().GetSettings(int64(1)).Return(defaults(), nil)
+				mockRepo.EXPECT().SaveSettings(int64(1), user.Settings{NotifyHour: user.DefaultHour, Greet: true}).Return(nil)
+			},
+			wantLines: []string{"Поздравление в мой день рождения: включено"},
+		},
+		{
+			name: "Неверный час",
+			text: "/settings hour 25",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().GetSettings(int64(1)).Return(defaults(), nil)
+				mockRepo.EXPECT().SaveSettings(int64(1), user.Settings{NotifyHour: 25}).Return(user.ErrBadSettings)
+			},
+			wantLines: []string{"Часы - числа от 0 до 23, дата - в формате ГГГГ-ММ-ДД, сводка - weekly или monthly."},
+		},
+		{
+			name: "Неизвестная настройка",
+			text: "/settings color red",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().GetSettings(int64(1)).Return(defaults(), nil)
+			},
+			wantLines: []string{"Использование: /settings hour <час|default>"},
+		},
+		{
+			name: "Телеграм не привязан",
+			text: "/settings",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+			},
+			wantLines: []string{"Ваш телеграм не найден среди зарегистрированных пользователей."},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			messages := newRouter(testBotName, user.LeapDayMar1).handle(newUpdate(tc.text), mockRepo)
+
+			assert.Len(t, messages, 1)
+			for _, line := range tc.wantLines {
+				assert.True(t, strings.Contains(messages[0].Text, line), messages[0].Text)
+			}
+		})
+	}
+}
+
+func TestSettingsCallback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+
+	now = func() time.Time { return time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+	newCallback := func(data string) tgbotapi.Update {
+		return tgbotapi.Update{
+			CallbackQuery: &tgbotapi.CallbackQuery{
+				ID:      "cb",
+				From:    &tgbotapi.User{ID: 100, UserName: "tester"},
+				Message: &tgbotapi.Message{MessageID: 7, Chat: &tgbotapi.Chat{ID: testChatID}},
+				Data:    data,
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		data       string
+		settings   user.Settings
+		saved      user.Settings
+		wantAnswer string
+	}{
+		{
+			name:       "Включить дайджест",
+			data:       "settings:digest",
+			settings:   user.DefaultSettings(),
+			saved:      user.Settings{NotifyHour: user.DefaultHour, Digest: true},
+			wantAnswer: "Настройки сохранены",
+		},
+		{
+			name:       "Отключить напоминания на неделю",
+			data:       "settings:mute",
+			settings:   user.DefaultSettings(),
+			saved:      user.Settings{NotifyHour: user.DefaultHour, MutedUntil: "2024-06-17"},
+			wantAnswer: "Настройки сохранены",
+		},
+		{
+			name:       "Включить напоминания",
+			data:       "settings:unmute",
+			settings:   user.Settings{NotifyHour: 8, MutedUntil: "2024-06-17"},
+			saved:      user.Settings{NotifyHour: 8},
+			wantAnswer: "Настройки сохранены",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			settings := tc.settings
+			mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+			mockRepo.EXPECT().GetSettings(int64(1)).Return(&settings, nil)
+			mockRepo.EXPECT().SaveSettings(int64(1), tc.saved).Return(nil)
+
+			result := callbackHandler(newCallback(tc.data), mockRepo)
+
+			assert.Len(t, result, 2)
+			assert.Equal(t, tgbotapi.NewCallback("cb", tc.wantAnswer), result[0])
+			edit, ok := result[1].(tgbotapi.EditMessageTextConfig)
+			assert.True(t, ok)
+			assert.Equal(t, 7, edit.MessageID)
+		})
+	}
+}
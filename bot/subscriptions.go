@@ -0,0 +1,139 @@
+package bot
+
+import (
+	"errors"
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"rutubeTest/pkg/user"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// now подменяется в тестах.
+var now = time.Now
+
+func (r *router) mySubscriptionsHandler(update tgbotapi.Update, _ []string, me *user.User, userRepo user.UserRepo) []tgbotapi.MessageConfig {
+	if me == nil {
+		return notLinked(update)
+	}
+	lang := userLanguage(update.Message.From, me)
+
+	users, err := userRepo.GetSubscriptions(me.ID)
+	if errors.Is(err, user.ErrNoUser) {
+		return reply(update, messages.T(lang, msgNoSubscriptions))
+	}
+	if err != nil {
+		return reply(update, errorText(lang, err))
+	}
+
+	return reply(update, messages.T(lang, msgSubscriptions, birthdayList(lang, users, now(), r.leapDay)))
+}
+
+func (r *router) mySubscribersHandler(update tgbotapi.Update, _ []string, me *user.User, userRepo user.UserRepo) []tgbotapi.MessageConfig {
+	if me == nil {
+		return notLinked(update)
+	}
+	lang := userLanguage(update.Message.From, me)
+
+	users, err := userRepo.GetSubscribedUsers(me.ID)
+	if errors.Is(err, user.ErrNoUser) {
+		return reply(update, messages.T(lang, msgNoSubscribers))
+	}
+	if err != nil {
+		return reply(update, errorText(lang, err))
+	}
+
+	return reply(update, messages.T(lang, msgSubscribers, birthdayList(lang, users, now(), r.leapDay)))
+}
+
+// upcomingDefaultDays - за сколько дней вперёд /upcoming показывает дни рождения, если число не указано.
+const upcomingDefaultDays = 7
+
+func (r *router) upcomingHandler(update tgbotapi.Update, args []string, me *user.User, userRepo user.UserRepo) []tgbotapi.MessageConfig {
+	lang := userLanguage(update.Message.From, me)
+	days := upcomingDefaultDays
+	onlyMine := false
+	for _, arg := range args {
+		if strings.EqualFold(arg, "my") || strings.EqualFold(arg, "мои") {
+			onlyMine = true
+			continue
+		}
+		d, err := strconv.Atoi(arg)
+		if err != nil || d < 0 || d > 366 {
+			return reply(update, messages.T(lang, msgBadDays))
+		}
+		days = d
+	}
+
+	var subscriberID int64
+	if onlyMine {
+		if me == nil {
+			return notLinked(update)
+		}
+		subscriberID = me.ID
+	}
+
+	today := now()
+	users, err := userRepo.GetUpcomingBirthdays(today, days, subscriberID)
+	if errors.Is(err, user.ErrNoUser) {
+		return reply(update, messages.T(lang, msgNoUpcoming, days))
+	}
+	if err != nil {
+		return reply(update, errorText(lang, err))
+	}
+
+	return reply(update, messages.T(lang, msgUpcoming, days, birthdayList(lang, users, today, r.leapDay)))
+}
+
+// birthdayList выводит пользователей по одному в строке, начиная с тех, у кого день рождения ближе.
+func birthdayList(lang string, users []user.User, today time.Time, leapDay user.LeapDayPolicy) string {
+	type entry struct {
+		u    user.User
+		days int
+		next time.Time
+	}
+
+	entries := make([]entry, 0, len(users))
+	for _, u := range users {
+		e := entry{u: u, days: -1}
+		if next, err := user.NextBirthday(u.Birthday, today, leapDay); err == nil {
+			e.next = next
+			e.days = user.DaysUntil(next, today)
+		}
+		entries = append(entries, e)
+	}
+
+	// Пользователи с некорректной датой рождения уходят в конец списка.
+	sort.SliceStable(entries, func(i, j int) bool {
+		if (entries[i].days < 0) != (entries[j].days < 0) {
+			return entries[j].days < 0
+		}
+		return entries[i].days < entries[j].days
+	})
+
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		line := fullName(e.u) + " " + e.u.Telegram
+		if e.days >= 0 {
+			line += " - " + e.next.Format("02.01") + ", " + daysText(lang, e.days)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func fullName(u user.User) string {
+	return strings.Join(strings.Fields(u.FirstName+" "+u.MiddleName+" "+u.LastName), " ")
+}
+
+func daysText(lang string, days int) string {
+	switch days {
+	case 0:
+		return messages.T(lang, msgToday)
+	case 1:
+		return messages.T(lang, msgTomorrow)
+	default:
+		return messages.T(lang, msgInDays, days)
+	}
+}
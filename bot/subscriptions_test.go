@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"rutubeTest/pkg/user"
+	"testing"
+	"time"
+)
+
+func TestMySubscriptionsHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+
+	now = func() time.Time { return time.Date(2024, 12, 30, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	me := &user.User{ID: 1, Telegram: "@tester"}
+	people := []user.User{
+		{ID: 2, FirstName: "John", MiddleName: "M", LastName: "Doe", Birthday: "1990-01-05", Telegram: "@john"},
+		{ID: 3, FirstName: "Jane", LastName: "Smith", Birthday: "1991-12-31", Telegram: "@jane"},
+		{ID: 4, FirstName: "Ann", LastName: "Lee", Birthday: "1992-12-30", Telegram: "@ann"},
+	}
+
+	tests := []struct {
+		name       string
+		text       string
+		setupMocks func()
+		wantText   string
+	}{
+		{
+			name: "Мои подписки отсортированы по ближайшему дню рождения",
+			text: "/mysubscriptions",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().GetSubscriptions(int64(1)).Return(people, nil)
+			},
+			wantText: "Вы подписаны на:\n" +
+				"Ann Lee @ann - 30.12, сегодня\n" +
+				"Jane Smith @jane - 31.12, завтра\n" +
+				"John M Doe @john - 05.01, через 6 дн.",
+		},
+		{
+			name: "Нет подписок",
+			text: "/mysubscriptions",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().GetSubscriptions(int64(1)).Return(nil, user.ErrNoUser)
+			},
+			wantText: "Вы ни на кого не подписаны. Подписаться можно через /users.",
+		},
+		{
+			name: "Мои подписчики",
+			text: "/mysubscribers",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().GetSubscribedUsers(int64(1)).Return(people[:1], nil)
+			},
+			wantText: "На вас подписаны:\n" +
+				"John M Doe @john - 05.01, через 6 дн.",
+		},
+		{
+			name: "Нет подписчиков",
+			text: "/mysubscribers",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().GetSubscribedUsers(int64(1)).Return(nil, user.ErrNoUser)
+			},
+			wantText: "На вас пока никто не подписан.",
+		},
+		{
+			name: "Ближайшие дни рождения по умолчанию",
+			text: "/upcoming",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+				mockRepo.EXPECT().GetUpcomingBirthdays(now(), 7, int64(0)).Return([]user.User{people[2], people[1], people[0]}, nil)
+			},
+			wantText: "Дни рождения в ближайшие 7 дн.:\n" +
+				"Ann Lee @ann - 30.12, сегодня\n" +
+				"Jane Smith @jane - 31.12, завтра\n" +
+				"John M Doe @john - 05.01, через 6 дн.",
+		},
+		{
+			name: "Ближайшие дни рождения из подписок",
+			text: "/upcoming my 1",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(me, nil)
+				mockRepo.EXPECT().GetUpcomingBirthdays(now(), 1, int64(1)).Return(nil, user.ErrNoUser)
+			},
+			wantText: "В ближайшие 1 дн. дней рождения нет.",
+		},
+		{
+			name: "Некорректное число дней",
+			text: "/upcoming 1000",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+			},
+			wantText: "Число дней должно быть от 0 до 366.",
+		},
+		{
+			name: "Телеграм не привязан",
+			text: "/mysubscribers",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+			},
+			wantText: "Ваш телеграм не найден среди зарегистрированных пользователей.",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			messages := newRouter(testBotName, user.LeapDayMar1).handle(newUpdate(tc.text), mockRepo)
+
+			assert.Len(t, messages, 1)
+			assert.Equal(t, tc.wantText, messages[0].Text)
+		})
+	}
+}
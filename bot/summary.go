@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"rutubeTest/pkg/notify"
+	"rutubeTest/pkg/user"
+	"strings"
+	"time"
+)
+
+// weekdayNames - ключи названий дней недели для заголовков сводки.
+var weekdayNames = map[time.Weekday]string{
+	time.Monday:    msgMonday,
+	time.Tuesday:   msgTuesday,
+	time.Wednesday: msgWednesday,
+	time.Thursday:  msgThursday,
+	time.Friday:    msgFriday,
+	time.Saturday:  msgSaturday,
+	time.Sunday:    msgSunday,
+}
+
+// SendSummaries рассылает сводки дней рождения подписчикам из часового пояса timezone, выбравшим их:
+// недельную по понедельникам и месячную первого числа. at - время рассылки по их местному времени.
+// Сводка учитывает настройки подписчика так же, как напоминания, и записывается в журнал,
+// поэтому повторный запуск за тот же день её не дублирует. Ошибка чтения подписчиков возвращается, чтобы день повторили.
+func (nt *Notifier) SendSummaries(timezone string, at time.Time) error {
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+	current := now()
+
+	var failed error
+	for _, period := range []string{user.SummaryWeekly, user.SummaryMonthly} {
+		days, ok := summaryDays(period, day)
+		if !ok {
+			continue
+		}
+
+		subscribers, err := nt.Users.GetSummarySubscribers(period, timezone)
+		if errors.Is(err, user.ErrNoUser) {
+			continue
+		}
+		if err != nil {
+			failed = errors.Join(failed, fmt.Errorf("can't fetch %s summary subscribers: %w", period, err))
+			continue
+		}
+
+		for _, sub := range subscribers {
+			settings := user.DefaultSettings()
+			if sub.Settings != nil {
+				settings = *sub.Settings
+			}
+			if sub.TelegramID == 0 || settings.Muted(day) {
+				continue
+			}
+
+			users, err := nt.Users.GetUpcomingBirthdays(day, days-1, sub.ID)
+			if errors.Is(err, user.ErrNoUser) {
+				continue
+			}
+			if err != nil {
+				fmt.Println("Error fetching birthdays:", err)
+				continue
+			}
+
+			n := &notify.Notification{
+				UserID:       sub.ID,
+				SubscriberID: sub.ID,
+				Day:          day,
+				Kind:         notify.KindSummary,
+				ChatID:       sub.TelegramID,
+				Text:         summaryText(userLanguage(nil, &sub), users, day, days, nt.LeapDay),
+			}
+			if deliverAt := settings.DeliverAt(day, at, current); deliverAt.After(current) {
+				n.Status = notify.StatusScheduled
+				n.NextAttempt = deliverAt
+			}
+			nt.deliverNotification(n)
+		}
+	}
+	return failed
+}
+
+// summaryDays возвращает, за сколько дней начиная с day нужна сводка period. ok будет false,
+// если в day сводка не отправляется.
+func summaryDays(period string, day time.Time) (days int, ok bool) {
+	switch {
+	case period == user.SummaryWeekly && day.Weekday() == time.Monday:
+		return 7, true
+	case period == user.SummaryMonthly && day.Day() == 1:
+		return day.AddDate(0, 1, -1).Day(), true
+	default:
+		return 0, false
+	}
+}
+
+// summaryText формирует сводку дней рождения users за days дней начиная с day, сгруппированную по датам,
+// на языке lang. leapDay - правило для родившихся 29 февраля.
+func summaryText(lang string, users []user.User, day time.Time, days int, leapDay user.LeapDayPolicy) string {
+	var sb strings.Builder
+	sb.WriteString(messages.T(lang, msgSummary, day.Format("02.01"), day.AddDate(0, 0, days-1).Format("02.01")))
+
+	var last time.Time
+	for _, u := range users {
+		next, err := user.NextBirthday(u.Birthday, day, leapDay)
+		if err != nil {
+			continue
+		}
+		if !next.Equal(last) {
+			fmt.Fprintf(&sb, "\n\n%s, %s", next.Format("02.01"), messages.T(lang, weekdayNames[next.Weekday()]))
+			last = next
+		}
+		sb.WriteString("\n" + fullName(u))
+		if u.Telegram != "" {
+			sb.WriteString(" " + u.Telegram)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,106 @@
+package bot
+
+import (
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"rutubeTest/pkg/notify"
+	"rutubeTest/pkg/queue"
+	"rutubeTest/pkg/user"
+	"testing"
+	"time"
+)
+
+func TestSendSummaries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+	mockNotify := notify.NewMockNotifyRepo(ctrl)
+	mockQueue := queue.NewMockQueue(ctrl)
+
+	monday := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	sendAt := 9 * time.Hour
+	weekly := user.User{ID: 3, TelegramID: 300, Settings: &user.Settings{NotifyHour: user.DefaultHour, Summary: user.SummaryWeekly}}
+	birthdays := []user.User{{ID: 1, FirstName: "John", LastName: "Doe", Birthday: "1990-06-11"}}
+	const weekText = "Дни рождения с 10.06 по 16.06:\n\n11.06, вторник\nJohn Doe"
+
+	tests := []struct {
+		name       string
+		day        time.Time
+		setupMocks func()
+	}{
+		{
+			name: "Недельная сводка в понедельник",
+			day:  monday,
+			setupMocks: func() {
+				muted := user.User{ID: 4, TelegramID: 400, Settings: &user.Settings{NotifyHour: user.DefaultHour, MutedUntil: "2024-06-30", Summary: user.SummaryWeekly}}
+				mockRepo.EXPECT().GetSummarySubscribers(user.SummaryWeekly, "").Return([]user.User{weekly, muted}, nil)
+				mockRepo.EXPECT().GetUpcomingBirthdays(monday, 6, int64(3)).Return(birthdays, nil)
+				n := &notify.Notification{UserID: 3, SubscriberID: 3, Day: monday, Kind: notify.KindSummary, ChatID: 300, Text: weekText}
+				mockNotify.EXPECT().CreateNotification(n).Return(int64(10), nil)
+				mockQueue.EXPECT().Enqueue(gomock.Any()).Return("1", nil)
+			},
+		},
+		{
+			name: "Без дней рождения сводка не приходит",
+			day:  monday,
+			setupMocks: func() {
+				mockRepo.EXPECT().GetSummarySubscribers(user.SummaryWeekly, "").Return([]user.User{weekly}, nil)
+				mockRepo.EXPECT().GetUpcomingBirthdays(monday, 6, int64(3)).Return(nil, user.ErrNoUser)
+			},
+		},
+		{
+			name: "Месячная сводка первого числа",
+			day:  time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			setupMocks: func() {
+				first := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+				monthly := user.User{ID: 5, TelegramID: 500, Settings: &user.Settings{NotifyHour: user.DefaultHour, Summary: user.SummaryMonthly}}
+				mockRepo.EXPECT().GetSummarySubscribers(user.SummaryMonthly, "").Return([]user.User{monthly}, nil)
+				mockRepo.EXPECT().GetUpcomingBirthdays(first, 29, int64(5)).Return(birthdays, nil)
+				n := &notify.Notification{UserID: 5, SubscriberID: 5, Day: first, Kind: notify.KindSummary, ChatID: 500,
+					Text: "Дни рождения с 01.06 по 30.06:\n\n11.06, вторник\nJohn Doe"}
+				mockNotify.EXPECT().CreateNotification(n).Return(int64(0), notify.ErrDuplicate)
+			},
+		},
+		{
+			name: "Ошибка базы не прерывает рассылку",
+			day:  monday,
+			setupMocks: func() {
+				mockRepo.EXPECT().GetSummarySubscribers(user.SummaryWeekly, "").Return(nil, fmt.Errorf("database error"))
+			},
+		},
+		{
+			name:       "В обычный день сводок нет",
+			day:        monday.AddDate(0, 0, 1),
+			setupMocks: func() {},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			now = func() time.Time { return tc.day.Add(sendAt) }
+			defer func() { now = time.Now }()
+			tc.setupMocks()
+
+			notifier := &Notifier{Users: mockRepo, Log: mockNotify, Queue: mockQueue, Location: time.UTC}
+			notifier.SendSummaries("", tc.day.Add(sendAt))
+		})
+	}
+}
+
+func TestSummaryText(t *testing.T) {
+	monday := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	users := []user.User{
+		{FirstName: "John", LastName: "Doe", Birthday: "1990-06-11", Telegram: "@john"},
+		{FirstName: "Jane", LastName: "Smith", Birthday: "1991-06-11"},
+		{FirstName: "Ivan", LastName: "Petrov", Birthday: "1985-06-15", Telegram: "@ivan"},
+	}
+
+	assert.Equal(t, "Дни рождения с 10.06 по 16.06:\n\n"+
+		"11.06, вторник\nJohn Doe @john\nJane Smith\n\n"+
+		"15.06, суббота\nIvan Petrov @ivan", summaryText("ru", users, monday, 7, user.LeapDayMar1))
+	assert.Equal(t, "Birthdays from 10.06 to 16.06:\n\n"+
+		"11.06, Tuesday\nJohn Doe @john\nJane Smith\n\n"+
+		"15.06, Saturday\nIvan Petrov @ivan", summaryText("en", users, monday, 7, user.LeapDayMar1))
+}
@@ -0,0 +1,27 @@
+package bot
+
+import (
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"rutubeTest/pkg/user"
+)
+
+// timezoneHandler показывает часовой пояс автора сообщения, а с аргументом - задаёт его.
+// По часовому поясу подписчика выбирается время, когда ему приходят напоминания.
+func timezoneHandler(update tgbotapi.Update, args []string, me *user.User, userRepo user.UserRepo) []tgbotapi.MessageConfig {
+	if me == nil {
+		return notLinked(update)
+	}
+	lang := userLanguage(update.Message.From, me)
+
+	if len(args) == 0 {
+		if me.TimeZone == "" {
+			return reply(update, messages.T(lang, msgNoTimeZone))
+		}
+		return reply(update, messages.T(lang, msgTimeZone, me.TimeZone))
+	}
+
+	if err := userRepo.SetTimeZone(me.ID, args[0]); err != nil {
+		return reply(update, errorText(lang, err))
+	}
+	return reply(update, messages.T(lang, msgTimeZoneChanged, args[0]))
+}
@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"rutubeTest/pkg/user"
+	"testing"
+)
+
+func TestTimezoneHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+
+	tests := []struct {
+		name       string
+		text       string
+		setupMocks func()
+		wantText   string
+	}{
+		{
+			name: "Часовой пояс не задан",
+			text: "/timezone",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+			},
+			wantText: "Часовой пояс не задан, напоминания приходят по часовому поясу сервиса. " +
+				"Задать его можно так: /timezone Europe/Moscow",
+		},
+		{
+			name: "Текущий часовой пояс",
+			text: "/timezone",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1, TimeZone: "Asia/Vladivostok"}, nil)
+			},
+			wantText: "Ваш часовой пояс: Asia/Vladivostok",
+		},
+		{
+			name: "Часовой пояс изменён",
+			text: "/timezone Asia/Vladivostok",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+				mockRepo.EXPECT().SetTimeZone(int64(1), "Asia/Vladivostok").Return(nil)
+			},
+			wantText: "Часовой пояс изменён на Asia/Vladivostok. Напоминания будут приходить по вашему местному времени.",
+		},
+		{
+			name: "Неизвестный часовой пояс",
+			text: "/timezone Mars/Olympus",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(&user.User{ID: 1}, nil)
+				mockRepo.EXPECT().SetTimeZone(int64(1), "Mars/Olympus").Return(user.ErrBadTimeZone)
+			},
+			wantText: "Неизвестный часовой пояс. Укажите его из базы IANA, например Europe/Moscow или Asia/Vladivostok.",
+		},
+		{
+			name: "Телеграм не привязан",
+			text: "/timezone UTC",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetUserByTelegram("@tester").Return(nil, user.ErrNoUser)
+			},
+			wantText: "Ваш телеграм не найден среди зарегистрированных пользователей.",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			messages := newRouter(testBotName, user.LeapDayMar1).handle(newUpdate(tc.text), mockRepo)
+
+			assert.Len(t, messages, 1)
+			assert.Equal(t, tc.wantText, messages[0].Text)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package bot
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"log"
+	"net/http"
+	"net/url"
+	"rutubeTest/configs"
+	"time"
+)
+
+// pollRetryDelay - пауза перед повторным запросом getUpdates после ошибки.
+const pollRetryDelay = 3 * time.Second
+
+// secretTokenHeader - заголовок, в котором телеграм присылает secret_token, указанный при установке вебхука.
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// webhookUpdates регистрирует вебхук в телеграме и поднимает http сервер, принимающий обновления.
+func webhookUpdates(bot *tgbotapi.BotAPI, config configs.Config) (tgbotapi.UpdatesChannel, error) {
+	if config.Bot.WebhookURL == "" {
+		return nil, fmt.Errorf("webhook url is not set")
+	}
+
+	link, err := url.JoinPath(config.Bot.WebhookURL, config.Bot.WebhookPath)
+	if err != nil {
+		log.Printf("Invalid webhook url: %s", err)
+		return nil, err
+	}
+
+	// WebhookConfig из библиотеки не умеет передавать secret_token, поэтому запрос собираем сами.
+	params := tgbotapi.Params{"url": link}
+	params.AddNonEmpty("secret_token", config.Bot.SecretToken)
+	_, err = bot.MakeRequest("setWebhook", params)
+	if err != nil {
+		log.Printf("SetWebhook failed: %s", err)
+		return nil, err
+	}
+
+	updates := make(chan tgbotapi.Update, bot.Buffer)
+
+	mux := http.NewServeMux()
+	mux.Handle(config.Bot.WebhookPath, webhookHandler(config.Bot.SecretToken, updates))
+	mux.HandleFunc("/state", func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte("all is working"))
+		if err != nil {
+			return
+		}
+	})
+
+	go func() {
+		log.Fatalln("http err:", http.ListenAndServe(config.Bot.ListenAddr, mux))
+	}()
+	fmt.Println("start listen " + config.Bot.ListenAddr)
+
+	return updates, nil
+}
+
+// webhookHandler принимает обновления от телеграма и отклоняет запросы с неверным secret_token.
+// Пустой secretToken не пропускает ни одного запроса: LoadConfig не даёт запустить вебхук без него.
+func webhookHandler(secretToken string, updates chan<- tgbotapi.Update) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		got := r.Header.Get(secretTokenHeader)
+		if secretToken == "" || subtle.ConstantTimeCompare([]byte(got), []byte(secretToken)) != 1 {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		updates <- update
+	}
+}
+
+// pollingUpdates снимает вебхук и запускает long polling через getUpdates.
+// Смещение хранится между запросами, чтобы телеграм не присылал уже обработанные обновления.
+func pollingUpdates(ctx context.Context, bot *tgbotapi.BotAPI, timeout int) (tgbotapi.UpdatesChannel, error) {
+	// Пока установлен вебхук, телеграм отвечает на getUpdates ошибкой.
+	_, err := bot.Request(tgbotapi.DeleteWebhookConfig{})
+	if err != nil {
+		log.Printf("DeleteWebhook failed: %s", err)
+		return nil, err
+	}
+
+	updates := make(chan tgbotapi.Update, bot.Buffer)
+
+	go func() {
+		defer close(updates)
+
+		u := tgbotapi.NewUpdate(0)
+		u.Timeout = timeout
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			batch, err := bot.GetUpdates(u)
+			if err != nil {
+				log.Printf("GetUpdates failed: %s, retrying in %s", err, pollRetryDelay)
+				select {
+				case <-time.After(pollRetryDelay):
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+
+			for _, update := range batch {
+				if update.UpdateID < u.Offset {
+					continue
+				}
+				u.Offset = update.UpdateID + 1
+
+				select {
+				case updates <- update:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	fmt.Println("start polling updates")
+
+	return updates, nil
+}
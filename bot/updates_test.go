@@ -0,0 +1,94 @@
+package bot
+
+import (
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	const secret = "s3cret"
+	body := `{"update_id": 7, "message": {"message_id": 1, "text": "/start"}}`
+
+	tests := []struct {
+		name       string
+		method     string
+		token      string
+		body       string
+		wantStatus int
+		wantUpdate bool
+	}{
+		{
+			name:       "Обновление принято",
+			method:     http.MethodPost,
+			token:      secret,
+			body:       body,
+			wantStatus: http.StatusOK,
+			wantUpdate: true,
+		},
+		{
+			name:       "Нет secret token",
+			method:     http.MethodPost,
+			body:       body,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Неверный secret token",
+			method:     http.MethodPost,
+			token:      "wrong",
+			body:       body,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Не POST",
+			method:     http.MethodGet,
+			token:      secret,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "Некорректный JSON",
+			method:     http.MethodPost,
+			token:      secret,
+			body:       `{"update_id":`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			updates := make(chan tgbotapi.Update, 1)
+
+			req := httptest.NewRequest(tc.method, "/webhook", strings.NewReader(tc.body))
+			if tc.token != "" {
+				req.Header.Set(secretTokenHeader, tc.token)
+			}
+			w := httptest.NewRecorder()
+
+			webhookHandler(secret, updates)(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Result().StatusCode)
+			if !tc.wantUpdate {
+				assert.Empty(t, updates)
+				return
+			}
+			update := <-updates
+			assert.Equal(t, 7, update.UpdateID)
+			assert.Equal(t, "/start", update.Message.Text)
+		})
+	}
+}
+
+func TestWebhookHandlerWithoutSecret(t *testing.T) {
+	updates := make(chan tgbotapi.Update, 1)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"update_id": 7}`))
+	w := httptest.NewRecorder()
+
+	webhookHandler("", updates)(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Result().StatusCode)
+	assert.Empty(t, updates)
+}
@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"rutubeTest/pkg/notify"
+	"rutubeTest/pkg/queue"
+	"sync"
+	"time"
+)
+
+const (
+	// queuePollInterval - пауза воркера, если очередь пуста.
+	queuePollInterval = time.Second
+	// reclaimInterval - как часто в очередь возвращаются задачи, не подтверждённые за visibility timeout.
+	reclaimInterval = 10 * time.Second
+)
+
+// RunWorkers запускает workers воркеров, отправляющих уведомления из очереди, и ждёт их завершения после отмены ctx.
+func (nt *Notifier) RunWorkers(ctx context.Context, workers int) {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nt.work(ctx)
+		}()
+	}
+
+	ticker := time.NewTicker(reclaimInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			nt.reclaim()
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+	}
+}
+
+func (nt *Notifier) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if nt.processJob() {
+			continue
+		}
+
+		select {
+		case <-time.After(queuePollInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// processJob забирает из очереди одно уведомление и отправляет его. Возвращает false, если очередь пуста
+// или недоступна. Уведомление отправляется, только если его удалось перевести из pending в sending:
+// так задача, выданная повторно после падения воркера, не приведёт к двойной отправке.
+func (nt *Notifier) processJob() bool {
+	job, err := nt.Queue.Dequeue()
+	if err == queue.ErrEmpty {
+		return false
+	}
+	if err != nil {
+		fmt.Println("Error dequeueing notification:", err)
+		return false
+	}
+
+	n := &notify.Notification{}
+	if err = json.Unmarshal(job.Payload, n); err != nil {
+		fmt.Println("Error decoding notification:", err)
+		if err = nt.Queue.Bury(job.ID); err != nil {
+			fmt.Println("Error burying job:", err)
+		}
+		return true
+	}
+
+	claimed, err := nt.Log.ClaimSending(n.ID, now())
+	if err != nil {
+		// Задачу не подтверждаем: она вернётся в очередь после visibility timeout.
+		fmt.Println("Error claiming notification:", err)
+		return true
+	}
+	if claimed {
+		nt.attemptDelivery(n)
+	}
+
+	if err = nt.Queue.Ack(job.ID); err != nil {
+		fmt.Println("Error acknowledging job:", err)
+	}
+	return true
+}
+
+func (nt *Notifier) reclaim() {
+	requeued, buried, err := nt.Queue.ReclaimExpired()
+	if err != nil {
+		fmt.Println("Error reclaiming jobs:", err)
+		return
+	}
+	if requeued > 0 || buried > 0 {
+		fmt.Printf("Reclaimed jobs: %d requeued, %d dead-lettered\n", requeued, buried)
+	}
+}
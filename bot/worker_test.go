@@ -0,0 +1,135 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"rutubeTest/pkg/notify"
+	"rutubeTest/pkg/queue"
+	"testing"
+	"time"
+)
+
+func TestProcessJob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockNotify := notify.NewMockNotifyRepo(ctrl)
+	mockQueue := queue.NewMockQueue(ctrl)
+	mockSender := NewMockSender(ctrl)
+
+	now = func() time.Time { return time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+	today := now()
+
+	n := notify.Notification{ID: 10, UserID: 1, SubscriberID: 2, ChatID: 200, Text: "Сегодня день рождения у John!", Attempts: 2}
+	payload, err := json.Marshal(&n)
+	assert.NoError(t, err)
+	job := &queue.Job{ID: "7", Payload: payload, Attempts: 1}
+	blocked := &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}
+
+	tests := []struct {
+		name       string
+		setupMocks func()
+		wantBusy   bool
+	}{
+		{
+			name: "Уведомление отправлено",
+			setupMocks: func() {
+				mockQueue.EXPECT().Dequeue().Return(job, nil)
+				mockNotify.EXPECT().ClaimSending(int64(10), today).Return(true, nil)
+				mockSender.EXPECT().Send(tgbotapi.NewMessage(200, n.Text)).Return(tgbotapi.Message{MessageID: 5}, nil)
+				mockNotify.EXPECT().MarkSent(int64(10), 5).Return(nil)
+				mockQueue.EXPECT().Ack("7").Return(nil)
+			},
+			wantBusy: true,
+		},
+		{
+			name: "Телеграм просит подождать",
+			setupMocks: func() {
+				mockQueue.EXPECT().Dequeue().Return(job, nil)
+				mockNotify.EXPECT().ClaimSending(int64(10), today).Return(true, nil)
+				mockSender.EXPECT().Send(gomock.Any()).Return(tgbotapi.Message{}, &tgbotapi.Error{
+					Code: 429, Message: "Too Many Requests", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 600},
+				})
+				mockNotify.EXPECT().MarkRetry(int64(10), today.Add(10*time.Minute), "Too Many Requests").Return(nil)
+				mockQueue.EXPECT().Ack("7").Return(nil)
+			},
+			wantBusy: true,
+		},
+		{
+			name: "Пользователь заблокировал бота",
+			setupMocks: func() {
+				mockQueue.EXPECT().Dequeue().Return(job, nil)
+				mockNotify.EXPECT().ClaimSending(int64(10), today).Return(true, nil)
+				mockSender.EXPECT().Send(gomock.Any()).Return(tgbotapi.Message{}, blocked)
+				mockNotify.EXPECT().MarkFailed(int64(10), blocked.Message).Return(nil)
+				mockQueue.EXPECT().Ack("7").Return(nil)
+			},
+			wantBusy: true,
+		},
+		{
+			name: "Попытки закончились",
+			setupMocks: func() {
+				last := n
+				last.Attempts = maxSendAttempts - 1
+				data, err := json.Marshal(&last)
+				assert.NoError(t, err)
+				mockQueue.EXPECT().Dequeue().Return(&queue.Job{ID: "7", Payload: data}, nil)
+				mockNotify.EXPECT().ClaimSending(int64(10), today).Return(true, nil)
+				mockSender.EXPECT().Send(gomock.Any()).Return(tgbotapi.Message{}, fmt.Errorf("timeout"))
+				mockNotify.EXPECT().MarkFailed(int64(10), "timeout").Return(nil)
+				mockQueue.EXPECT().Ack("7").Return(nil)
+			},
+			wantBusy: true,
+		},
+		{
+			name: "Повторно выданная задача не отправляется дважды",
+			setupMocks: func() {
+				mockQueue.EXPECT().Dequeue().Return(job, nil)
+				mockNotify.EXPECT().ClaimSending(int64(10), today).Return(false, nil)
+				mockQueue.EXPECT().Ack("7").Return(nil)
+			},
+			wantBusy: true,
+		},
+		{
+			name: "Ошибка журнала оставляет задачу в очереди",
+			setupMocks: func() {
+				mockQueue.EXPECT().Dequeue().Return(job, nil)
+				mockNotify.EXPECT().ClaimSending(int64(10), today).Return(false, fmt.Errorf("database error"))
+			},
+			wantBusy: true,
+		},
+		{
+			name: "Испорченная задача уходит в dead letter",
+			setupMocks: func() {
+				mockQueue.EXPECT().Dequeue().Return(&queue.Job{ID: "8", Payload: []byte("{")}, nil)
+				mockQueue.EXPECT().Bury("8").Return(nil)
+			},
+			wantBusy: true,
+		},
+		{
+			name: "Очередь пуста",
+			setupMocks: func() {
+				mockQueue.EXPECT().Dequeue().Return(nil, queue.ErrEmpty)
+			},
+		},
+		{
+			name: "Очередь недоступна",
+			setupMocks: func() {
+				mockQueue.EXPECT().Dequeue().Return(nil, fmt.Errorf("connection refused"))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			notifier := &Notifier{Log: mockNotify, Queue: mockQueue, Sender: mockSender}
+			assert.Equal(t, tc.wantBusy, notifier.processJob())
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log"
+	"rutubeTest/configs"
+	"rutubeTest/pkg/lock"
+	"rutubeTest/pkg/notify"
+	"rutubeTest/pkg/user"
+	"time"
+)
+
+// zonesInterval - как часто проверяется, не появились ли у пользователей новые часовые пояса.
+const zonesInterval = 10 * time.Minute
+
+// zoneSchedulers запускает отдельный ежедневный планировщик рассылки для каждого часового пояса пользователей,
+// чтобы напоминания приходили в NOTIFY_TIME по местному времени подписчика.
+type zoneSchedulers struct {
+	users   user.UserRepo
+	start   func(ctx context.Context, timezone string) error
+	started map[string]bool
+}
+
+func (z *zoneSchedulers) run(ctx context.Context) {
+	ticker := time.NewTicker(zonesInterval)
+	defer ticker.Stop()
+	for {
+		z.startNew(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// startNew запускает планировщики для часовых поясов, для которых они ещё не запущены.
+func (z *zoneSchedulers) startNew(ctx context.Context) {
+	zones, err := z.users.GetTimeZones()
+	if err != nil {
+		log.Println("can't get time zones:", err)
+	}
+	// Часовой пояс сервиса нужен, даже если база недоступна или в ней ещё нет пользователей.
+	zones = append([]string{""}, zones...)
+
+	for _, zone := range zones {
+		if z.started[zone] {
+			continue
+		}
+		if err = z.start(ctx, zone); err != nil {
+			log.Printf("can't schedule notifications for time zone %q: %v", zone, err)
+			continue
+		}
+		z.started[zone] = true
+	}
+}
+
+// notificationsScheduler создаёт планировщик рассылки подписчикам из часового пояса timezone.
+// Для часового пояса сервиса (пустой timezone) задача называется notificationsJob, для остальных - с суффиксом зоны,
+// поэтому у каждого часового пояса свой последний запуск и своя блокировка.
+func notificationsScheduler(config configs.Config, notifier *Notifier, runs notify.NotifyRepo, locker lock.Locker, timezone string) (*scheduler, error) {
+	name := notificationsJob
+	loc := config.Notify.Location
+	if timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return nil, err
+		}
+		name += ":" + timezone
+	}
+
+	// Рассылка планируется в начале дня: уведомления уходят в NOTIFY_TIME или в час, выбранный подписчиком.
+	s := &scheduler{
+		name:       name,
+		sendAt:     0,
+		loc:        loc,
+		maxCatchUp: config.Notify.MaxCatchUpDays,
+		runs:       runs,
+		locker:     locker,
+		lockTTL:    config.Notify.LockTTL,
+	}
+	s.job = func(day time.Time) error {
+		at := time.Date(day.Year(), day.Month(), day.Day(), 0, int(config.Notify.SendAt/time.Minute), 0, 0, loc)
+		// Рассылки независимы: ошибка одной не отменяет остальные, а журнал не даст повторить уже отправленное.
+		return errors.Join(
+			notifier.CheckAndSendNotifications(timezone, at),
+			notifier.SendSummaries(timezone, at),
+			notifier.SendGreetings(timezone, at),
+		)
+	}
+	return s, nil
+}
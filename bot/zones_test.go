@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"rutubeTest/configs"
+	"rutubeTest/pkg/user"
+	"testing"
+	"time"
+)
+
+func TestZoneSchedulers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+
+	var started []string
+	z := &zoneSchedulers{
+		users:   mockRepo,
+		started: make(map[string]bool),
+		start: func(_ context.Context, timezone string) error {
+			if timezone == "Mars/Olympus" {
+				return fmt.Errorf("unknown time zone")
+			}
+			started = append(started, timezone)
+			return nil
+		},
+	}
+
+	// Без базы запускается хотя бы часовой пояс сервиса.
+	mockRepo.EXPECT().GetTimeZones().Return(nil, fmt.Errorf("database error"))
+	z.startNew(context.Background())
+	assert.Equal(t, []string{""}, started)
+
+	// Уже запущенные пояса не дублируются, а неизвестные пропускаются.
+	mockRepo.EXPECT().GetTimeZones().Return([]string{"", "Asia/Vladivostok", "Mars/Olympus"}, nil)
+	z.startNew(context.Background())
+	assert.Equal(t, []string{"", "Asia/Vladivostok"}, started)
+
+	mockRepo.EXPECT().GetTimeZones().Return([]string{"", "Asia/Vladivostok", "Europe/Moscow"}, nil)
+	z.startNew(context.Background())
+	assert.Equal(t, []string{"", "Asia/Vladivostok", "Europe/Moscow"}, started)
+}
+
+func TestNotificationsScheduler(t *testing.T) {
+	var config configs.Config
+	config.Notify.SendAt = 9 * time.Hour
+	config.Notify.Location = time.UTC
+
+	s, err := notificationsScheduler(config, &Notifier{}, nil, nil, "")
+	assert.NoError(t, err)
+	assert.Equal(t, notificationsJob, s.name)
+	assert.Equal(t, time.UTC, s.loc)
+
+	s, err = notificationsScheduler(config, &Notifier{}, nil, nil, "Asia/Vladivostok")
+	assert.NoError(t, err)
+	assert.Equal(t, "notifications:Asia/Vladivostok", s.name)
+	assert.Equal(t, "Asia/Vladivostok", s.loc.String())
+
+	_, err = notificationsScheduler(config, &Notifier{}, nil, nil, "Mars/Olympus")
+	assert.Error(t, err)
+}
@@ -13,10 +13,15 @@ import (
 	"net/http"
 	"rutubeTest/bot"
 	"rutubeTest/configs"
+	"rutubeTest/pkg/greeting"
 	"rutubeTest/pkg/handlers"
+	"rutubeTest/pkg/lock"
 	"rutubeTest/pkg/middleware"
+	"rutubeTest/pkg/notify"
+	"rutubeTest/pkg/queue"
 	"rutubeTest/pkg/sessions"
 	"rutubeTest/pkg/user"
+	"time"
 )
 
 func main() {
@@ -55,6 +60,18 @@ func main() {
 
 	sessManager := sessions.NewSessionManager(redisConn)
 
+	// Воркеры очереди и блокировка рассылки работают параллельно, поэтому для них нужен пул соединений.
+	redisPool := &redis.Pool{
+		MaxIdle:     config.Queue.Workers + 1,
+		IdleTimeout: 4 * time.Minute,
+		Dial: func() (redis.Conn, error) {
+			return redis.DialURL(*addr)
+		},
+	}
+	defer redisPool.Close()
+	jobs := queue.NewRedisQueue(redisPool, "notifications", config.Queue.VisibilityTimeout, config.Queue.MaxDeliveries)
+	locker := lock.NewRedisLocker(redisPool)
+
 	zapLogger, err := zap.NewProduction()
 	if err != nil {
 		log.Printf("Error making new logger: %v", err)
@@ -66,12 +83,22 @@ func main() {
 	}()
 	logger := zapLogger.Sugar()
 
-	userRepo := user.NewMysqlRepo(mysql)
+	userRepo := user.NewMysqlRepo(mysql, config.Birthday.LeapDay)
+	notifyRepo := notify.NewMysqlRepo(mysql)
+	templateRepo := greeting.NewMysqlRepo(mysql)
 
 	userHandler := &handlers.UserHandler{
 		UserRepo: userRepo,
 		Logger:   logger,
 		Sessions: sessManager,
+		LeapDay:  config.Birthday.LeapDay,
+	}
+
+	templateHandler := &handlers.TemplateHandler{
+		Templates: templateRepo,
+		Logger:    logger,
+		Sessions:  sessManager,
+		AdminIDs:  config.API.AdminIDs,
 	}
 
 	r := mux.NewRouter()
@@ -81,6 +108,14 @@ func main() {
 	r.HandleFunc("/api/users", userHandler.GetUsers).Methods("GET")
 	r.HandleFunc("/api/subscribe", userHandler.SubscribeToUser).Methods("POST")
 	r.HandleFunc("/api/unsubscribe", userHandler.UnsubscribeToUser).Methods("POST")
+	r.HandleFunc("/api/reminders", userHandler.SetReminders).Methods("POST")
+	r.HandleFunc("/api/timezone", userHandler.SetTimeZone).Methods("POST")
+	r.HandleFunc("/api/me/settings", userHandler.SaveSettings).Methods("PUT")
+	r.HandleFunc("/api/birthdays/upcoming", userHandler.GetUpcomingBirthdays).Methods("GET")
+	r.HandleFunc("/api/templates", templateHandler.GetTemplates).Methods("GET")
+	r.HandleFunc("/api/templates", templateHandler.SaveTemplate).Methods("PUT")
+	r.HandleFunc("/api/templates", templateHandler.DeleteTemplate).Methods("DELETE")
+	r.HandleFunc("/api/templates/preview", templateHandler.PreviewTemplate).Methods("POST")
 
 	middleWares := middleware.AccessLog(logger, r)
 
@@ -98,7 +133,7 @@ func main() {
 
 	// Запуск тг бота в горутине
 	go func() {
-		err = bot.StartTaskBot(ctx, config.Bot.Token, userRepo)
+		err = bot.StartTaskBot(ctx, config, userRepo, notifyRepo, templateRepo, jobs, locker)
 		if err != nil {
 			log.Println(err)
 		}
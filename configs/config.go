@@ -1,9 +1,16 @@
 package configs
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
+
+	"rutubeTest/pkg/calendar"
+	"rutubeTest/pkg/user"
 
 	"github.com/joho/godotenv"
 )
@@ -16,16 +23,63 @@ type Config struct {
 		Password string
 		Name     string
 	}
+	API struct {
+		// AdminIDs - id пользователей, которым доступно управление шаблонами напоминаний.
+		AdminIDs []int64
+	}
 	Redis struct {
 		Host string
 		Port int
 		User string
 	}
 	Bot struct {
-		Token string
+		Token       string
+		Mode        string
+		PollTimeout int
+		WebhookURL  string
+		WebhookPath string
+		ListenAddr  string
+		SecretToken string
+		// Debug включает лог каждого запроса к телеграму. В нём видны токены, поэтому по умолчанию он выключен.
+		Debug bool
+		// AdminChatIDs - чаты, куда бот сообщает о недоставленных уведомлениях.
+		AdminChatIDs []int64
+		// NudgeChatID - общий чат, где бот просит привязать телеграм подписчиков, которым не может написать.
+		NudgeChatID int64
+		// Лимиты отправки сообщений в секунду: всего и в один чат.
+		GlobalRate int
+		ChatRate   int
+	}
+	Notify struct {
+		SendAt         time.Duration // Время рассылки - смещение от полуночи.
+		Location       *time.Location
+		MaxCatchUpDays int
+		// LockTTL - на сколько реплика берёт блокировку рассылки; пока рассылка идёт, блокировка продлевается.
+		LockTTL time.Duration
+		// Calendar - производственный календарь для переноса поздравлений с нерабочих дней; nil - не переносить.
+		Calendar *calendar.Calendar
+	}
+	Birthday struct {
+		// LeapDay - когда поздравлять родившихся 29 февраля в невисокосный год.
+		LeapDay user.LeapDayPolicy
+	}
+	Queue struct {
+		Workers int
+		// VisibilityTimeout - через сколько задача, которую воркер не подтвердил, вернётся в очередь.
+		VisibilityTimeout time.Duration
+		// MaxDeliveries - сколько раз задача выдаётся воркерам, прежде чем попасть в dead-letter список.
+		MaxDeliveries int
 	}
 }
 
+var secretTokenRe = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// Режимы получения обновлений от Telegram.
+const (
+	BotModeWebhook = "webhook"
+	BotModePolling = "polling"
+)
+
 func LoadConfig() (Config, error) {
 	var config Config
 
@@ -39,15 +93,122 @@ func LoadConfig() (Config, error) {
 	config.MySQL.Password = os.Getenv("MYSQL_PASSWORD")
 	config.MySQL.Name = os.Getenv("MYSQL_NAME")
 
+	apiAdminIDs, err := getEnvAsInt64Slice("API_ADMIN_IDS")
+	if err != nil {
+		return config, fmt.Errorf("invalid api admin ids: %w", err)
+	}
+	config.API.AdminIDs = apiAdminIDs
+
 	config.Redis.Host = os.Getenv("REDIS_HOST")
 	config.Redis.Port = getEnvAsInt("REDIS_PORT", 6379)
 	config.Redis.User = os.Getenv("REDIS_USER")
 
 	config.Bot.Token = os.Getenv("BOT_TOKEN")
+	config.Bot.Mode = getEnv("BOT_MODE", BotModeWebhook)
+	config.Bot.PollTimeout = getEnvAsInt("BOT_POLL_TIMEOUT", 60)
+	config.Bot.WebhookURL = os.Getenv("BOT_WEBHOOK_URL")
+	config.Bot.WebhookPath = getEnv("BOT_WEBHOOK_PATH", "/")
+	config.Bot.ListenAddr = getEnv("BOT_LISTEN_ADDR", ":8081")
+	config.Bot.SecretToken = os.Getenv("BOT_SECRET_TOKEN")
+	config.Bot.Debug = getEnvAsBool("BOT_DEBUG", false)
+
+	config.Bot.NudgeChatID = int64(getEnvAsInt("BOT_NUDGE_CHAT_ID", 0))
+	config.Bot.GlobalRate = getEnvAsInt("BOT_GLOBAL_RATE", 30)
+	config.Bot.ChatRate = getEnvAsInt("BOT_CHAT_RATE", 1)
+	if config.Bot.GlobalRate <= 0 || config.Bot.ChatRate <= 0 {
+		return config, fmt.Errorf("bot rate limits must be positive")
+	}
+
+	adminChatIDs, err := getEnvAsInt64Slice("BOT_ADMIN_CHAT_IDS")
+	if err != nil {
+		return config, fmt.Errorf("invalid admin chat ids: %w", err)
+	}
+	config.Bot.AdminChatIDs = adminChatIDs
+
+	sendAt, err := time.Parse("15:04", getEnv("NOTIFY_TIME", "09:00"))
+	if err != nil {
+		return config, fmt.Errorf("invalid notify time: %w", err)
+	}
+	config.Notify.SendAt = time.Duration(sendAt.Hour())*time.Hour + time.Duration(sendAt.Minute())*time.Minute
+
+	config.Notify.Location, err = time.LoadLocation(getEnv("NOTIFY_TIMEZONE", "Local"))
+	if err != nil {
+		return config, fmt.Errorf("invalid notify timezone: %w", err)
+	}
+
+	config.Notify.MaxCatchUpDays = getEnvAsInt("NOTIFY_MAX_CATCH_UP_DAYS", 7)
+	if config.Notify.MaxCatchUpDays < 0 {
+		return config, fmt.Errorf("notify max catch up days must not be negative")
+	}
+
+	config.Notify.LockTTL = time.Duration(getEnvAsInt("NOTIFY_LOCK_TTL", 60)) * time.Second
+	if config.Notify.LockTTL <= 0 {
+		return config, fmt.Errorf("notify lock ttl must be positive")
+	}
+
+	config.Notify.Calendar, err = loadCalendar(os.Getenv("NOTIFY_WORK_CALENDAR"))
+	if err != nil {
+		return config, fmt.Errorf("invalid work calendar: %w", err)
+	}
+
+	config.Birthday.LeapDay, err = user.ParseLeapDayPolicy(getEnv("BIRTHDAY_LEAP_DAY", string(user.LeapDayMar1)))
+	if err != nil {
+		return config, err
+	}
+
+	config.Queue.Workers = getEnvAsInt("QUEUE_WORKERS", 4)
+	config.Queue.VisibilityTimeout = time.Duration(getEnvAsInt("QUEUE_VISIBILITY_TIMEOUT", 60)) * time.Second
+	config.Queue.MaxDeliveries = getEnvAsInt("QUEUE_MAX_DELIVERIES", 5)
+	if config.Queue.Workers <= 0 || config.Queue.VisibilityTimeout <= 0 || config.Queue.MaxDeliveries <= 0 {
+		return config, fmt.Errorf("queue settings must be positive")
+	}
+
+	if config.Bot.Mode != BotModeWebhook && config.Bot.Mode != BotModePolling {
+		return config, fmt.Errorf("unknown bot mode %q", config.Bot.Mode)
+	}
+
+	// Путь регистрируется в http.ServeMux, который принимает только пути от корня.
+	if !strings.HasPrefix(config.Bot.WebhookPath, "/") {
+		return config, fmt.Errorf("bot webhook path must start with /")
+	}
+
+	// Без secret_token любой, кто узнал адрес вебхука, может присылать боту поддельные обновления.
+	if config.Bot.Mode == BotModeWebhook && config.Bot.SecretToken == "" {
+		return config, fmt.Errorf("bot secret token is required in webhook mode")
+	}
+
+	// Телеграм принимает secret_token длиной до 256 символов из A-Z, a-z, 0-9, _ и -.
+	if config.Bot.SecretToken != "" && !secretTokenRe.MatchString(config.Bot.SecretToken) {
+		return config, fmt.Errorf("invalid bot secret token")
+	}
 
 	return config, nil
 }
 
+// WorkCalendarWeekends - значение NOTIFY_WORK_CALENDAR, при котором нерабочими считаются только суббота и воскресенье.
+const WorkCalendarWeekends = "weekends"
+
+// loadCalendar возвращает производственный календарь по значению NOTIFY_WORK_CALENDAR:
+// пустое значение отключает перенос, WorkCalendarWeekends - только выходные, иначе это путь к файлу .json или .csv.
+func loadCalendar(value string) (*calendar.Calendar, error) {
+	switch value {
+	case "":
+		return nil, nil
+	case WorkCalendarWeekends:
+		return calendar.New(nil, nil)
+	default:
+		return calendar.Load(value)
+	}
+}
+
+// getEnv возвращает значение переменной окружения или значение по умолчанию, если она не установлена.
+func getEnv(key string, defaultVal string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultVal
+}
+
 // getEnvAsInt преобразует переменную окружения в int.
 // Возвращает значение по умолчанию, если переменная не установлена или не может быть преобразована в int.
 func getEnvAsInt(key string, defaultVal int) int {
@@ -57,3 +218,30 @@ func getEnvAsInt(key string, defaultVal int) int {
 	}
 	return defaultVal
 }
+
+// getEnvAsBool преобразует переменную окружения в bool.
+// Возвращает значение по умолчанию, если переменная не установлена или не может быть преобразована в bool.
+func getEnvAsBool(key string, defaultVal bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultVal
+}
+
+// getEnvAsInt64Slice разбирает переменную окружения со списком чисел через запятую.
+// Пустая или не установленная переменная даёт пустой список.
+func getEnvAsInt64Slice(key string) ([]int64, error) {
+	var values []int64
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		value, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
@@ -1,3 +1,8 @@
+DROP TABLE IF EXISTS congratulations;
+DROP TABLE IF EXISTS templates;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS job_runs;
+DROP TABLE IF EXISTS settings;
 DROP TABLE IF EXISTS subscribes;
 DROP TABLE IF EXISTS users;
 
@@ -10,13 +15,78 @@ CREATE TABLE users (
                        password VARCHAR(200) NOT NULL,
                        birthday DATE NOT NULL,
                        telegram VARCHAR(200) NOT NULL UNIQUE,
-                       telegramID INT UNIQUE
+                       telegramID INT UNIQUE,
+                       timezone VARCHAR(64) NOT NULL DEFAULT '',
+                       department VARCHAR(200) NOT NULL DEFAULT '',
+                       language VARCHAR(2) NOT NULL DEFAULT '',
+                       gender VARCHAR(6) NOT NULL DEFAULT ''
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
 
 CREATE TABLE subscribes (
                             id INT AUTO_INCREMENT PRIMARY KEY,
                             userID INT NOT NULL,
                             subscriberID INT NOT NULL,
+                            reminders VARCHAR(100) NOT NULL DEFAULT '0',
+                            UNIQUE KEY (userID, subscriberID),
                             FOREIGN KEY (userID) REFERENCES users(id),
                             FOREIGN KEY (subscriberID) REFERENCES users(id)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE settings (
+                          userID INT PRIMARY KEY,
+                          notifyHour INT NOT NULL DEFAULT -1,
+                          quietFrom INT NOT NULL DEFAULT 0,
+                          quietTo INT NOT NULL DEFAULT 0,
+                          mutedUntil DATE,
+                          digest BOOLEAN NOT NULL DEFAULT FALSE,
+                          summary VARCHAR(10) NOT NULL DEFAULT '',
+                          greet BOOLEAN NOT NULL DEFAULT FALSE,
+                          FOREIGN KEY (userID) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE job_runs (
+                          name VARCHAR(100) PRIMARY KEY,
+                          lastRun DATE NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE notifications (
+                               id INT AUTO_INCREMENT PRIMARY KEY,
+                               userID INT NOT NULL,
+                               subscriberID INT NOT NULL,
+                               day DATE NOT NULL,
+                               kind VARCHAR(20) NOT NULL DEFAULT 'reminder',
+                               daysBefore INT NOT NULL,
+                               status VARCHAR(20) NOT NULL,
+                               attempts INT NOT NULL DEFAULT 0,
+                               messageID INT,
+                               chatID BIGINT NOT NULL,
+                               text TEXT NOT NULL,
+                               lastError VARCHAR(255),
+                               nextAttempt DATETIME,
+                               claimedAt DATETIME,
+                               reported BOOLEAN NOT NULL DEFAULT FALSE,
+                               UNIQUE KEY (userID, subscriberID, day, kind),
+                               KEY (status, nextAttempt),
+                               FOREIGN KEY (userID) REFERENCES users(id),
+                               FOREIGN KEY (subscriberID) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE templates (
+                           id INT AUTO_INCREMENT PRIMARY KEY,
+                           kind VARCHAR(20) NOT NULL,
+                           language VARCHAR(2) NOT NULL,
+                           team VARCHAR(200) NOT NULL DEFAULT '',
+                           text TEXT NOT NULL,
+                           UNIQUE KEY (kind, language, team)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE congratulations (
+                                 id INT AUTO_INCREMENT PRIMARY KEY,
+                                 userID INT NOT NULL,
+                                 authorID INT NOT NULL,
+                                 anonymous BOOLEAN NOT NULL DEFAULT FALSE,
+                                 text TEXT NOT NULL,
+                                 UNIQUE KEY (userID, authorID),
+                                 FOREIGN KEY (userID) REFERENCES users(id),
+                                 FOREIGN KEY (authorID) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
@@ -0,0 +1,135 @@
+package calendar
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DateLayout - формат дат в файле календаря.
+const DateLayout = "2006-01-02"
+
+// maxDaysOff - сколько нерабочих дней подряд ищется рабочий день; дольше не бывает даже в новогодние праздники.
+const maxDaysOff = 31
+
+// Типы дней в CSV-файле календаря.
+const (
+	dayHoliday = "holiday"
+	dayWorkday = "workday"
+)
+
+// Calendar - производственный календарь: суббота и воскресенье выходные, кроме перенесённых рабочих дней,
+// и праздники нерабочие.
+type Calendar struct {
+	holidays map[string]bool
+	workdays map[string]bool
+}
+
+// calendarFile - календарь в JSON: {"holidays": ["2025-01-01", ...], "workdays": ["2025-11-01", ...]}.
+type calendarFile struct {
+	Holidays []string `json:"holidays"`
+	Workdays []string `json:"workdays"`
+}
+
+// New создаёт календарь с праздниками holidays и рабочими днями-переносами workdays в формате DateLayout.
+func New(holidays, workdays []string) (*Calendar, error) {
+	c := &Calendar{
+		holidays: make(map[string]bool, len(holidays)),
+		workdays: make(map[string]bool, len(workdays)),
+	}
+	for _, day := range holidays {
+		if _, err := time.Parse(DateLayout, day); err != nil {
+			return nil, fmt.Errorf("bad holiday %q: %w", day, err)
+		}
+		c.holidays[day] = true
+	}
+	for _, day := range workdays {
+		if _, err := time.Parse(DateLayout, day); err != nil {
+			return nil, fmt.Errorf("bad workday %q: %w", day, err)
+		}
+		c.workdays[day] = true
+	}
+	return c, nil
+}
+
+// Load читает календарь из файла .json или .csv. В CSV каждая строка - дата и тип дня:
+// "2025-01-01,holiday" или "2025-11-01,workday"; строка заголовка "date,type" допустима.
+func Load(path string) (*Calendar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return decodeJSON(f)
+	case ".csv":
+		return decodeCSV(f)
+	default:
+		return nil, fmt.Errorf("unknown calendar format %q", path)
+	}
+}
+
+func decodeJSON(r io.Reader) (*Calendar, error) {
+	var file calendarFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+	return New(file.Holidays, file.Workdays)
+}
+
+func decodeCSV(r io.Reader) (*Calendar, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var holidays, workdays []string
+	for i, record := range records {
+		if i == 0 && record[0] == "date" {
+			continue
+		}
+		switch record[1] {
+		case dayHoliday:
+			holidays = append(holidays, record[0])
+		case dayWorkday:
+			workdays = append(workdays, record[0])
+		default:
+			return nil, fmt.Errorf("line %d: unknown day type %q", i+1, record[1])
+		}
+	}
+	return New(holidays, workdays)
+}
+
+// IsWorkday сообщает, рабочий ли день day. Учитывается только календарная дата.
+func (c *Calendar) IsWorkday(day time.Time) bool {
+	key := day.Format(DateLayout)
+	if c.workdays[key] {
+		return true
+	}
+	if c.holidays[key] {
+		return false
+	}
+	return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday
+}
+
+// LastWorkdayBefore возвращает последний рабочий день перед day. false значит, что рабочего дня не нашлось.
+func (c *Calendar) LastWorkdayBefore(day time.Time) (time.Time, bool) {
+	for i := 1; i <= maxDaysOff; i++ {
+		prev := day.AddDate(0, 0, -i)
+		if c.IsWorkday(prev) {
+			return prev, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,89 @@
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(month time.Month, day int) time.Time {
+	return time.Date(2025, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCalendar(t *testing.T) {
+	c, err := New([]string{"2025-06-12"}, []string{"2025-11-01"})
+	assert.NoError(t, err)
+
+	assert.True(t, c.IsWorkday(date(6, 11)))
+	assert.False(t, c.IsWorkday(date(6, 12)), "праздник")
+	assert.False(t, c.IsWorkday(date(6, 14)), "суббота")
+	assert.True(t, c.IsWorkday(date(11, 1)), "рабочая суббота")
+
+	// Перед выходными 14-15 июня и праздником 12 июня последний рабочий день - 11 июня.
+	prev, ok := c.LastWorkdayBefore(date(6, 15))
+	assert.True(t, ok)
+	assert.Equal(t, date(6, 13), prev)
+
+	prev, ok = c.LastWorkdayBefore(date(6, 12))
+	assert.True(t, ok)
+	assert.Equal(t, date(6, 11), prev)
+
+	_, err = New([]string{"12.06.2025"}, nil)
+	assert.Error(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "JSON",
+			file:    "calendar.json",
+			content: `{"holidays": ["2025-06-12"], "workdays": ["2025-11-01"]}`,
+		},
+		{
+			name:    "CSV с заголовком",
+			file:    "calendar.csv",
+			content: "date,type\n2025-06-12,holiday\n2025-11-01, workday\n",
+		},
+		{
+			name:    "CSV с неизвестным типом дня",
+			file:    "bad.csv",
+			content: "2025-06-12,vacation\n",
+			wantErr: true,
+		},
+		{
+			name:    "Неизвестный формат",
+			file:    "calendar.txt",
+			content: "2025-06-12",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, tc.file)
+			assert.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			c, err := Load(path)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.False(t, c.IsWorkday(date(6, 12)))
+			assert.True(t, c.IsWorkday(date(11, 1)))
+		})
+	}
+
+	_, err := Load(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
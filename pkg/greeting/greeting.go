@@ -0,0 +1,293 @@
+package greeting
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"rutubeTest/pkg/i18n"
+	"rutubeTest/pkg/inflect"
+	"rutubeTest/pkg/user"
+)
+
+// DefaultLanguage - язык, шаблоны которого используются, если для языка подписчика шаблона нет.
+const DefaultLanguage = i18n.DefaultLanguage
+
+// Виды шаблонов напоминаний.
+const (
+	KindToday    = "today"    // День рождения сегодня.
+	KindTomorrow = "tomorrow" // День рождения завтра.
+	KindSoon     = "soon"     // День рождения через DaysUntil дней.
+	KindLate     = "late"     // День рождения уже прошёл: рассылка догоняет простой.
+	KindDayOff   = "dayoff"   // День рождения в нерабочий день, поздравление перенесено на сегодня.
+	KindBirthday = "birthday" // Поздравление самому имениннику в его день рождения.
+)
+
+var (
+	ErrNoTemplate  = errors.New("no template found")
+	ErrBadTemplate = errors.New("bad template")
+	// Причины, по которым шаблон не прошёл CheckTemplate; все они оборачивают ErrBadTemplate.
+	ErrUnknownKind      = fmt.Errorf("%w: unknown kind", ErrBadTemplate)
+	ErrTemplateLanguage = fmt.Errorf("%w: language must be a two-letter code", ErrBadTemplate)
+	ErrEmptyTemplate    = fmt.Errorf("%w: empty text", ErrBadTemplate)
+)
+
+// Template - шаблон текста напоминания вида Kind на языке Language для подписчиков из отдела Team.
+// Пустой Team - шаблон для всех отделов. Text - шаблон text/template, поля которого описаны в Data.
+type Template struct {
+	ID       int64  `json:"id"`
+	Kind     string `json:"kind"`
+	Language string `json:"language"`
+	Team     string `json:"team"`
+	Text     string `json:"text"`
+}
+
+// Data - поля, доступные в шаблоне. Кроме полей, в шаблоне доступны формы имени и местоимения
+// в нужном падеже: {{.NameIn "gen"}}, {{.FirstNameIn "dat"}}, {{.Pronoun "acc"}}; падежи перечислены в inflect.
+type Data struct {
+	Name       string `json:"name"`      // Полное имя именинника.
+	FirstName  string `json:"firstname"` // Имя именинника.
+	MiddleName string `json:"middlename"`
+	LastName   string `json:"lastname"`
+	Gender     string `json:"gender"` // Род именинника, как в user.User; пустая строка - не указан.
+	Age        int    `json:"age"`    // Сколько лет исполняется; 0, если год рождения неизвестен.
+	Department string `json:"department"`
+	// DaysUntil - через сколько дней день рождения; отрицательное значение - сколько дней назад он был.
+	DaysUntil int          `json:"daysUntil"`
+	Date      string       `json:"date"` // Дата дня рождения в формате ДД.ММ.
+	Weekday   time.Weekday `json:"weekday"`
+
+	language string // Язык шаблона, в который подставляются данные.
+}
+
+// SampleData - данные, на которых проверяются шаблоны перед сохранением и строится предпросмотр.
+var SampleData = Data{
+	Name:       "Иван Иванов",
+	FirstName:  "Иван",
+	LastName:   "Иванов",
+	Gender:     user.GenderMale,
+	Age:        30,
+	Department: "QA",
+	DaysUntil:  3,
+	Date:       "14.06",
+	Weekday:    time.Friday,
+}
+
+// weekdaysAccusative - дни недели в винительном падеже с предлогом, как в "в субботу".
+var weekdaysAccusative = map[time.Weekday]string{
+	time.Monday:    "В понедельник",
+	time.Tuesday:   "Во вторник",
+	time.Wednesday: "В среду",
+	time.Thursday:  "В четверг",
+	time.Friday:    "В пятницу",
+	time.Saturday:  "В субботу",
+	time.Sunday:    "В воскресенье",
+}
+
+// funcs - функции, доступные в шаблонах. onWeekday возвращает день недели с предлогом для начала
+// русского предложения, например "В субботу"; в других языках можно вывести {{.Weekday}}.
+var funcs = template.FuncMap{
+	"onWeekday": func(d time.Weekday) string { return weekdaysAccusative[d] },
+}
+
+// Inflectors - правила склонения имён по языкам. Для языков, которых здесь нет, имена не склоняются.
+var Inflectors = map[string]inflect.Inflector{
+	"ru": inflect.Russian,
+}
+
+// pronouns - личные местоимения в косвенных падежах по языкам и роду. Для неизвестного рода в русском
+// местоимения нет, поэтому шаблоны проверяют {{if .Gender}}.
+var pronouns = map[string]map[string]map[inflect.Case]string{
+	"ru": {
+		user.GenderMale:    {inflect.Nominative: "он", inflect.Genitive: "его", inflect.Dative: "ему", inflect.Accusative: "его", inflect.Instrumental: "им", inflect.Prepositional: "нём"},
+		user.GenderFemale:  {inflect.Nominative: "она", inflect.Genitive: "её", inflect.Dative: "ей", inflect.Accusative: "её", inflect.Instrumental: "ей", inflect.Prepositional: "ней"},
+		user.GenderUnknown: {},
+	},
+	"en": {
+		user.GenderMale:    {inflect.Nominative: "he", inflect.Genitive: "his", inflect.Dative: "him", inflect.Accusative: "him", inflect.Instrumental: "him", inflect.Prepositional: "him"},
+		user.GenderFemale:  {inflect.Nominative: "she", inflect.Genitive: "her", inflect.Dative: "her", inflect.Accusative: "her", inflect.Instrumental: "her", inflect.Prepositional: "her"},
+		user.GenderUnknown: {inflect.Nominative: "they", inflect.Genitive: "their", inflect.Dative: "them", inflect.Accusative: "them", inflect.Instrumental: "them", inflect.Prepositional: "them"},
+	},
+}
+
+// NameIn возвращает полное имя именинника в падеже c. Если имя не разбито на части, возвращается Name.
+func (d Data) NameIn(c string) (string, error) {
+	cs, err := inflect.ParseCase(c)
+	if err != nil {
+		return "", err
+	}
+	if d.FirstName == "" && d.LastName == "" {
+		return d.Name, nil
+	}
+
+	var parts []string
+	for _, p := range []struct {
+		word string
+		part inflect.Part
+	}{{d.FirstName, inflect.FirstName}, {d.MiddleName, inflect.MiddleName}, {d.LastName, inflect.LastName}} {
+		if p.word != "" {
+			parts = append(parts, d.inflect(p.word, p.part, cs))
+		}
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// FirstNameIn возвращает имя именинника в падеже c.
+func (d Data) FirstNameIn(c string) (string, error) {
+	cs, err := inflect.ParseCase(c)
+	if err != nil {
+		return "", err
+	}
+	return d.inflect(d.FirstName, inflect.FirstName, cs), nil
+}
+
+// Pronoun возвращает личное местоимение третьего лица для именинника в падеже c.
+func (d Data) Pronoun(c string) (string, error) {
+	cs, err := inflect.ParseCase(c)
+	if err != nil {
+		return "", err
+	}
+	return pronouns[d.lang()][d.Gender][cs], nil
+}
+
+func (d Data) inflect(word string, part inflect.Part, c inflect.Case) string {
+	inflector, ok := Inflectors[d.lang()]
+	if !ok {
+		return word
+	}
+	return inflector.Inflect(word, part, d.Gender, c)
+}
+
+func (d Data) lang() string {
+	if d.language == "" {
+		return DefaultLanguage
+	}
+	return d.language
+}
+
+// builtin - шаблоны по умолчанию, которые используются, если в базе подходящего шаблона нет.
+var builtin = map[string]map[string]string{
+	"ru": {
+		KindToday: `Сегодня день рождения у {{.NameIn "gen"}}! ` +
+			`{{if .Gender}}Поздравьте {{.Pronoun "acc"}}!{{else}}Не забудьте поздравить!{{end}}`,
+		KindTomorrow: `Завтра ({{.Date}}) день рождения у {{.NameIn "gen"}}. Не забудьте поздравить!`,
+		KindSoon:     `Через {{.DaysUntil}} дн. ({{.Date}}) день рождения у {{.NameIn "gen"}}. Самое время подготовить подарок!`,
+		KindLate:     `{{.Date}} был день рождения у {{.NameIn "gen"}}. Поздравить ещё не поздно!`,
+		KindDayOff: `{{onWeekday .Weekday}} ({{.Date}}) день рождения у {{.NameIn "gen"}}, это нерабочий день. ` +
+			`Поздравьте {{if .Gender}}{{.Pronoun "acc"}} {{end}}сегодня!`,
+		KindBirthday: `{{.FirstName}}, с днём рождения! Желаем здоровья, счастья и успехов!`,
+	},
+	"en": {
+		KindToday:    `Today is {{.Name}}'s birthday! Don't forget to congratulate {{.Pronoun "acc"}}!`,
+		KindTomorrow: `Tomorrow ({{.Date}}) is {{.Name}}'s birthday. Don't forget to congratulate {{.Pronoun "acc"}}!`,
+		KindSoon:     `In {{.DaysUntil}} days ({{.Date}}) it's {{.Name}}'s birthday. Time to get a present!`,
+		KindLate:     `{{.Name}} had a birthday on {{.Date}}. It's not too late to congratulate {{.Pronoun "acc"}}!`,
+		KindDayOff:   `{{.Name}}'s birthday is on {{.Weekday}} ({{.Date}}), a day off. Congratulate {{.Pronoun "acc"}} today!`,
+		KindBirthday: `Happy birthday, {{.FirstName}}! We wish you health, happiness and success!`,
+	},
+}
+
+type TemplateRepo interface {
+	GetTemplates() ([]Template, error)
+	SaveTemplate(t Template) (*Template, error)
+	DeleteTemplate(id int64) error
+}
+
+// Render подставляет data в шаблон text на языке language; от языка зависят формы имени и местоимения.
+func Render(text, language string, data Data) (string, error) {
+	tmpl, err := parse(text)
+	if err != nil {
+		return "", err
+	}
+	data.language = language
+	return execute(tmpl, data)
+}
+
+// CheckTemplate проверяет вид и язык шаблона и то, что он разбирается и выполняется на SampleData.
+func CheckTemplate(t Template) error {
+	if _, ok := builtin[DefaultLanguage][t.Kind]; !ok {
+		return fmt.Errorf("%w %q", ErrUnknownKind, t.Kind)
+	}
+	if t.Language == "" || user.CheckLanguage(t.Language) != nil {
+		return ErrTemplateLanguage
+	}
+	text, err := Render(t.Text, t.Language, SampleData)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBadTemplate, err)
+	}
+	if text == "" {
+		return ErrEmptyTemplate
+	}
+	return nil
+}
+
+func parse(text string) (*template.Template, error) {
+	return template.New("greeting").Funcs(funcs).Parse(text)
+}
+
+func execute(tmpl *template.Template, data Data) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+type key struct {
+	kind, language, team string
+}
+
+// Catalog выбирает шаблон напоминания по цепочке: язык подписчика, затем DefaultLanguage; для каждого языка -
+// шаблон его отдела, шаблон для всех отделов и шаблон по умолчанию. Пустой Catalog использует только шаблоны
+// по умолчанию.
+type Catalog struct {
+	templates map[key]*template.Template
+}
+
+// NewCatalog собирает каталог из шаблонов, сохранённых в базе. Шаблоны, которые не разбираются, пропускаются.
+func NewCatalog(list []Template) *Catalog {
+	c := &Catalog{templates: make(map[key]*template.Template)}
+	for _, t := range list {
+		tmpl, err := parse(t.Text)
+		if err != nil {
+			fmt.Println("Error parsing template:", err)
+			continue
+		}
+		c.templates[key{t.Kind, t.Language, t.Team}] = tmpl
+	}
+	return c
+}
+
+// Render возвращает текст напоминания вида kind для подписчика с языком language из отдела team.
+// Шаблон, который не удалось выполнить, пропускается, и берётся следующий в цепочке.
+func (c *Catalog) Render(kind, language, team string, data Data) (string, error) {
+	for _, lang := range chain(language, DefaultLanguage) {
+		for _, tm := range chain(team, "") {
+			tmpl, ok := c.templates[key{kind, lang, tm}]
+			if !ok {
+				continue
+			}
+			data.language = lang
+			text, err := execute(tmpl, data)
+			if err == nil {
+				return text, nil
+			}
+			fmt.Println("Error executing template:", err)
+		}
+		if text, ok := builtin[lang][kind]; ok {
+			return Render(text, lang, data)
+		}
+	}
+	return "", ErrNoTemplate
+}
+
+// chain возвращает value и fallback без пустых значений и повторов.
+func chain(value, fallback string) []string {
+	if value == "" || value == fallback {
+		return []string{fallback}
+	}
+	return []string{value, fallback}
+}
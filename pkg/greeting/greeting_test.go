@@ -0,0 +1,129 @@
+package greeting
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"rutubeTest/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogRender(t *testing.T) {
+	data := Data{Name: "John Doe", FirstName: "John", LastName: "Doe", Age: 34, Department: "Dev", DaysUntil: 0, Date: "11.06", Weekday: time.Tuesday}
+	catalog := NewCatalog([]Template{
+		{Kind: KindToday, Language: "ru", Text: "{{.FirstName}}, {{.Age}}: поздравляем!"},
+		{Kind: KindToday, Language: "ru", Team: "QA", Text: "QA поздравляет {{.Name}} из {{.Department}}!"},
+		{Kind: KindToday, Language: "en", Team: "Ops", Text: "{{.Missing}}"},
+		{Kind: KindTomorrow, Language: "ru", Team: "QA", Text: "{{.Name"},
+	})
+
+	tests := []struct {
+		name     string
+		kind     string
+		language string
+		team     string
+		expected string
+	}{
+		{
+			name:     "Шаблон отдела",
+			kind:     KindToday,
+			language: "ru",
+			team:     "QA",
+			expected: "QA поздравляет John Doe из Dev!",
+		},
+		{
+			name:     "Шаблон для всех отделов",
+			kind:     KindToday,
+			team:     "Dev",
+			expected: "John, 34: поздравляем!",
+		},
+		{
+			name:     "Язык без своих шаблонов",
+			kind:     KindToday,
+			language: "en",
+			expected: "Today is John Doe's birthday! Don't forget to congratulate them!",
+		},
+		{
+			name:     "Шаблон с ошибкой пропускается",
+			kind:     KindToday,
+			language: "en",
+			team:     "Ops",
+			expected: "Today is John Doe's birthday! Don't forget to congratulate them!",
+		},
+		{
+			name:     "Неизвестный язык",
+			kind:     KindToday,
+			language: "de",
+			expected: "John, 34: поздравляем!",
+		},
+		{
+			name:     "Шаблон по умолчанию",
+			kind:     KindDayOff,
+			language: "ru",
+			team:     "QA",
+			expected: "Во вторник (11.06) день рождения у John Doe, это нерабочий день. Поздравьте сегодня!",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := catalog.Render(tt.kind, tt.language, tt.team, data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, text)
+		})
+	}
+
+	_, err := catalog.Render("unknown", "ru", "", data)
+	assert.Equal(t, ErrNoTemplate, err)
+}
+
+func TestCatalogRenderGender(t *testing.T) {
+	catalog := NewCatalog(nil)
+	anna := Data{Name: "Анна Сергеевна Петрова", FirstName: "Анна", MiddleName: "Сергеевна", LastName: "Петрова", Gender: user.GenderFemale}
+	ivan := Data{Name: "Иван Петров", FirstName: "Иван", LastName: "Петров", Gender: user.GenderMale, Date: "15.06", Weekday: time.Saturday}
+
+	text, err := catalog.Render(KindToday, "ru", "", anna)
+	assert.NoError(t, err)
+	assert.Equal(t, "Сегодня день рождения у Анны Сергеевны Петровой! Поздравьте её!", text)
+
+	text, err = catalog.Render(KindToday, "en", "", anna)
+	assert.NoError(t, err)
+	assert.Equal(t, "Today is Анна Сергеевна Петрова's birthday! Don't forget to congratulate her!", text)
+
+	text, err = catalog.Render(KindDayOff, "ru", "", ivan)
+	assert.NoError(t, err)
+	assert.Equal(t, "В субботу (15.06) день рождения у Ивана Петрова, это нерабочий день. Поздравьте его сегодня!", text)
+
+	text, err = Render(`{{.FirstNameIn "dat"}} исполняется {{.Age}}`, "ru", ivan)
+	assert.NoError(t, err)
+	assert.Equal(t, "Ивану исполняется 0", text)
+}
+
+func TestCheckTemplate(t *testing.T) {
+	tests := []struct {
+		name  string
+		tmpl  Template
+		valid bool
+	}{
+		{name: "Верный шаблон", tmpl: Template{Kind: KindSoon, Language: "en", Text: "{{.Name}} in {{.DaysUntil}} days"}, valid: true},
+		{name: "Неизвестный вид", tmpl: Template{Kind: "party", Language: "ru", Text: "{{.Name}}"}},
+		{name: "Без языка", tmpl: Template{Kind: KindToday, Text: "{{.Name}}"}},
+		{name: "Синтаксическая ошибка", tmpl: Template{Kind: KindToday, Language: "ru", Text: "{{.Name"}},
+		{name: "Неизвестное поле", tmpl: Template{Kind: KindToday, Language: "ru", Text: "{{.Salary}}"}},
+		{name: "Неизвестный падеж", tmpl: Template{Kind: KindToday, Language: "ru", Text: `{{.NameIn "dative"}}`}},
+		{name: "Пустой текст", tmpl: Template{Kind: KindToday, Language: "ru", Text: ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckTemplate(tt.tmpl)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, ErrBadTemplate), err)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package greeting
+
+import (
+	"database/sql"
+)
+
+type TemplateMysqlRepository struct {
+	DB *sql.DB
+}
+
+func NewMysqlRepo(db *sql.DB) *TemplateMysqlRepository {
+	return &TemplateMysqlRepository{DB: db}
+}
+
+// GetTemplates возвращает все сохранённые шаблоны. Если их нет, возвращается пустой список.
+func (repo *TemplateMysqlRepository) GetTemplates() ([]Template, error) {
+	rows, err := repo.DB.Query("SELECT id, kind, language, team, text FROM templates ORDER BY kind, language, team")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []Template{}
+	for rows.Next() {
+		var t Template
+		if err = rows.Scan(&t.ID, &t.Kind, &t.Language, &t.Team, &t.Text); err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// SaveTemplate проверяет шаблон и сохраняет его. Шаблон того же вида, языка и отдела заменяется.
+func (repo *TemplateMysqlRepository) SaveTemplate(t Template) (*Template, error) {
+	if err := CheckTemplate(t); err != nil {
+		return nil, err
+	}
+
+	// LAST_INSERT_ID(id) возвращает id заменённой строки.
+	result, err := repo.DB.Exec(
+		"INSERT INTO templates (`kind`, `language`, `team`, `text`) VALUES (?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE `text` = VALUES(`text`), `id` = LAST_INSERT_ID(`id`)",
+		t.Kind,
+		t.Language,
+		t.Team,
+		t.Text,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	t.ID, err = result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// DeleteTemplate удаляет шаблон; после этого используется следующий шаблон в цепочке.
+func (repo *TemplateMysqlRepository) DeleteTemplate(id int64) error {
+	result, err := repo.DB.Exec("DELETE FROM templates WHERE `id` = ?", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNoTemplate
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package greeting
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTemplates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db)
+
+	query := regexp.QuoteMeta("SELECT id, kind, language, team, text FROM templates ORDER BY kind, language, team")
+	columns := []string{"id", "kind", "language", "team", "text"}
+
+	tests := []struct {
+		name        string
+		mockFunc    func()
+		expected    []Template
+		expectedErr error
+	}{
+		{
+			name: "Templates found",
+			mockFunc: func() {
+				mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(1, KindToday, "ru", "", "Сегодня {{.Name}}").
+					AddRow(2, KindToday, "ru", "QA", "QA: {{.Name}}"))
+			},
+			expected: []Template{
+				{ID: 1, Kind: KindToday, Language: "ru", Text: "Сегодня {{.Name}}"},
+				{ID: 2, Kind: KindToday, Language: "ru", Team: "QA", Text: "QA: {{.Name}}"},
+			},
+		},
+		{
+			name: "No templates",
+			mockFunc: func() {
+				mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expected: []Template{},
+		},
+		{
+			name: "Query error",
+			mockFunc: func() {
+				mock.ExpectQuery(query).WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc()
+			templates, err := repo.GetTemplates()
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expected, templates)
+		})
+	}
+}
+
+func TestSaveTemplate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db)
+
+	query := regexp.QuoteMeta("INSERT INTO templates (`kind`, `language`, `team`, `text`) VALUES (?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE `text` = VALUES(`text`), `id` = LAST_INSERT_ID(`id`)")
+	tmpl := Template{Kind: KindToday, Language: "en", Team: "QA", Text: "Happy birthday, {{.FirstName}}!"}
+
+	tests := []struct {
+		name        string
+		tmpl        Template
+		mockFunc    func()
+		expected    *Template
+		expectedErr error
+	}{
+		{
+			name: "Template saved",
+			tmpl: tmpl,
+			mockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs(KindToday, "en", "QA", "Happy birthday, {{.FirstName}}!").
+					WillReturnResult(sqlmock.NewResult(5, 1))
+			},
+			expected: &Template{ID: 5, Kind: KindToday, Language: "en", Team: "QA", Text: "Happy birthday, {{.FirstName}}!"},
+		},
+		{
+			name:        "Bad template",
+			tmpl:        Template{Kind: KindToday, Language: "en", Text: "{{.Name"},
+			mockFunc:    func() {},
+			expectedErr: ErrBadTemplate,
+		},
+		{
+			name: "Exec error",
+			tmpl: tmpl,
+			mockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs(KindToday, "en", "QA", "Happy birthday, {{.FirstName}}!").
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc()
+			saved, err := repo.SaveTemplate(tt.tmpl)
+			assert.True(t, errors.Is(err, tt.expectedErr), err)
+			assert.Equal(t, tt.expected, saved)
+		})
+	}
+}
+
+func TestDeleteTemplate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db)
+
+	query := regexp.QuoteMeta("DELETE FROM templates WHERE `id` = ?")
+
+	tests := []struct {
+		name        string
+		mockFunc    func()
+		expectedErr error
+	}{
+		{
+			name: "Template deleted",
+			mockFunc: func() {
+				mock.ExpectExec(query).WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "No template",
+			mockFunc: func() {
+				mock.ExpectExec(query).WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedErr: ErrNoTemplate,
+		},
+		{
+			name: "Exec error",
+			mockFunc: func() {
+				mock.ExpectExec(query).WithArgs(3).WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc()
+			err := repo.DeleteTemplate(3)
+			assert.Equal(t, tt.expectedErr, err)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"rutubeTest/pkg/sessions"
+	"rutubeTest/pkg/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultUpcomingDays = 7
+	maxUpcomingDays     = 366
+)
+
+type UpcomingBirthday struct {
+	user.User
+	NextBirthday string `json:"nextBirthday"`
+	DaysUntil    int    `json:"daysUntil"`
+}
+
+// GetUpcomingBirthdays отдаёт дни рождения в ближайшие days дней.
+// С параметром subscribed=true учитываются только пользователи, на которых подписан автор запроса.
+func (h *UserHandler) GetUpcomingBirthdays(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Infoln("Start authorization")
+
+	token := r.Header.Get("Authorization")
+	if !strings.HasPrefix(token, "Bearer ") {
+		httpError(w, r, ErrUserNotFound, http.StatusUnauthorized)
+		return
+	}
+
+	sess := h.Sessions.Check(&sessions.SessionID{ID: token[7:]})
+	if sess == nil {
+		httpError(w, r, ErrUserNotFound, http.StatusUnauthorized)
+		return
+	}
+	r = withSession(r, sess)
+
+	days := defaultUpcomingDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || d < 0 || d > maxUpcomingDays {
+			httpError(w, r, ErrBadDays, http.StatusBadRequest)
+			return
+		}
+		days = d
+	}
+
+	var subscriberID int64
+	if v := r.URL.Query().Get("subscribed"); v != "" {
+		subscribed, err := strconv.ParseBool(v)
+		if err != nil {
+			httpError(w, r, ErrBadRequest, http.StatusBadRequest)
+			return
+		}
+		if subscribed {
+			subscriberID = sess.ID
+		}
+	}
+
+	now := time.Now()
+	users, err := h.UserRepo.GetUpcomingBirthdays(now, days, subscriberID)
+	if err != nil && !errors.Is(err, user.ErrNoUser) {
+		requestError(w, r, h.Logger, err)
+		return
+	}
+
+	h.Logger.Infoln("upcoming birthdays received")
+
+	result := make([]UpcomingBirthday, 0, len(users))
+	for _, u := range users {
+		next, err := user.NextBirthday(u.Birthday, now, h.LeapDay)
+		if err != nil {
+			h.Logger.Errorln(err.Error())
+			continue
+		}
+		result = append(result, UpcomingBirthday{
+			User:         u,
+			NextBirthday: next.Format(user.BirthdayLayout),
+			DaysUntil:    user.DaysUntil(next, now),
+		})
+	}
+
+	resp, err := json.Marshal(result)
+	if err != nil {
+		internalError(w, r, h.Logger, err)
+		return
+	}
+
+	_, err = w.Write(resp)
+	if err != nil {
+		h.Logger.Errorln(err.Error())
+		return
+	}
+	h.Logger.Infoln("Response sent")
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rutubeTest/pkg/sessions"
+	"rutubeTest/pkg/user"
+	"testing"
+	"time"
+)
+
+func TestGetUpcomingBirthdaysHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+	mockSessions := sessions.NewMockSessionManagerInterface(ctrl)
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		fmt.Println("Got err when making")
+		return
+	}
+
+	service := &UserHandler{
+		UserRepo: mockRepo,
+		Logger:   logger.Sugar(),
+		Sessions: mockSessions,
+	}
+
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	birthday := time.Date(1990, tomorrow.Month(), tomorrow.Day(), 0, 0, 0, 0, time.UTC).Format(user.BirthdayLayout)
+
+	tests := []struct {
+		name       string
+		setupMocks func()
+		query      string
+		authHeader string
+		wantStatus int
+		wantCount  int
+	}{
+		{
+			name: "Дни рождения по умолчанию на неделю",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				mockRepo.EXPECT().GetUpcomingBirthdays(gomock.Any(), 7, int64(0)).
+					Return([]user.User{{ID: 2, Birthday: birthday}}, nil)
+			},
+			authHeader: "Bearer validToken",
+			wantStatus: http.StatusOK,
+			wantCount:  1,
+		},
+		{
+			name: "Только подписки на 30 дней",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				mockRepo.EXPECT().GetUpcomingBirthdays(gomock.Any(), 30, int64(1)).Return(nil, user.ErrNoUser)
+			},
+			query:      "?days=30&subscribed=true",
+			authHeader: "Bearer validToken",
+			wantStatus: http.StatusOK,
+			wantCount:  0,
+		},
+		{
+			name: "Некорректное число дней",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+			},
+			query:      "?days=-1",
+			authHeader: "Bearer validToken",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Некорректный флаг подписок",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+			},
+			query:      "?subscribed=maybe",
+			authHeader: "Bearer validToken",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Неверный токен авторизации",
+			setupMocks: func() {},
+			authHeader: "invalidToken",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "Ошибка репозитория",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				mockRepo.EXPECT().GetUpcomingBirthdays(gomock.Any(), 7, int64(0)).Return(nil, fmt.Errorf("database error"))
+			},
+			authHeader: "Bearer validToken",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			req := httptest.NewRequest("GET", "/api/birthdays/upcoming"+tc.query, nil)
+			req.Header.Add("Authorization", tc.authHeader)
+
+			w := httptest.NewRecorder()
+
+			service.GetUpcomingBirthdays(w, req)
+
+			resp := w.Result()
+			assert.Equal(t, tc.wantStatus, resp.StatusCode)
+
+			if tc.wantStatus == http.StatusOK {
+				var result []map[string]any
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+				assert.Len(t, result, tc.wantCount)
+				for _, b := range result {
+					assert.Equal(t, float64(1), b["daysUntil"])
+					assert.Contains(t, b, "nextBirthday")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"go.uber.org/zap"
+	"net/http"
+	"rutubeTest/pkg/i18n"
+	"rutubeTest/pkg/sessions"
+)
+
+// msgRequired - сообщение об обязательном поле, которое не заполнено.
+const msgRequired = "required"
+
+// messages - тексты ошибок API.
+var messages = i18n.Messages{
+	"ru": {
+		ErrReading:          "не удалось прочитать запрос",
+		ErrUserNotFound:     "пользователь не найден",
+		ErrInvalidPass:      "неверный пароль",
+		ErrBadRequest:       "неверный запрос",
+		ErrBadDays:          "число дней должно быть от 0 до 366",
+		ErrBadReminders:     "напоминания - числа дней от 0 до 30",
+		ErrNoSubscription:   "сначала подпишитесь на пользователя",
+		ErrBadTimeZone:      "неизвестный часовой пояс, укажите его из базы IANA, например Europe/Moscow",
+		ErrBadSettings:      "часы - от 0 до 23 или -1 для времени сервиса, mutedUntil - в формате ГГГГ-ММ-ДД, summary - weekly, monthly или пусто",
+		ErrBadLanguage:      "язык - двухбуквенный код, например ru или en",
+		ErrBadGender:        "род - male, female или пусто",
+		ErrExists:           "пользователь с таким логином уже существует",
+		ErrInternal:         "внутренняя ошибка сервера, попробуйте позже",
+		ErrRequestFailed:    "не удалось выполнить запрос, попробуйте позже",
+		ErrForbidden:        "управлять шаблонами могут только администраторы",
+		ErrNoTemplate:       "шаблон не найден",
+		ErrBadTemplate:      "шаблон не разбирается или ссылается на неизвестное поле",
+		ErrUnknownKind:      "неизвестный вид шаблона, допустимы today, tomorrow, soon, late, dayoff и birthday",
+		ErrTemplateLanguage: "язык шаблона - двухбуквенный код, например ru или en",
+		ErrEmptyTemplate:    "шаблон даёт пустой текст",
+		msgRequired:         "обязательное поле",
+	},
+	"en": {
+		ErrReading:          "error reading request",
+		ErrUserNotFound:     "user not found",
+		ErrInvalidPass:      "invalid password",
+		ErrBadRequest:       "bad request",
+		ErrBadDays:          "days must be a number from 0 to 366",
+		ErrBadReminders:     "reminders must be days from 0 to 30",
+		ErrNoSubscription:   "subscribe to the user first",
+		ErrBadTimeZone:      "unknown time zone, use an IANA name like Europe/Moscow",
+		ErrBadSettings:      "hours must be from 0 to 23 or -1 for the service time, mutedUntil must be YYYY-MM-DD, summary weekly, monthly or empty",
+		ErrBadLanguage:      "language must be a two-letter code like ru or en",
+		ErrBadGender:        "gender must be male, female or empty",
+		ErrExists:           "a user with this username already exists",
+		ErrInternal:         "internal server error, please try again later",
+		ErrRequestFailed:    "the request failed, please try again later",
+		ErrForbidden:        "only admins can manage templates",
+		ErrNoTemplate:       "template not found",
+		ErrBadTemplate:      "the template cannot be parsed or refers to an unknown field",
+		ErrUnknownKind:      "unknown template kind, use today, tomorrow, soon, late, dayoff or birthday",
+		ErrTemplateLanguage: "the template language must be a two-letter code like ru or en",
+		ErrEmptyTemplate:    "the template renders empty text",
+		msgRequired:         "is required",
+	},
+}
+
+// sessionKey - ключ контекста запроса, под которым хранится сессия автора.
+type sessionKey struct{}
+
+// withSession запоминает в контексте запроса сессию автора, чтобы ответить на его языке.
+func withSession(r *http.Request, sess *sessions.Session) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), sessionKey{}, sess))
+}
+
+// requestLanguage возвращает язык ответа на запрос r: язык автора из сессии, а если запрос без сессии
+// или язык в учётной записи не выбран - по заголовку Accept-Language.
+func requestLanguage(r *http.Request) string {
+	if sess, ok := r.Context().Value(sessionKey{}).(*sessions.Session); ok {
+		if _, ok = messages[sess.Language]; ok {
+			return sess.Language
+		}
+	}
+	return messages.AcceptLanguage(r.Header.Get("Accept-Language"))
+}
+
+// internalError пишет ошибку err в лог и отвечает 500: подробности сбоя базы или Redis клиенту не нужны.
+func internalError(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, err error) {
+	logger.Errorln(err.Error())
+	httpError(w, r, ErrInternal, http.StatusInternalServerError)
+}
+
+// requestError пишет ошибку репозитория err в лог и отвечает 400, как эти обработчики отвечали всегда,
+// но без подробностей сбоя.
+func requestError(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, err error) {
+	logger.Errorln(err.Error())
+	httpError(w, r, ErrRequestFailed, http.StatusBadRequest)
+}
+
+// httpError отвечает кодом status и ошибкой {"message": "..."} с сообщением key на языке запроса.
+func httpError(w http.ResponseWriter, r *http.Request, key string, status int) {
+	resp, _ := json.Marshal(map[string]string{"message": messages.T(requestLanguage(r), key)})
+	http.Error(w, string(resp), status)
+}
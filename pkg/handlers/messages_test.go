@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"rutubeTest/pkg/sessions"
+	"testing"
+)
+
+func TestHTTPError(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		session        *sessions.Session
+		wantMessage    string
+	}{
+		{
+			name:           "Английский",
+			acceptLanguage: "en-US,en;q=0.9",
+			wantMessage:    "user not found",
+		},
+		{
+			name:           "Русский важнее по весу",
+			acceptLanguage: "en;q=0.5,ru",
+			wantMessage:    "пользователь не найден",
+		},
+		{
+			name:        "Без заголовка - язык по умолчанию",
+			wantMessage: "пользователь не найден",
+		},
+		{
+			name:           "Язык из сессии важнее заголовка",
+			acceptLanguage: "ru",
+			session:        &sessions.Session{ID: 1, Language: "en"},
+			wantMessage:    "user not found",
+		},
+		{
+			name:           "Язык в учётной записи не выбран",
+			acceptLanguage: "en",
+			session:        &sessions.Session{ID: 1},
+			wantMessage:    "user not found",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/users", nil)
+			if tc.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tc.acceptLanguage)
+			}
+			if tc.session != nil {
+				req = withSession(req, tc.session)
+			}
+			w := httptest.NewRecorder()
+
+			httpError(w, req, ErrUserNotFound, http.StatusUnauthorized)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			var body map[string]string
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, tc.wantMessage, body["message"])
+		})
+	}
+}
+
+func TestMessages(t *testing.T) {
+	assert.Empty(t, messages.Missing())
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"rutubeTest/pkg/sessions"
+	"rutubeTest/pkg/user"
+	"strings"
+)
+
+type RemindersForm struct {
+	UserID    int64 `json:"userID"  validate:"required"`
+	Reminders []int `json:"reminders"  validate:"required"`
+}
+
+// SetReminders задаёт, за сколько дней до дня рождения userID напоминать автору запроса.
+// Автор запроса должен быть подписан на userID.
+func (h *UserHandler) SetReminders(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Infoln("Start authorization")
+
+	token := r.Header.Get("Authorization")
+	if !strings.HasPrefix(token, "Bearer ") {
+		httpError(w, r, ErrUserNotFound, http.StatusUnauthorized)
+		return
+	}
+
+	sess := h.Sessions.Check(&sessions.SessionID{ID: token[7:]})
+	if sess == nil {
+		httpError(w, r, ErrUserNotFound, http.StatusUnauthorized)
+		return
+	}
+	r = withSession(r, sess)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		httpError(w, r, ErrReading, http.StatusBadRequest)
+		return
+	}
+	r.Body.Close()
+
+	rf := &RemindersForm{}
+	if err = json.Unmarshal(body, rf); err != nil {
+		httpError(w, r, ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	h.Logger.Infoln("User data unmarshalled")
+
+	// Валидация предоставленных данных.
+	errs := dataValidation(requestLanguage(r), rf)
+	if len(errs) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		err = json.NewEncoder(w).Encode(map[string][]map[string]string{"errors": errs})
+		if err != nil {
+			h.Logger.Errorln(err.Error())
+		}
+		return
+	}
+
+	h.Logger.Infoln("User data validated")
+
+	reminders, err := h.UserRepo.SetReminders(rf.UserID, sess.ID, rf.Reminders)
+	switch {
+	case errors.Is(err, user.ErrBadReminders):
+		httpError(w, r, ErrBadReminders, http.StatusBadRequest)
+		return
+	case errors.Is(err, user.ErrNoSubscription):
+		httpError(w, r, ErrNoSubscription, http.StatusNotFound)
+		return
+	case err != nil:
+		requestError(w, r, h.Logger, err)
+		return
+	}
+
+	resp, err := json.Marshal(RemindersForm{UserID: rf.UserID, Reminders: reminders})
+	if err != nil {
+		internalError(w, r, h.Logger, err)
+		return
+	}
+
+	_, err = w.Write(resp)
+	if err != nil {
+		h.Logger.Errorln(err.Error())
+		return
+	}
+	h.Logger.Infoln("Response sent")
+}
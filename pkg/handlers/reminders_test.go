@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rutubeTest/pkg/sessions"
+	"rutubeTest/pkg/user"
+	"testing"
+)
+
+func TestSetRemindersHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+	mockSessions := sessions.NewMockSessionManagerInterface(ctrl)
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		fmt.Println("Got err when making")
+		return
+	}
+
+	service := &UserHandler{
+		UserRepo: mockRepo,
+		Logger:   logger.Sugar(),
+		Sessions: mockSessions,
+	}
+
+	tests := []struct {
+		name          string
+		setupMocks    func()
+		authHeader    string
+		requestBody   interface{}
+		wantStatus    int
+		wantReminders []int
+	}{
+		{
+			name: "Напоминания заданы",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				mockRepo.EXPECT().SetReminders(int64(2), int64(1), []int{0, 7, 1}).Return([]int{7, 1, 0}, nil)
+			},
+			authHeader:    "Bearer validToken",
+			requestBody:   &RemindersForm{UserID: 2, Reminders: []int{0, 7, 1}},
+			wantStatus:    http.StatusOK,
+			wantReminders: []int{7, 1, 0},
+		},
+		{
+			name: "Некорректные смещения",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				mockRepo.EXPECT().SetReminders(int64(2), int64(1), []int{45}).Return(nil, user.ErrBadReminders)
+			},
+			authHeader:  "Bearer validToken",
+			requestBody: &RemindersForm{UserID: 2, Reminders: []int{45}},
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name: "Нет подписки",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				mockRepo.EXPECT().SetReminders(int64(2), int64(1), []int{1}).Return(nil, user.ErrNoSubscription)
+			},
+			authHeader:  "Bearer validToken",
+			requestBody: &RemindersForm{UserID: 2, Reminders: []int{1}},
+			wantStatus:  http.StatusNotFound,
+		},
+		{
+			name: "Не указан пользователь",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+			},
+			authHeader:  "Bearer validToken",
+			requestBody: &RemindersForm{Reminders: []int{1}},
+			wantStatus:  http.StatusUnprocessableEntity,
+		},
+		{
+			name: "Некорректный JSON",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+			},
+			authHeader:  "Bearer validToken",
+			requestBody: "invalid",
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "Неверный токен авторизации",
+			setupMocks:  func() {},
+			authHeader:  "invalidToken",
+			requestBody: &RemindersForm{UserID: 2, Reminders: []int{1}},
+			wantStatus:  http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			body, err := json.Marshal(tc.requestBody)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest("POST", "/api/reminders", bytes.NewReader(body))
+			req.Header.Add("Authorization", tc.authHeader)
+
+			w := httptest.NewRecorder()
+
+			service.SetReminders(w, req)
+
+			resp := w.Result()
+			assert.Equal(t, tc.wantStatus, resp.StatusCode)
+
+			if tc.wantStatus == http.StatusOK {
+				var result RemindersForm
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+				assert.Equal(t, tc.wantReminders, result.Reminders)
+			}
+		})
+	}
+}
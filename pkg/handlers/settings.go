@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"rutubeTest/pkg/sessions"
+	"rutubeTest/pkg/user"
+	"strings"
+)
+
+// SaveSettings заменяет настройки напоминаний автора запроса. Поля, которых нет в запросе, получают значения по умолчанию.
+func (h *UserHandler) SaveSettings(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Infoln("Start authorization")
+
+	token := r.Header.Get("Authorization")
+	if !strings.HasPrefix(token, "Bearer ") {
+		httpError(w, r, ErrUserNotFound, http.StatusUnauthorized)
+		return
+	}
+
+	sess := h.Sessions.Check(&sessions.SessionID{ID: token[7:]})
+	if sess == nil {
+		httpError(w, r, ErrUserNotFound, http.StatusUnauthorized)
+		return
+	}
+	r = withSession(r, sess)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		httpError(w, r, ErrReading, http.StatusBadRequest)
+		return
+	}
+	r.Body.Close()
+
+	settings := user.DefaultSettings()
+	if err = json.Unmarshal(body, &settings); err != nil {
+		httpError(w, r, ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	h.Logger.Infoln("User data unmarshalled")
+
+	err = h.UserRepo.SaveSettings(sess.ID, settings)
+	switch {
+	case errors.Is(err, user.ErrBadSettings):
+		httpError(w, r, ErrBadSettings, http.StatusBadRequest)
+		return
+	case err != nil:
+		internalError(w, r, h.Logger, err)
+		return
+	}
+
+	resp, err := json.Marshal(settings)
+	if err != nil {
+		internalError(w, r, h.Logger, err)
+		return
+	}
+
+	_, err = w.Write(resp)
+	if err != nil {
+		h.Logger.Errorln(err.Error())
+		return
+	}
+	h.Logger.Infoln("Response sent")
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"rutubeTest/pkg/sessions"
+	"rutubeTest/pkg/user"
+	"testing"
+)
+
+func TestSaveSettingsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+	mockSessions := sessions.NewMockSessionManagerInterface(ctrl)
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		fmt.Println("Got err when making")
+		return
+	}
+
+	service := &UserHandler{
+		UserRepo: mockRepo,
+		Logger:   logger.Sugar(),
+		Sessions: mockSessions,
+	}
+
+	tests := []struct {
+		name         string
+		setupMocks   func()
+		authHeader   string
+		requestBody  interface{}
+		wantStatus   int
+		wantSettings *user.Settings
+	}{
+		{
+			name: "Настройки сохранены",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				mockRepo.EXPECT().SaveSettings(int64(1), user.Settings{NotifyHour: 8, QuietFrom: 22, QuietTo: 7, Digest: true}).Return(nil)
+			},
+			authHeader:   "Bearer validToken",
+			requestBody:  &user.Settings{NotifyHour: 8, QuietFrom: 22, QuietTo: 7, Digest: true},
+			wantStatus:   http.StatusOK,
+			wantSettings: &user.Settings{NotifyHour: 8, QuietFrom: 22, QuietTo: 7, Digest: true},
+		},
+		{
+			name: "Не указанные поля получают значения по умолчанию",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				mockRepo.EXPECT().SaveSettings(int64(1), user.Settings{NotifyHour: user.DefaultHour, MutedUntil: "2024-07-01"}).Return(nil)
+			},
+			authHeader:   "Bearer validToken",
+			requestBody:  map[string]string{"mutedUntil": "2024-07-01"},
+			wantStatus:   http.StatusOK,
+			wantSettings: &user.Settings{NotifyHour: user.DefaultHour, MutedUntil: "2024-07-01"},
+		},
+		{
+			name: "Неверные настройки",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				mockRepo.EXPECT().SaveSettings(int64(1), user.Settings{NotifyHour: 30}).Return(user.ErrBadSettings)
+			},
+			authHeader:  "Bearer validToken",
+			requestBody: &user.Settings{NotifyHour: 30},
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name: "Ошибка базы данных",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				mockRepo.EXPECT().SaveSettings(int64(1), gomock.Any()).Return(fmt.Errorf("database error"))
+			},
+			authHeader:  "Bearer validToken",
+			requestBody: &user.Settings{NotifyHour: 8},
+			wantStatus:  http.StatusInternalServerError,
+		},
+		{
+			name: "Некорректный JSON",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+			},
+			authHeader:  "Bearer validToken",
+			requestBody: "invalid",
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name: "Сессия не найдена",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(nil)
+			},
+			authHeader:  "Bearer expiredToken",
+			requestBody: &user.Settings{NotifyHour: 8},
+			wantStatus:  http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			body, err := json.Marshal(tc.requestBody)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest("PUT", "/api/me/settings", bytes.NewReader(body))
+			req.Header.Add("Authorization", tc.authHeader)
+
+			w := httptest.NewRecorder()
+
+			service.SaveSettings(w, req)
+
+			resp := w.Result()
+			assert.Equal(t, tc.wantStatus, resp.StatusCode)
+			if tc.wantSettings != nil {
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(t, err)
+				var got user.Settings
+				assert.NoError(t, json.Unmarshal(data, &got))
+				assert.Equal(t, *tc.wantSettings, got)
+			}
+		})
+	}
+}
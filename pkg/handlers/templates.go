@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"rutubeTest/pkg/greeting"
+	"rutubeTest/pkg/sessions"
+	"strconv"
+	"strings"
+)
+
+const (
+	ErrForbidden        = "forbidden"
+	ErrNoTemplate       = "noTemplate"
+	ErrBadTemplate      = "badTemplate"
+	ErrUnknownKind      = "unknownKind"
+	ErrTemplateLanguage = "templateLanguage"
+	ErrEmptyTemplate    = "emptyTemplate"
+)
+
+// TemplateHandler - API управления шаблонами напоминаний. Он доступен только администраторам.
+type TemplateHandler struct {
+	Templates greeting.TemplateRepo
+	Logger    *zap.SugaredLogger
+	Sessions  sessions.SessionManagerInterface
+	AdminIDs  []int64
+}
+
+// PreviewForm - запрос предпросмотра. Если Text задан, подставляются данные в него, иначе в шаблон,
+// который выбирается для Kind, Language и Team. Если Data не задана, используется greeting.SampleData.
+type PreviewForm struct {
+	Kind     string         `json:"kind"`
+	Language string         `json:"language"`
+	Team     string         `json:"team"`
+	Text     string         `json:"text"`
+	Data     *greeting.Data `json:"data"`
+}
+
+// GetTemplates отдаёт все сохранённые шаблоны.
+func (h *TemplateHandler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	r, ok := h.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	templates, err := h.Templates.GetTemplates()
+	if err != nil {
+		internalError(w, r, h.Logger, err)
+		return
+	}
+
+	h.writeJSON(w, r, templates)
+}
+
+// SaveTemplate сохраняет шаблон; шаблон того же вида, языка и отдела заменяется.
+func (h *TemplateHandler) SaveTemplate(w http.ResponseWriter, r *http.Request) {
+	r, ok := h.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		httpError(w, r, ErrReading, http.StatusBadRequest)
+		return
+	}
+	r.Body.Close()
+
+	var t greeting.Template
+	if err = json.Unmarshal(body, &t); err != nil {
+		httpError(w, r, ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	h.Logger.Infoln("Template unmarshalled")
+
+	saved, err := h.Templates.SaveTemplate(t)
+	switch {
+	case errors.Is(err, greeting.ErrBadTemplate):
+		h.badTemplate(w, r, err)
+		return
+	case err != nil:
+		internalError(w, r, h.Logger, err)
+		return
+	}
+
+	h.writeJSON(w, r, saved)
+}
+
+// DeleteTemplate удаляет шаблон с id из параметра запроса id.
+func (h *TemplateHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	r, ok := h.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		httpError(w, r, ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	err = h.Templates.DeleteTemplate(id)
+	switch {
+	case errors.Is(err, greeting.ErrNoTemplate):
+		httpError(w, r, ErrNoTemplate, http.StatusNotFound)
+		return
+	case err != nil:
+		internalError(w, r, h.Logger, err)
+		return
+	}
+
+	h.writeJSON(w, r, map[string]int64{"id": id})
+}
+
+// PreviewTemplate отдаёт текст напоминания, не сохраняя шаблон.
+func (h *TemplateHandler) PreviewTemplate(w http.ResponseWriter, r *http.Request) {
+	r, ok := h.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		httpError(w, r, ErrReading, http.StatusBadRequest)
+		return
+	}
+	r.Body.Close()
+
+	pf := &PreviewForm{}
+	if err = json.Unmarshal(body, pf); err != nil {
+		httpError(w, r, ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	data := greeting.SampleData
+	if pf.Data != nil {
+		data = *pf.Data
+	}
+
+	var text string
+	if pf.Text != "" {
+		text, err = greeting.Render(pf.Text, pf.Language, data)
+	} else {
+		var templates []greeting.Template
+		templates, err = h.Templates.GetTemplates()
+		if err != nil {
+			internalError(w, r, h.Logger, err)
+			return
+		}
+		text, err = greeting.NewCatalog(templates).Render(pf.Kind, pf.Language, pf.Team, data)
+	}
+	switch {
+	case errors.Is(err, greeting.ErrNoTemplate):
+		httpError(w, r, ErrNoTemplate, http.StatusNotFound)
+		return
+	case err != nil:
+		h.badTemplate(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, r, map[string]string{"text": text})
+}
+
+// authorize проверяет, что запрос прислал администратор, и отвечает ошибкой, если это не так.
+// Возвращает запрос с сессией автора, чтобы дальнейшие ответы были на его языке.
+func (h *TemplateHandler) authorize(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	h.Logger.Infoln("Start authorization")
+
+	token := r.Header.Get("Authorization")
+	if !strings.HasPrefix(token, "Bearer ") {
+		httpError(w, r, ErrUserNotFound, http.StatusUnauthorized)
+		return r, false
+	}
+
+	sess := h.Sessions.Check(&sessions.SessionID{ID: token[7:]})
+	if sess == nil {
+		httpError(w, r, ErrUserNotFound, http.StatusUnauthorized)
+		return r, false
+	}
+	r = withSession(r, sess)
+
+	for _, id := range h.AdminIDs {
+		if id == sess.ID {
+			return r, true
+		}
+	}
+	httpError(w, r, ErrForbidden, http.StatusForbidden)
+	return r, false
+}
+
+// badTemplate отвечает 400 с причиной, по которой шаблон не подошёл. Ошибка разбора шаблона
+// пишется в лог: её текст на английском и не для пользователя.
+func (h *TemplateHandler) badTemplate(w http.ResponseWriter, r *http.Request, err error) {
+	h.Logger.Infoln("Bad template:", err)
+
+	key := ErrBadTemplate
+	switch {
+	case errors.Is(err, greeting.ErrUnknownKind):
+		key = ErrUnknownKind
+	case errors.Is(err, greeting.ErrTemplateLanguage):
+		key = ErrTemplateLanguage
+	case errors.Is(err, greeting.ErrEmptyTemplate):
+		key = ErrEmptyTemplate
+	}
+	httpError(w, r, key, http.StatusBadRequest)
+}
+
+func (h *TemplateHandler) writeJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	resp, err := json.Marshal(v)
+	if err != nil {
+		internalError(w, r, h.Logger, err)
+		return
+	}
+
+	_, err = w.Write(resp)
+	if err != nil {
+		h.Logger.Errorln(err.Error())
+		return
+	}
+	h.Logger.Infoln("Response sent")
+}
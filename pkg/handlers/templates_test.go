@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"rutubeTest/pkg/greeting"
+	"rutubeTest/pkg/sessions"
+	"testing"
+)
+
+func newTemplateHandler(t *testing.T, ctrl *gomock.Controller) (*TemplateHandler, *greeting.MockTemplateRepo, *sessions.MockSessionManagerInterface) {
+	mockRepo := greeting.NewMockTemplateRepo(ctrl)
+	mockSessions := sessions.NewMockSessionManagerInterface(ctrl)
+	logger, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+
+	return &TemplateHandler{
+		Templates: mockRepo,
+		Logger:    logger.Sugar(),
+		Sessions:  mockSessions,
+		AdminIDs:  []int64{1},
+	}, mockRepo, mockSessions
+}
+
+func TestGetTemplatesHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, mockRepo, mockSessions := newTemplateHandler(t, ctrl)
+
+	tests := []struct {
+		name       string
+		setupMocks func()
+		authHeader string
+		wantStatus int
+	}{
+		{
+			name: "Шаблоны получены",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				mockRepo.EXPECT().GetTemplates().Return([]greeting.Template{{ID: 1, Kind: greeting.KindToday, Language: "ru", Text: "{{.Name}}"}}, nil)
+			},
+			authHeader: "Bearer validToken",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Не администратор",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 2})
+			},
+			authHeader: "Bearer validToken",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Нет токена",
+			setupMocks: func() {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "Ошибка базы данных",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				mockRepo.EXPECT().GetTemplates().Return(nil, fmt.Errorf("database error"))
+			},
+			authHeader: "Bearer validToken",
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			req := httptest.NewRequest("GET", "/api/templates", nil)
+			req.Header.Add("Authorization", tc.authHeader)
+			w := httptest.NewRecorder()
+
+			service.GetTemplates(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Result().StatusCode)
+		})
+	}
+}
+
+func TestSaveTemplateHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, mockRepo, mockSessions := newTemplateHandler(t, ctrl)
+	tmpl := greeting.Template{Kind: greeting.KindToday, Language: "en", Team: "QA", Text: "Happy birthday, {{.FirstName}}!"}
+
+	tests := []struct {
+		name        string
+		setupMocks  func()
+		requestBody interface{}
+		wantStatus  int
+		wantMessage string
+	}{
+		{
+			name: "Шаблон сохранён",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				saved := tmpl
+				saved.ID = 5
+				mockRepo.EXPECT().SaveTemplate(tmpl).Return(&saved, nil)
+			},
+			requestBody: tmpl,
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name: "Неверный шаблон",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				mockRepo.EXPECT().SaveTemplate(gomock.Any()).Return(nil, fmt.Errorf("%w %q", greeting.ErrUnknownKind, "party"))
+			},
+			requestBody: greeting.Template{Kind: "party", Language: "en", Text: "{{.Name}}"},
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: messages.T("ru", ErrUnknownKind),
+		},
+		{
+			name: "Ошибка разбора не уходит клиенту",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				mockRepo.EXPECT().SaveTemplate(gomock.Any()).Return(nil, fmt.Errorf("%w: template: t:1: unexpected \"}\" in operand", greeting.ErrBadTemplate))
+			},
+			requestBody: greeting.Template{Kind: "today", Language: "en", Text: "{{.Name}"},
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: messages.T("ru", ErrBadTemplate),
+		},
+		{
+			name: "Ошибка репозитория",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				mockRepo.EXPECT().SaveTemplate(gomock.Any()).Return(nil, fmt.Errorf("database error"))
+			},
+			requestBody: tmpl,
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: messages.T("ru", ErrInternal),
+		},
+		{
+			name: "Некорректный JSON",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+			},
+			requestBody: "invalid",
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name: "Не администратор",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 2})
+			},
+			requestBody: tmpl,
+			wantStatus:  http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			body, err := json.Marshal(tc.requestBody)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest("PUT", "/api/templates", bytes.NewReader(body))
+			req.Header.Add("Authorization", "Bearer validToken")
+			w := httptest.NewRecorder()
+
+			service.SaveTemplate(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Result().StatusCode)
+			if tc.wantMessage != "" {
+				var resp map[string]string
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Equal(t, tc.wantMessage, resp["message"])
+			}
+		})
+	}
+}
+
+func TestDeleteTemplateHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, mockRepo, mockSessions := newTemplateHandler(t, ctrl)
+
+	tests := []struct {
+		name       string
+		setupMocks func()
+		query      string
+		wantStatus int
+	}{
+		{
+			name: "Шаблон удалён",
+			setupMocks: func() {
+				mockRepo.EXPECT().DeleteTemplate(int64(3)).Return(nil)
+			},
+			query:      "?id=3",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Шаблон не найден",
+			setupMocks: func() {
+				mockRepo.EXPECT().DeleteTemplate(int64(3)).Return(greeting.ErrNoTemplate)
+			},
+			query:      "?id=3",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Неверный id",
+			setupMocks: func() {},
+			query:      "?id=abc",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+			tc.setupMocks()
+
+			req := httptest.NewRequest("DELETE", "/api/templates"+tc.query, nil)
+			req.Header.Add("Authorization", "Bearer validToken")
+			w := httptest.NewRecorder()
+
+			service.DeleteTemplate(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Result().StatusCode)
+		})
+	}
+}
+
+func TestPreviewTemplateHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, mockRepo, mockSessions := newTemplateHandler(t, ctrl)
+
+	tests := []struct {
+		name        string
+		setupMocks  func()
+		requestBody interface{}
+		wantStatus  int
+		wantText    string
+	}{
+		{
+			name:        "Предпросмотр текста на примере",
+			setupMocks:  func() {},
+			requestBody: PreviewForm{Text: "{{.FirstName}}, {{.Age}}"},
+			wantStatus:  http.StatusOK,
+			wantText:    "Иван, 30",
+		},
+		{
+			name: "Предпросмотр шаблона из цепочки",
+			setupMocks: func() {
+				mockRepo.EXPECT().GetTemplates().Return([]greeting.Template{
+					{Kind: greeting.KindSoon, Language: "ru", Text: "{{.Name}} через {{.DaysUntil}} дн."},
+				}, nil)
+			},
+			requestBody: PreviewForm{Kind: greeting.KindSoon, Language: "en", Team: "QA", Data: &greeting.Data{Name: "John Doe", DaysUntil: 2, Date: "12.06"}},
+			wantStatus:  http.StatusOK,
+			wantText:    "In 2 days (12.06) it's John Doe's birthday. Time to get a present!",
+		},
+		{
+			name:        "Ошибка в шаблоне",
+			setupMocks:  func() {},
+			requestBody: PreviewForm{Text: "{{.Name"},
+			wantStatus:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+			tc.setupMocks()
+
+			body, err := json.Marshal(tc.requestBody)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest("POST", "/api/templates/preview", bytes.NewReader(body))
+			req.Header.Add("Authorization", "Bearer validToken")
+			w := httptest.NewRecorder()
+
+			service.PreviewTemplate(w, req)
+
+			resp := w.Result()
+			assert.Equal(t, tc.wantStatus, resp.StatusCode)
+			if tc.wantText != "" {
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(t, err)
+				var got map[string]string
+				assert.NoError(t, json.Unmarshal(data, &got))
+				assert.Equal(t, tc.wantText, got["text"])
+			}
+		})
+	}
+}
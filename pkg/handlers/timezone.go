@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"rutubeTest/pkg/sessions"
+	"rutubeTest/pkg/user"
+	"strings"
+)
+
+type TimeZoneForm struct {
+	TimeZone string `json:"timezone"`
+}
+
+// SetTimeZone задаёт часовой пояс автора запроса. Пустой timezone возвращает часовой пояс сервиса.
+func (h *UserHandler) SetTimeZone(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Infoln("Start authorization")
+
+	token := r.Header.Get("Authorization")
+	if !strings.HasPrefix(token, "Bearer ") {
+		httpError(w, r, ErrUserNotFound, http.StatusUnauthorized)
+		return
+	}
+
+	sess := h.Sessions.Check(&sessions.SessionID{ID: token[7:]})
+	if sess == nil {
+		httpError(w, r, ErrUserNotFound, http.StatusUnauthorized)
+		return
+	}
+	r = withSession(r, sess)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		httpError(w, r, ErrReading, http.StatusBadRequest)
+		return
+	}
+	r.Body.Close()
+
+	tf := &TimeZoneForm{}
+	if err = json.Unmarshal(body, tf); err != nil {
+		httpError(w, r, ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	h.Logger.Infoln("User data unmarshalled")
+
+	err = h.UserRepo.SetTimeZone(sess.ID, tf.TimeZone)
+	switch {
+	case errors.Is(err, user.ErrBadTimeZone):
+		httpError(w, r, ErrBadTimeZone, http.StatusBadRequest)
+		return
+	case err != nil:
+		requestError(w, r, h.Logger, err)
+		return
+	}
+
+	resp, err := json.Marshal(tf)
+	if err != nil {
+		internalError(w, r, h.Logger, err)
+		return
+	}
+
+	_, err = w.Write(resp)
+	if err != nil {
+		h.Logger.Errorln(err.Error())
+		return
+	}
+	h.Logger.Infoln("Response sent")
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rutubeTest/pkg/sessions"
+	"rutubeTest/pkg/user"
+	"testing"
+)
+
+func TestSetTimeZoneHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := user.NewMockUserRepo(ctrl)
+	mockSessions := sessions.NewMockSessionManagerInterface(ctrl)
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		fmt.Println("Got err when making")
+		return
+	}
+
+	service := &UserHandler{
+		UserRepo: mockRepo,
+		Logger:   logger.Sugar(),
+		Sessions: mockSessions,
+	}
+
+	tests := []struct {
+		name        string
+		setupMocks  func()
+		authHeader  string
+		requestBody interface{}
+		wantStatus  int
+	}{
+		{
+			name: "Часовой пояс задан",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				mockRepo.EXPECT().SetTimeZone(int64(1), "Asia/Vladivostok").Return(nil)
+			},
+			authHeader:  "Bearer validToken",
+			requestBody: &TimeZoneForm{TimeZone: "Asia/Vladivostok"},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name: "Неизвестный часовой пояс",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+				mockRepo.EXPECT().SetTimeZone(int64(1), "Mars/Olympus").Return(user.ErrBadTimeZone)
+			},
+			authHeader:  "Bearer validToken",
+			requestBody: &TimeZoneForm{TimeZone: "Mars/Olympus"},
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name: "Некорректный JSON",
+			setupMocks: func() {
+				mockSessions.EXPECT().Check(gomock.Any()).Return(&sessions.Session{ID: 1})
+			},
+			authHeader:  "Bearer validToken",
+			requestBody: "invalid",
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "Неверный токен авторизации",
+			setupMocks:  func() {},
+			authHeader:  "invalidToken",
+			requestBody: &TimeZoneForm{TimeZone: "Asia/Vladivostok"},
+			wantStatus:  http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			body, err := json.Marshal(tc.requestBody)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest("POST", "/api/timezone", bytes.NewReader(body))
+			req.Header.Add("Authorization", tc.authHeader)
+
+			w := httptest.NewRecorder()
+
+			service.SetTimeZone(w, req)
+
+			resp := w.Result()
+			assert.Equal(t, tc.wantStatus, resp.StatusCode)
+		})
+	}
+}
@@ -2,27 +2,41 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 	"io"
-	"log"
 	"net/http"
 	"rutubeTest/pkg/sessions"
 	"rutubeTest/pkg/user"
 	"strings"
 )
 
+// Ошибки API - ключи сообщений из messages.
 const (
-	ErrReading      = `{"message": "error reading request"}`
-	ErrUserNotFound = `{"message":"user not found"}`
-	ErrInvalidPass  = `{"message":"invalid password"}`
-	ErrBadRequest   = `{"message": "bad request"}`
+	ErrReading      = "reading"
+	ErrUserNotFound = "userNotFound"
+	ErrInvalidPass  = "invalidPass"
+	ErrBadRequest   = "badRequest"
+	ErrBadDays      = "badDays"
+
+	ErrBadReminders   = "badReminders"
+	ErrNoSubscription = "noSubscription"
+	ErrBadTimeZone    = "badTimeZone"
+	ErrBadSettings    = "badSettings"
+	ErrBadLanguage    = "badLanguage"
+	ErrBadGender      = "badGender"
+	ErrExists         = "exists"
+	ErrInternal       = "internal"
+	ErrRequestFailed  = "requestFailed"
 )
 
 type UserHandler struct {
 	UserRepo user.UserRepo
 	Logger   *zap.SugaredLogger
 	Sessions sessions.SessionManagerInterface
+	// LeapDay - когда поздравлять родившихся 29 февраля в невисокосный год.
+	LeapDay user.LeapDayPolicy
 }
 
 type AuthForm struct {
@@ -38,6 +52,10 @@ type RegForm struct {
 	Password   string `json:"password"  validate:"required"`
 	Birthday   string `json:"birthday"  validate:"required"`
 	Telegram   string `json:"telegram"  validate:"required"`
+	TimeZone   string `json:"timezone"`
+	Department string `json:"department"`
+	Language   string `json:"language"`
+	Gender     string `json:"gender"`
 }
 
 type SubscribeForm struct {
@@ -54,21 +72,21 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, ErrReading, http.StatusBadRequest)
+		httpError(w, r, ErrReading, http.StatusBadRequest)
 		return
 	}
 	r.Body.Close()
 
 	af := &AuthForm{}
 	if err = json.Unmarshal(body, af); err != nil {
-		http.Error(w, ErrBadRequest, http.StatusBadRequest)
+		httpError(w, r, ErrBadRequest, http.StatusBadRequest)
 		return
 	}
 
 	h.Logger.Infoln("User data unmarshalled")
 
 	// Валидация предоставленных данных
-	errors := dataValidation(af)
+	errors := dataValidation(requestLanguage(r), af)
 	if errors != nil {
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		err = json.NewEncoder(w).Encode(map[string][]map[string]string{"errors": errors})
@@ -82,21 +100,19 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	// Авторизация пользователя по предоставленным данным
 	u, err := h.UserRepo.Authorize(af.Username, af.Password)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	switch {
+	case err == user.ErrNoUser:
+		httpError(w, r, ErrUserNotFound, http.StatusBadRequest)
 		return
-	}
-
-	if err == user.ErrNoUser {
-		http.Error(w, ErrUserNotFound, http.StatusUnauthorized)
+	case err == user.ErrBadPass:
+		httpError(w, r, ErrInvalidPass, http.StatusBadRequest)
 		return
-	}
-	if err == user.ErrBadPass {
-		http.Error(w, ErrInvalidPass, http.StatusUnauthorized)
+	case err != nil:
+		requestError(w, r, h.Logger, err)
 		return
 	}
 	if u == nil {
-		http.Error(w, ErrBadRequest, http.StatusBadRequest)
+		httpError(w, r, ErrBadRequest, http.StatusBadRequest)
 		return
 	}
 
@@ -107,10 +123,10 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		ID:        u.ID,
 		Login:     u.Username,
 		Useragent: r.UserAgent(),
+		Language:  u.Language,
 	})
 	if err != nil {
-		log.Println("cant create session:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		internalError(w, r, h.Logger, fmt.Errorf("can't create session: %w", err))
 		return
 	}
 
@@ -118,7 +134,7 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		"session": sess.ID,
 	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		internalError(w, r, h.Logger, err)
 		return
 	}
 
@@ -135,21 +151,21 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, ErrReading, http.StatusBadRequest)
+		httpError(w, r, ErrReading, http.StatusBadRequest)
 		return
 	}
 	r.Body.Close()
 
 	rf := &RegForm{}
 	if err = json.Unmarshal(body, rf); err != nil {
-		http.Error(w, ErrBadRequest, http.StatusBadRequest)
+		httpError(w, r, ErrBadRequest, http.StatusBadRequest)
 		return
 	}
 
 	h.Logger.Infoln("User data unmarshalled")
 
 	// Валидация предоставленных данных.
-	errors := dataValidation(rf)
+	errors := dataValidation(requestLanguage(r), rf)
 	if len(errors) > 0 {
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		err = json.NewEncoder(w).Encode(map[string][]map[string]string{"errors": errors})
@@ -159,31 +175,43 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.Logger.Infoln("User data validated")
+	if err = user.CheckTimeZone(rf.TimeZone); err != nil {
+		httpError(w, r, ErrBadTimeZone, http.StatusBadRequest)
+		return
+	}
 
-	// Создание пользователя по предоставленным данным.
-	u, err := h.UserRepo.MakeUser(rf.Username, rf.Password, rf.FirstName, rf.MiddleName, rf.LastName, rf.Birthday, rf.Telegram)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err = user.CheckLanguage(rf.Language); err != nil {
+		httpError(w, r, ErrBadLanguage, http.StatusBadRequest)
 		return
 	}
 
-	// обработка ошибки, что юзер уже есть.
-	if err == user.ErrExists {
-		newError := map[string]string{
-			"location": "body",
-			"param":    rf.Username,
-			"msg":      "already exists",
-		}
-		errors = append(errors, newError)
+	if err = user.CheckGender(rf.Gender); err != nil {
+		httpError(w, r, ErrBadGender, http.StatusBadRequest)
+		return
 	}
 
-	if errors != nil {
-		w.WriteHeader(http.StatusUnprocessableEntity)
-		err = json.NewEncoder(w).Encode(map[string][]map[string]string{"errors": errors})
-		if err != nil {
-			h.Logger.Errorln(err.Error())
-		}
+	h.Logger.Infoln("User data validated")
+
+	// Создание пользователя по предоставленным данным.
+	u, err := h.UserRepo.MakeUser(user.User{
+		Username:   rf.Username,
+		Password:   rf.Password,
+		FirstName:  rf.FirstName,
+		MiddleName: rf.MiddleName,
+		LastName:   rf.LastName,
+		Birthday:   rf.Birthday,
+		Telegram:   rf.Telegram,
+		TimeZone:   rf.TimeZone,
+		Department: rf.Department,
+		Language:   rf.Language,
+		Gender:     rf.Gender,
+	})
+	switch {
+	case err == user.ErrExists:
+		httpError(w, r, ErrExists, http.StatusBadRequest)
+		return
+	case err != nil:
+		requestError(w, r, h.Logger, err)
 		return
 	}
 
@@ -194,10 +222,10 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 		ID:        u.ID,
 		Login:     u.Username,
 		Useragent: r.UserAgent(),
+		Language:  u.Language,
 	})
 	if err != nil {
-		log.Println("cant create session:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		internalError(w, r, h.Logger, fmt.Errorf("can't create session: %w", err))
 		return
 	}
 
@@ -205,7 +233,7 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 		"session": sess.ID,
 	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		internalError(w, r, h.Logger, err)
 		return
 	}
 
@@ -217,14 +245,14 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	h.Logger.Infoln("Response sent")
 }
 
-func dataValidation(fd interface{}) []map[string]string {
+func dataValidation(lang string, fd interface{}) []map[string]string {
 	if err := validator.New().Struct(fd); err != nil {
 		var newErrors []map[string]string
 		for _, someErr := range err.(validator.ValidationErrors) {
 			newError := map[string]string{
 				"location": "body",
 				"param":    strings.ToLower(someErr.StructField()),
-				"msg":      "is required",
+				"msg":      messages.T(lang, msgRequired),
 			}
 			newErrors = append(newErrors, newError)
 		}
@@ -239,19 +267,20 @@ func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
 
 	token := r.Header.Get("Authorization")
 	if !strings.HasPrefix(token, "Bearer ") {
-		http.Error(w, ErrUserNotFound, http.StatusUnauthorized)
+		httpError(w, r, ErrUserNotFound, http.StatusUnauthorized)
 		return
 	}
 
 	sess := h.Sessions.Check(&sessions.SessionID{ID: token[7:]})
 	if sess == nil {
-		http.Error(w, ErrUserNotFound, http.StatusUnauthorized)
+		httpError(w, r, ErrUserNotFound, http.StatusUnauthorized)
 		return
 	}
+	r = withSession(r, sess)
 
 	users, err := h.UserRepo.GetUsers()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		requestError(w, r, h.Logger, err)
 		return
 	}
 
@@ -259,7 +288,7 @@ func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := json.Marshal(users)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		internalError(w, r, h.Logger, err)
 		return
 	}
 
@@ -276,33 +305,34 @@ func (h *UserHandler) SubscribeToUser(w http.ResponseWriter, r *http.Request) {
 
 	token := r.Header.Get("Authorization")
 	if !strings.HasPrefix(token, "Bearer ") {
-		http.Error(w, ErrUserNotFound, http.StatusUnauthorized)
+		httpError(w, r, ErrUserNotFound, http.StatusUnauthorized)
 		return
 	}
 
 	sess := h.Sessions.Check(&sessions.SessionID{ID: token[7:]})
 	if sess == nil {
-		http.Error(w, ErrUserNotFound, http.StatusUnauthorized)
+		httpError(w, r, ErrUserNotFound, http.StatusUnauthorized)
 		return
 	}
+	r = withSession(r, sess)
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, ErrReading, http.StatusBadRequest)
+		httpError(w, r, ErrReading, http.StatusBadRequest)
 		return
 	}
 	r.Body.Close()
 
 	sf := &SubscribeForm{}
 	if err = json.Unmarshal(body, sf); err != nil {
-		http.Error(w, ErrBadRequest, http.StatusBadRequest)
+		httpError(w, r, ErrBadRequest, http.StatusBadRequest)
 		return
 	}
 
 	h.Logger.Infoln("User data unmarshalled")
 
 	// Валидация предоставленных данных.
-	errors := dataValidation(sf)
+	errors := dataValidation(requestLanguage(r), sf)
 	if len(errors) > 0 {
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		err = json.NewEncoder(w).Encode(map[string][]map[string]string{"errors": errors})
@@ -316,7 +346,7 @@ func (h *UserHandler) SubscribeToUser(w http.ResponseWriter, r *http.Request) {
 
 	_, err = h.UserRepo.Subscribe(sf.UserID, sf.SubscriberID, 1)
 	if err != nil {
-		http.Error(w, ErrBadRequest, http.StatusBadRequest)
+		httpError(w, r, ErrBadRequest, http.StatusBadRequest)
 		return
 	}
 }
@@ -326,33 +356,34 @@ func (h *UserHandler) UnsubscribeToUser(w http.ResponseWriter, r *http.Request)
 
 	token := r.Header.Get("Authorization")
 	if !strings.HasPrefix(token, "Bearer ") {
-		http.Error(w, ErrUserNotFound, http.StatusUnauthorized)
+		httpError(w, r, ErrUserNotFound, http.StatusUnauthorized)
 		return
 	}
 
 	sess := h.Sessions.Check(&sessions.SessionID{ID: token[7:]})
 	if sess == nil {
-		http.Error(w, ErrUserNotFound, http.StatusUnauthorized)
+		httpError(w, r, ErrUserNotFound, http.StatusUnauthorized)
 		return
 	}
+	r = withSession(r, sess)
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, ErrReading, http.StatusBadRequest)
+		httpError(w, r, ErrReading, http.StatusBadRequest)
 		return
 	}
 	r.Body.Close()
 
 	sf := &SubscribeForm{}
 	if err = json.Unmarshal(body, sf); err != nil {
-		http.Error(w, ErrBadRequest, http.StatusBadRequest)
+		httpError(w, r, ErrBadRequest, http.StatusBadRequest)
 		return
 	}
 
 	h.Logger.Infoln("User data unmarshalled")
 
 	// Валидация предоставленных данных.
-	errors := dataValidation(sf)
+	errors := dataValidation(requestLanguage(r), sf)
 	if len(errors) > 0 {
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		err = json.NewEncoder(w).Encode(map[string][]map[string]string{"errors": errors})
@@ -366,7 +397,7 @@ func (h *UserHandler) UnsubscribeToUser(w http.ResponseWriter, r *http.Request)
 
 	_, err = h.UserRepo.Subscribe(sf.UserID, sf.SubscriberID, 0)
 	if err != nil {
-		http.Error(w, ErrBadRequest, http.StatusBadRequest)
+		httpError(w, r, ErrBadRequest, http.StatusBadRequest)
 		return
 	}
 }
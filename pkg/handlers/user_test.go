@@ -191,8 +191,8 @@ func TestRegisterHandler(t *testing.T) {
 		{
 			name: "Успешный register",
 			setupMocks: func() {
-				mockRepo.EXPECT().MakeUser("validUser", "validPass", "firstname",
-					"middlename", "lastname", "2001-11-11", "@testuser").
+				mockRepo.EXPECT().MakeUser(user.User{Username: "validUser", Password: "validPass", FirstName: "firstname",
+					MiddleName: "middlename", LastName: "lastname", Birthday: "2001-11-11", Telegram: "@testuser"}).
 					Return(&user.User{}, nil)
 				mockSessions.EXPECT().Create(gomock.Any()).Return(&sessions.SessionID{ID: "session-id"}, nil)
 			},
@@ -216,11 +216,66 @@ func TestRegisterHandler(t *testing.T) {
 			wantStatus:  http.StatusUnprocessableEntity,
 			expectError: true,
 		},
+		{
+			name: "Регистрация с часовым поясом",
+			setupMocks: func() {
+				mockRepo.EXPECT().MakeUser(user.User{Username: "validUser", Password: "validPass", FirstName: "firstname",
+					MiddleName: "middlename", LastName: "lastname", Birthday: "2001-11-11", Telegram: "@testuser", TimeZone: "Asia/Vladivostok"}).
+					Return(&user.User{}, nil)
+				mockSessions.EXPECT().Create(gomock.Any()).Return(&sessions.SessionID{ID: "session-id"}, nil)
+			},
+			requestBody: map[string]string{"username": "validUser", "password": "validPass", "firstname": "firstname",
+				"middlename": "middlename", "lastname": "lastname", "birthday": "2001-11-11", "telegram": "@testuser",
+				"timezone": "Asia/Vladivostok"},
+			wantStatus:  http.StatusOK,
+			expectError: false,
+		},
+		{
+			name: "Регистрация с отделом, языком и родом",
+			setupMocks: func() {
+				mockRepo.EXPECT().MakeUser(user.User{Username: "validUser", Password: "validPass", FirstName: "firstname",
+					MiddleName: "middlename", LastName: "lastname", Birthday: "2001-11-11", Telegram: "@testuser", Department: "QA", Language: "en", Gender: "female"}).
+					Return(&user.User{}, nil)
+				mockSessions.EXPECT().Create(gomock.Any()).Return(&sessions.SessionID{ID: "session-id"}, nil)
+			},
+			requestBody: map[string]string{"username": "validUser", "password": "validPass", "firstname": "firstname",
+				"middlename": "middlename", "lastname": "lastname", "birthday": "2001-11-11", "telegram": "@testuser",
+				"department": "QA", "language": "en", "gender": "female"},
+			wantStatus:  http.StatusOK,
+			expectError: false,
+		},
+		{
+			name:       "Неизвестный род",
+			setupMocks: func() {},
+			requestBody: map[string]string{"username": "validUser", "password": "validPass", "firstname": "firstname",
+				"middlename": "middlename", "lastname": "lastname", "birthday": "2001-11-11", "telegram": "@testuser",
+				"gender": "robot"},
+			wantStatus:  http.StatusBadRequest,
+			expectError: true,
+		},
+		{
+			name:       "Неизвестный язык",
+			setupMocks: func() {},
+			requestBody: map[string]string{"username": "validUser", "password": "validPass", "firstname": "firstname",
+				"middlename": "middlename", "lastname": "lastname", "birthday": "2001-11-11", "telegram": "@testuser",
+				"language": "English"},
+			wantStatus:  http.StatusBadRequest,
+			expectError: true,
+		},
+		{
+			name:       "Неизвестный часовой пояс",
+			setupMocks: func() {},
+			requestBody: map[string]string{"username": "validUser", "password": "validPass", "firstname": "firstname",
+				"middlename": "middlename", "lastname": "lastname", "birthday": "2001-11-11", "telegram": "@testuser",
+				"timezone": "Mars/Olympus"},
+			wantStatus:  http.StatusBadRequest,
+			expectError: true,
+		},
 		{
 			name: "Проверка обработки ошибки при авторизации, что юзер уже есть",
 			setupMocks: func() {
-				mockRepo.EXPECT().MakeUser("invalidUser", "invalidPass", "firstname",
-					"middlename", "lastname", "2001-11-11", "@testuser").
+				mockRepo.EXPECT().MakeUser(user.User{Username: "invalidUser", Password: "invalidPass", FirstName: "firstname",
+					MiddleName: "middlename", LastName: "lastname", Birthday: "2001-11-11", Telegram: "@testuser"}).
 					Return(&user.User{}, nil).Return(nil, user.ErrExists)
 			},
 			requestBody: map[string]string{"username": "invalidUser", "password": "invalidPass", "firstname": "firstname",
@@ -231,8 +286,8 @@ func TestRegisterHandler(t *testing.T) {
 		{
 			name: "Обработка ошибки при создании сессии",
 			setupMocks: func() {
-				mockRepo.EXPECT().MakeUser("validUser", "validPass", "firstname",
-					"middlename", "lastname", "2001-11-11", "@testuser").
+				mockRepo.EXPECT().MakeUser(user.User{Username: "validUser", Password: "validPass", FirstName: "firstname",
+					MiddleName: "middlename", LastName: "lastname", Birthday: "2001-11-11", Telegram: "@testuser"}).
 					Return(&user.User{}, nil).Return(&user.User{}, nil)
 				mockSessions.EXPECT().Create(gomock.Any()).Return(nil, fmt.Errorf("session creation failed"))
 			},
@@ -244,8 +299,8 @@ func TestRegisterHandler(t *testing.T) {
 		{
 			name: "Обработка ошибки при создании ответа",
 			setupMocks: func() {
-				mockRepo.EXPECT().MakeUser("validUser", "validPass", "firstname",
-					"middlename", "lastname", "2001-11-11", "@testuser").
+				mockRepo.EXPECT().MakeUser(user.User{Username: "validUser", Password: "validPass", FirstName: "firstname",
+					MiddleName: "middlename", LastName: "lastname", Birthday: "2001-11-11", Telegram: "@testuser"}).
 					Return(&user.User{}, nil).Return(&user.User{}, nil)
 				mockSessions.EXPECT().Create(gomock.Any()).Return(&sessions.SessionID{ID: "session-id"}, nil)
 			},
@@ -0,0 +1,92 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage - язык, на который переводятся сообщения, если язык пользователя неизвестен
+// или перевода на него нет. На нём в каталоге должны быть все сообщения.
+const DefaultLanguage = "ru"
+
+// Messages - каталог сообщений: тексты по языку и ключу. Текст - формат fmt.Sprintf.
+type Messages map[string]map[string]string
+
+// T возвращает сообщение key на языке language с подставленными args. Если перевода на language нет,
+// берётся сообщение на DefaultLanguage, а если нет и его - сам key.
+func (m Messages) T(language, key string, args ...interface{}) string {
+	text, ok := m[language][key]
+	if !ok {
+		text, ok = m[DefaultLanguage][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Language возвращает первый из languages, на который в каталоге есть перевод, или DefaultLanguage.
+// Языки можно передавать тегами IETF, как language_code в телеграме: "en-US" даёт "en".
+func (m Messages) Language(languages ...string) string {
+	for _, language := range languages {
+		language = strings.ToLower(strings.TrimSpace(language))
+		if i := strings.IndexAny(language, "-_"); i != -1 {
+			language = language[:i]
+		}
+		if _, ok := m[language]; ok {
+			return language
+		}
+	}
+	return DefaultLanguage
+}
+
+// AcceptLanguage выбирает язык по заголовку HTTP Accept-Language, например "en-US,en;q=0.9,ru;q=0.8".
+// Языки перебираются по убыванию веса q.
+func (m Messages) AcceptLanguage(header string) string {
+	type tag struct {
+		language string
+		q        float64
+	}
+
+	var tags []tag
+	for _, part := range strings.Split(header, ",") {
+		language, params, _ := strings.Cut(part, ";")
+		t := tag{language: language, q: 1}
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			t.q = q
+		}
+		if t.q > 0 {
+			tags = append(tags, t)
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	languages := make([]string, 0, len(tags))
+	for _, t := range tags {
+		languages = append(languages, t.language)
+	}
+	return m.Language(languages...)
+}
+
+// Missing возвращает сообщения DefaultLanguage, которых нет в других языках каталога, в виде "en: key".
+func (m Messages) Missing() []string {
+	var missing []string
+	for language, texts := range m {
+		for key := range m[DefaultLanguage] {
+			if _, ok := texts[key]; !ok {
+				missing = append(missing, language+": "+key)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
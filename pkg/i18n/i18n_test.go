@@ -0,0 +1,64 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testMessages = Messages{
+	"ru": {"hello": "Привет, %s!", "bye": "Пока"},
+	"en": {"hello": "Hello, %s!"},
+}
+
+func TestT(t *testing.T) {
+	assert.Equal(t, "Hello, John!", testMessages.T("en", "hello", "John"))
+	assert.Equal(t, "Привет, Иван!", testMessages.T("ru", "hello", "Иван"))
+	assert.Equal(t, "Пока", testMessages.T("en", "bye"), "нет перевода - язык по умолчанию")
+	assert.Equal(t, "Привет, John!", testMessages.T("de", "hello", "John"), "неизвестный язык - язык по умолчанию")
+	assert.Equal(t, "unknown", testMessages.T("ru", "unknown"))
+}
+
+func TestLanguage(t *testing.T) {
+	tests := []struct {
+		name      string
+		languages []string
+		want      string
+	}{
+		{name: "Первый известный", languages: []string{"en", "ru"}, want: "en"},
+		{name: "Пустой пропускается", languages: []string{"", "en"}, want: "en"},
+		{name: "Тег с регионом", languages: []string{"en-US"}, want: "en"},
+		{name: "Регистр не важен", languages: []string{"EN_gb"}, want: "en"},
+		{name: "Неизвестный язык", languages: []string{"de"}, want: DefaultLanguage},
+		{name: "Без языков", want: DefaultLanguage},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, testMessages.Language(tc.languages...))
+		})
+	}
+}
+
+func TestAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "en-US,en;q=0.9,ru;q=0.8", want: "en"},
+		{header: "de-DE, ru;q=0.5, en;q=0.7", want: "en"},
+		{header: "en;q=0, ru", want: "ru"},
+		{header: "*", want: DefaultLanguage},
+		{header: "", want: DefaultLanguage},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.header, func(t *testing.T) {
+			assert.Equal(t, tc.want, testMessages.AcceptLanguage(tc.header))
+		})
+	}
+}
+
+func TestMissing(t *testing.T) {
+	assert.Equal(t, []string{"en: bye"}, testMessages.Missing())
+}
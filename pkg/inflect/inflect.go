@@ -0,0 +1,93 @@
+package inflect
+
+import (
+	"errors"
+	"strings"
+
+	"rutubeTest/pkg/user"
+)
+
+// Case - падеж.
+type Case string
+
+const (
+	Nominative    Case = "nom"
+	Genitive      Case = "gen"
+	Dative        Case = "dat"
+	Accusative    Case = "acc"
+	Instrumental  Case = "ins"
+	Prepositional Case = "pre"
+)
+
+// Part - часть полного имени.
+type Part int
+
+const (
+	FirstName Part = iota
+	MiddleName
+	LastName
+)
+
+var ErrBadCase = errors.New("unknown case")
+
+// cases - косвенные падежи в порядке Rule.Endings.
+var cases = []Case{Genitive, Dative, Accusative, Instrumental, Prepositional}
+
+// ParseCase проверяет название падежа.
+func ParseCase(s string) (Case, error) {
+	c := Case(s)
+	if c == Nominative {
+		return c, nil
+	}
+	for _, known := range cases {
+		if c == known {
+			return c, nil
+		}
+	}
+	return "", ErrBadCase
+}
+
+// Inflector склоняет часть имени человека рода gender (user.GenderMale, user.GenderFemale или пустая строка).
+type Inflector interface {
+	Inflect(word string, part Part, gender string, c Case) string
+}
+
+// Rule - правило склонения: у части имени Part рода Gender, которая оканчивается на Suffix, отрезаются
+// последние Cut букв и добавляются Endings в родительном, дательном, винительном, творительном и предложном падежах.
+// Пустой Gender подходит к любому роду.
+type Rule struct {
+	Part    Part
+	Gender  string
+	Suffix  string
+	Cut     int
+	Endings [5]string
+}
+
+// Rules - набор правил склонения. Применяется первое подходящее правило; если подходящего нет или род
+// не известен, слово не склоняется.
+type Rules []Rule
+
+func (rules Rules) Inflect(word string, part Part, gender string, c Case) string {
+	if word == "" || c == Nominative || gender == user.GenderUnknown {
+		return word
+	}
+	index := -1
+	for i, known := range cases {
+		if c == known {
+			index = i
+		}
+	}
+	if index < 0 {
+		return word
+	}
+
+	lower := strings.ToLower(word)
+	for _, r := range rules {
+		if r.Part != part || (r.Gender != "" && r.Gender != gender) || !strings.HasSuffix(lower, r.Suffix) {
+			continue
+		}
+		runes := []rune(word)
+		return string(runes[:len(runes)-r.Cut]) + r.Endings[index]
+	}
+	return word
+}
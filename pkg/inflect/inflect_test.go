@@ -0,0 +1,62 @@
+package inflect
+
+import (
+	"testing"
+
+	"rutubeTest/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRussian(t *testing.T) {
+	tests := []struct {
+		word     string
+		part     Part
+		gender   string
+		expected [5]string
+	}{
+		{"Иван", FirstName, user.GenderMale, [5]string{"Ивана", "Ивану", "Ивана", "Иваном", "Иване"}},
+		{"Андрей", FirstName, user.GenderMale, [5]string{"Андрея", "Андрею", "Андрея", "Андреем", "Андрее"}},
+		{"Василий", FirstName, user.GenderMale, [5]string{"Василия", "Василию", "Василия", "Василием", "Василии"}},
+		{"Игорь", FirstName, user.GenderMale, [5]string{"Игоря", "Игорю", "Игоря", "Игорем", "Игоре"}},
+		{"Никита", FirstName, user.GenderMale, [5]string{"Никиты", "Никите", "Никиту", "Никитой", "Никите"}},
+		{"Илья", FirstName, user.GenderMale, [5]string{"Ильи", "Илье", "Илью", "Ильей", "Илье"}},
+		{"Анна", FirstName, user.GenderFemale, [5]string{"Анны", "Анне", "Анну", "Анной", "Анне"}},
+		{"Ольга", FirstName, user.GenderFemale, [5]string{"Ольги", "Ольге", "Ольгу", "Ольгой", "Ольге"}},
+		{"Маша", FirstName, user.GenderFemale, [5]string{"Маши", "Маше", "Машу", "Машей", "Маше"}},
+		{"Мария", FirstName, user.GenderFemale, [5]string{"Марии", "Марии", "Марию", "Марией", "Марии"}},
+		{"Любовь", FirstName, user.GenderFemale, [5]string{"Любови", "Любови", "Любовь", "Любовью", "Любови"}},
+		{"Иванович", MiddleName, user.GenderMale, [5]string{"Ивановича", "Ивановичу", "Ивановича", "Ивановичем", "Ивановиче"}},
+		{"Сергеевна", MiddleName, user.GenderFemale, [5]string{"Сергеевны", "Сергеевне", "Сергеевну", "Сергеевной", "Сергеевне"}},
+		{"Петров", LastName, user.GenderMale, [5]string{"Петрова", "Петрову", "Петрова", "Петровым", "Петрове"}},
+		{"Петрова", LastName, user.GenderFemale, [5]string{"Петровой", "Петровой", "Петрову", "Петровой", "Петровой"}},
+		{"Достоевский", LastName, user.GenderMale, [5]string{"Достоевского", "Достоевскому", "Достоевского", "Достоевским", "Достоевском"}},
+		{"Толстая", LastName, user.GenderFemale, [5]string{"Толстой", "Толстой", "Толстую", "Толстой", "Толстой"}},
+		{"Шевчук", LastName, user.GenderMale, [5]string{"Шевчука", "Шевчуку", "Шевчука", "Шевчуком", "Шевчуке"}},
+		{"Шевчук", LastName, user.GenderFemale, [5]string{"Шевчук", "Шевчук", "Шевчук", "Шевчук", "Шевчук"}},
+		{"Черных", LastName, user.GenderMale, [5]string{"Черных", "Черных", "Черных", "Черных", "Черных"}},
+		{"Шевченко", LastName, user.GenderMale, [5]string{"Шевченко", "Шевченко", "Шевченко", "Шевченко", "Шевченко"}},
+		{"John", FirstName, user.GenderMale, [5]string{"John", "John", "John", "John", "John"}},
+		{"Иван", FirstName, user.GenderUnknown, [5]string{"Иван", "Иван", "Иван", "Иван", "Иван"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			var got [5]string
+			for i, c := range cases {
+				got[i] = Russian.Inflect(tt.word, tt.part, tt.gender, c)
+			}
+			assert.Equal(t, tt.expected, got)
+			assert.Equal(t, tt.word, Russian.Inflect(tt.word, tt.part, tt.gender, Nominative))
+		})
+	}
+}
+
+func TestParseCase(t *testing.T) {
+	c, err := ParseCase("dat")
+	assert.NoError(t, err)
+	assert.Equal(t, Dative, c)
+
+	_, err = ParseCase("dative")
+	assert.Equal(t, ErrBadCase, err)
+}
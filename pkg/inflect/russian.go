@@ -0,0 +1,89 @@
+package inflect
+
+import (
+	"rutubeTest/pkg/user"
+)
+
+// Окончания косвенных падежей: родительный, дательный, винительный, творительный, предложный.
+var (
+	hardMale    = [5]string{"а", "у", "а", "ом", "е"}        // Иван, Шевчук
+	hissMale    = [5]string{"а", "у", "а", "ем", "е"}        // Кузьмич, Иванович
+	softMale    = [5]string{"я", "ю", "я", "ем", "е"}        // Игорь, Андрей
+	iyMale      = [5]string{"ия", "ию", "ия", "ием", "ии"}   // Василий
+	hardA       = [5]string{"ы", "е", "у", "ой", "е"}        // Анна, Никита
+	velarA      = [5]string{"и", "е", "у", "ой", "е"}        // Ольга, Лука
+	hissA       = [5]string{"и", "е", "у", "ей", "е"}        // Маша, Саша
+	softA       = [5]string{"и", "е", "ю", "ей", "е"}        // Таня, Илья
+	iya         = [5]string{"ии", "ии", "ию", "ией", "ии"}   // Мария
+	softFemale  = [5]string{"и", "и", "ь", "ью", "и"}        // Любовь
+	possessive  = [5]string{"а", "у", "а", "ым", "е"}        // Иванов, Пушкин
+	possFemale  = [5]string{"ой", "ой", "у", "ой", "ой"}     // Иванова
+	adjSoftMale = [5]string{"ого", "ому", "ого", "им", "ом"} // Достоевский
+	adjHardMale = [5]string{"ого", "ому", "ого", "ым", "ом"} // Толстой, Белый
+	adjFemale   = [5]string{"ой", "ой", "ую", "ой", "ой"}    // Достоевская
+	unchanged   = [5]string{}                                // Черных, Шевченко
+)
+
+const (
+	hardConsonants = "бвгдзклмнпрстфх"
+	hissing        = "жцчшщ"
+	velars         = "гкх"
+	vowels         = "оеёиуюыэ"
+)
+
+// Russian - правила склонения русских имён, отчеств и фамилий. Слова, к которым не подошло ни одно
+// правило (например, имена на латинице), не склоняются.
+var Russian = russianRules()
+
+func russianRules() Rules {
+	var rules Rules
+	add := func(part Part, gender, suffix string, cut int, endings [5]string) {
+		rules = append(rules, Rule{Part: part, Gender: gender, Suffix: suffix, Cut: cut, Endings: endings})
+	}
+	each := func(letters string, f func(letter string)) {
+		for _, r := range letters {
+			f(string(r))
+		}
+	}
+	genders := []string{user.GenderMale, user.GenderFemale}
+
+	// Отчества.
+	add(MiddleName, user.GenderMale, "ич", 0, hissMale)
+	add(MiddleName, user.GenderFemale, "на", 1, hardA)
+
+	// Фамилии на -их, -ых и на гласные, кроме -а и -я, не склоняются; прилагательные и притяжательные
+	// склоняются по своим правилам; женские фамилии на согласную не склоняются.
+	for _, g := range genders {
+		add(LastName, g, "их", 0, unchanged)
+		add(LastName, g, "ых", 0, unchanged)
+	}
+	add(LastName, user.GenderMale, "ский", 2, adjSoftMale)
+	add(LastName, user.GenderMale, "цкий", 2, adjSoftMale)
+	add(LastName, user.GenderMale, "ой", 2, adjHardMale)
+	add(LastName, user.GenderMale, "ый", 2, adjHardMale)
+	add(LastName, user.GenderFemale, "ая", 2, adjFemale)
+	for _, s := range []string{"ов", "ев", "ёв", "ин", "ын"} {
+		add(LastName, user.GenderMale, s, 0, possessive)
+		add(LastName, user.GenderFemale, s+"а", 1, possFemale)
+	}
+	each(hardConsonants+hissing+"йь", func(l string) { add(LastName, user.GenderFemale, l, 0, unchanged) })
+
+	// Имена и остальные фамилии.
+	for _, part := range []Part{FirstName, LastName} {
+		for _, g := range genders {
+			add(part, g, "ия", 2, iya)
+			each(velars, func(l string) { add(part, g, l+"а", 1, velarA) })
+			each(hissing, func(l string) { add(part, g, l+"а", 1, hissA) })
+			add(part, g, "а", 1, hardA)
+			add(part, g, "я", 1, softA)
+			each(vowels, func(l string) { add(part, g, l, 0, unchanged) })
+		}
+		add(part, user.GenderMale, "ий", 2, iyMale)
+		add(part, user.GenderMale, "й", 1, softMale)
+		add(part, user.GenderMale, "ь", 1, softMale)
+		add(part, user.GenderFemale, "ь", 1, softFemale)
+		each(hissing, func(l string) { add(part, user.GenderMale, l, 0, hissMale) })
+		each(hardConsonants, func(l string) { add(part, user.GenderMale, l, 0, hardMale) })
+	}
+	return rules
+}
@@ -0,0 +1,18 @@
+package lock
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNotHeld = errors.New("lock is not held")
+
+// Locker - распределённая блокировка с TTL. Блокировка выдаётся с токеном, и продлить или снять её
+// может только владелец токена; если владелец пропал и не продлил её, она снимается сама по истечении TTL.
+type Locker interface {
+	// TryLock берёт блокировку name на ttl. false значит, что её держит кто-то другой.
+	TryLock(name string, ttl time.Duration) (token string, ok bool, err error)
+	// Refresh продлевает блокировку на ttl или возвращает ErrNotHeld, если она уже не принадлежит token.
+	Refresh(name string, token string, ttl time.Duration) error
+	Unlock(name string, token string) error
+}
@@ -0,0 +1,94 @@
+package lock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// RedisLocker хранит блокировку name в ключе lock:<name> со значением-токеном владельца.
+type RedisLocker struct {
+	pool *redis.Pool
+}
+
+func NewRedisLocker(pool *redis.Pool) *RedisLocker {
+	return &RedisLocker{pool: pool}
+}
+
+// refreshScript продлевает ключ, только если он всё ещё принадлежит токену.
+var refreshScript = redis.NewScript(1, `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// unlockScript удаляет ключ, только если он всё ещё принадлежит токену,
+// чтобы не снять блокировку, которую после истечения TTL взял другой процесс.
+var unlockScript = redis.NewScript(1, `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+func (l *RedisLocker) TryLock(name string, ttl time.Duration) (string, bool, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", false, err
+	}
+
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	_, err = redis.String(conn.Do("SET", key(name), token, "NX", "PX", ttl.Milliseconds()))
+	if err == redis.ErrNil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return token, true, nil
+}
+
+func (l *RedisLocker) Refresh(name string, token string, ttl time.Duration) error {
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	refreshed, err := redis.Int(refreshScript.Do(conn, key(name), token, ttl.Milliseconds()))
+	if err != nil {
+		return err
+	}
+	if refreshed == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+func (l *RedisLocker) Unlock(name string, token string) error {
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	deleted, err := redis.Int(unlockScript.Do(conn, key(name), token))
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+func key(name string) string {
+	return "lock:" + name
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,101 @@
+package lock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLocker(t *testing.T) (*RedisLocker, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	pool := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", mr.Addr())
+		},
+	}
+	t.Cleanup(func() { pool.Close() })
+	return NewRedisLocker(pool), mr
+}
+
+func TestRedisLockerTryLock(t *testing.T) {
+	l, mr := newTestLocker(t)
+
+	token, ok, err := l.TryLock("daily", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NotEmpty(t, token)
+	value, err := mr.Get("lock:daily")
+	assert.NoError(t, err)
+	assert.Equal(t, token, value)
+	assert.Equal(t, time.Minute, mr.TTL("lock:daily"))
+
+	// Пока блокировка не истекла, второй процесс её не получит.
+	_, ok, err = l.TryLock("daily", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// После TTL блокировку может взять другой процесс, и у него будет свой токен.
+	mr.FastForward(time.Minute)
+	other, ok, err := l.TryLock("daily", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NotEqual(t, token, other)
+}
+
+func TestRedisLockerRefresh(t *testing.T) {
+	l, mr := newTestLocker(t)
+
+	token, _, err := l.TryLock("daily", time.Minute)
+	assert.NoError(t, err)
+
+	mr.FastForward(30 * time.Second)
+	assert.NoError(t, l.Refresh("daily", token, time.Minute))
+	assert.Equal(t, time.Minute, mr.TTL("lock:daily"))
+
+	// Чужой токен блокировку не продлевает.
+	assert.Equal(t, ErrNotHeld, l.Refresh("daily", "other", 2*time.Minute))
+	assert.Equal(t, time.Minute, mr.TTL("lock:daily"))
+
+	// Истёкшую блокировку продлить нельзя, даже если её никто не взял.
+	mr.FastForward(time.Minute)
+	assert.Equal(t, ErrNotHeld, l.Refresh("daily", token, time.Minute))
+	assert.False(t, mr.Exists("lock:daily"))
+
+	// Истёкшую блокировку взял другой процесс - прежний владелец её не продлит.
+	other, ok, err := l.TryLock("daily", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, ErrNotHeld, l.Refresh("daily", token, time.Minute))
+	assert.NoError(t, l.Refresh("daily", other, time.Minute))
+}
+
+func TestRedisLockerUnlock(t *testing.T) {
+	l, mr := newTestLocker(t)
+
+	token, _, err := l.TryLock("daily", time.Minute)
+	assert.NoError(t, err)
+
+	// Не владелец блокировку не снимает.
+	assert.Equal(t, ErrNotHeld, l.Unlock("daily", "other"))
+	assert.True(t, mr.Exists("lock:daily"))
+
+	assert.NoError(t, l.Unlock("daily", token))
+	assert.False(t, mr.Exists("lock:daily"))
+	assert.Equal(t, ErrNotHeld, l.Unlock("daily", token))
+
+	// Прежний владелец не снимет блокировку, которую после истечения TTL взял другой процесс.
+	token, _, err = l.TryLock("daily", time.Minute)
+	assert.NoError(t, err)
+	mr.FastForward(time.Minute)
+	other, ok, err := l.TryLock("daily", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.Equal(t, ErrNotHeld, l.Unlock("daily", token))
+	value, err := mr.Get("lock:daily")
+	assert.NoError(t, err)
+	assert.Equal(t, other, value)
+}
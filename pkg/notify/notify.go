@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"errors"
+	"time"
+)
+
+// Форматы дат и времени, которые хранятся в таблицах рассылки. Время хранится в UTC.
+const (
+	DateLayout     = "2006-01-02"
+	DateTimeLayout = "2006-01-02 15:04:05"
+)
+
+// Статусы уведомления в журнале рассылки.
+const (
+	StatusPending   = "pending"
+	StatusScheduled = "scheduled" // Уведомление будет отправлено в NextAttempt по настройкам подписчика.
+	StatusSending   = "sending"   // Уведомление забрал воркер.
+	StatusSent      = "sent"
+	StatusRetry     = "retry"    // Временная ошибка, отправка будет повторена после NextAttempt.
+	StatusFailed    = "failed"   // Доставка невозможна, повторов не будет.
+	StatusUnlinked  = "unlinked" // Подписчик не привязал телеграм, отправлять некуда.
+	StatusDigest    = "digest"   // Напоминание отправлено в составе дайджеста другого уведомления.
+)
+
+// Виды уведомлений. Вид входит в уникальный ключ журнала, поэтому сводка не мешает напоминанию в тот же день.
+const (
+	KindReminder = "reminder" // Напоминание о дне рождения UserID.
+	KindSummary  = "summary"  // Сводка дней рождения для SubscriberID, UserID совпадает с ним.
+	KindGreeting = "greeting" // Поздравление от бота имениннику UserID, SubscriberID совпадает с ним.
+	// KindCongratulation - поздравление, которое подписчик SubscriberID оставил имениннику UserID.
+	KindCongratulation = "congratulation"
+)
+
+var (
+	ErrNoRun     = errors.New("job has never run")
+	ErrDuplicate = errors.New("notification already exists")
+)
+
+// Notification - запись журнала рассылки: напоминание подписчику SubscriberID о дне рождения UserID,
+// которое должно уйти в день Day за DaysBefore дней до праздника.
+type Notification struct {
+	ID           int64     `json:"id"`
+	UserID       int64     `json:"userID"`
+	SubscriberID int64     `json:"subscriberID"`
+	Day          time.Time `json:"day"`
+	Kind         string    `json:"kind"`
+	DaysBefore   int       `json:"daysBefore"`
+	Status       string    `json:"status"`
+	Attempts     int       `json:"attempts"`
+	MessageID    int       `json:"messageID"`
+	ChatID       int64     `json:"chatID"`
+	Text         string    `json:"text"`
+	LastError    string    `json:"lastError"`
+	NextAttempt  time.Time `json:"nextAttempt"`
+}
+
+type NotifyRepo interface {
+	LastRun(job string) (time.Time, error)
+	SetLastRun(job string, day time.Time) error
+	CreateNotification(n *Notification) (int64, error)
+	MarkSent(id int64, messageID int) error
+	MarkRetry(id int64, nextAttempt time.Time, reason string) error
+	MarkFailed(id int64, reason string) error
+	GetDueRetries(at time.Time) ([]Notification, error)
+	ClaimRetry(id int64) (bool, error)
+	ClaimSending(id int64, at time.Time) (bool, error)
+	ReclaimStaleSending(before time.Time, maxAttempts int, nextAttempt time.Time) (int64, error)
+	GetUnreportedFailures() ([]Notification, error)
+	MarkReported(id int64) error
+}
@@ -0,0 +1,269 @@
+package notify
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// errDupEntry - код ошибки MySQL при нарушении уникального ключа.
+const errDupEntry = 1062
+
+// maxErrorLen - длина колонки notifications.lastError.
+const maxErrorLen = 255
+
+// errStaleSending - причина неудачной попытки для уведомления, которое воркер забрал, но не отправил.
+const errStaleSending = "sending was not finished in time"
+
+type NotifyMysqlRepository struct {
+	DB *sql.DB
+}
+
+func NewMysqlRepo(db *sql.DB) *NotifyMysqlRepository {
+	return &NotifyMysqlRepository{DB: db}
+}
+
+// LastRun возвращает день последнего завершённого запуска задачи job.
+func (repo *NotifyMysqlRepository) LastRun(job string) (time.Time, error) {
+	var lastRun string
+
+	err := repo.DB.
+		QueryRow("SELECT lastRun FROM job_runs WHERE name = ?", job).
+		Scan(&lastRun)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, ErrNoRun
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Parse(DateLayout, lastRun)
+}
+
+// SetLastRun запоминает, что задача job выполнена за день day.
+func (repo *NotifyMysqlRepository) SetLastRun(job string, day time.Time) error {
+	_, err := repo.DB.Exec(
+		"INSERT INTO job_runs (`name`, `lastRun`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `lastRun` = VALUES(`lastRun`)",
+		job,
+		day.Format(DateLayout),
+	)
+	return err
+}
+
+// CreateNotification записывает уведомление перед отправкой. Если статус не задан, уведомление
+// создаётся в статусе pending, если не задан вид - это напоминание; NextAttempt сохраняется, только если он задан.
+// Уникальный ключ (userID, subscriberID, day, kind) не даёт отправить одно уведомление дважды,
+// в этом случае возвращается ErrDuplicate.
+func (repo *NotifyMysqlRepository) CreateNotification(n *Notification) (int64, error) {
+	status := n.Status
+	if status == "" {
+		status = StatusPending
+	}
+	kind := n.Kind
+	if kind == "" {
+		kind = KindReminder
+	}
+
+	var lastError sql.NullString
+	if n.LastError != "" {
+		lastError = sql.NullString{String: truncate(n.LastError, maxErrorLen), Valid: true}
+	}
+
+	var nextAttempt sql.NullString
+	if !n.NextAttempt.IsZero() {
+		nextAttempt = sql.NullString{String: n.NextAttempt.UTC().Format(DateTimeLayout), Valid: true}
+	}
+
+	result, err := repo.DB.Exec(
+		"INSERT INTO notifications (`userID`, `subscriberID`, `day`, `kind`, `daysBefore`, `status`, `chatID`, `text`, `lastError`, `nextAttempt`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		n.UserID,
+		n.SubscriberID,
+		n.Day.Format(DateLayout),
+		kind,
+		n.DaysBefore,
+		status,
+		n.ChatID,
+		n.Text,
+		lastError,
+		nextAttempt,
+	)
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == errDupEntry {
+		return 0, ErrDuplicate
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// MarkSent отмечает уведомление доставленным и сохраняет id сообщения в телеграме.
+func (repo *NotifyMysqlRepository) MarkSent(id int64, messageID int) error {
+	_, err := repo.DB.Exec(
+		"UPDATE notifications SET `status` = ?, `attempts` = `attempts` + 1, `messageID` = ? WHERE `id` = ?",
+		StatusSent,
+		messageID,
+		id,
+	)
+	return err
+}
+
+// MarkRetry откладывает повторную отправку уведомления до nextAttempt.
+func (repo *NotifyMysqlRepository) MarkRetry(id int64, nextAttempt time.Time, reason string) error {
+	_, err := repo.DB.Exec(
+		"UPDATE notifications SET `status` = ?, `attempts` = `attempts` + 1, `nextAttempt` = ?, `lastError` = ? WHERE `id` = ?",
+		StatusRetry,
+		nextAttempt.UTC().Format(DateTimeLayout),
+		truncate(reason, maxErrorLen),
+		id,
+	)
+	return err
+}
+
+// MarkFailed отмечает, что уведомление доставить не удалось и повторять отправку не нужно.
+func (repo *NotifyMysqlRepository) MarkFailed(id int64, reason string) error {
+	_, err := repo.DB.Exec(
+		"UPDATE notifications SET `status` = ?, `attempts` = `attempts` + 1, `lastError` = ? WHERE `id` = ?",
+		StatusFailed,
+		truncate(reason, maxErrorLen),
+		id,
+	)
+	return err
+}
+
+// GetDueRetries возвращает отложенные и запланированные уведомления, время отправки которых наступило к моменту at.
+func (repo *NotifyMysqlRepository) GetDueRetries(at time.Time) ([]Notification, error) {
+	return repo.queryNotifications(`
+		SELECT id, userID, subscriberID, day, daysBefore, status, attempts, chatID, text, lastError
+		FROM notifications
+		WHERE status IN (?, ?) AND nextAttempt <= ?
+		ORDER BY nextAttempt`, StatusRetry, StatusScheduled, at.UTC().Format(DateTimeLayout))
+}
+
+// ClaimRetry переводит уведомление из retry или scheduled в pending. false значит, что его уже забрал другой процесс.
+func (repo *NotifyMysqlRepository) ClaimRetry(id int64) (bool, error) {
+	return repo.changeStatus(id, StatusPending, StatusRetry, StatusScheduled)
+}
+
+// ClaimSending переводит уведомление из pending в sending перед отправкой и запоминает момент at,
+// по которому ReclaimStaleSending найдёт уведомление, если воркер упадёт, не записав результат.
+// false значит, что его уже отправляет или отправил другой воркер.
+func (repo *NotifyMysqlRepository) ClaimSending(id int64, at time.Time) (bool, error) {
+	result, err := repo.DB.Exec(
+		"UPDATE notifications SET `status` = ?, `claimedAt` = ? WHERE `id` = ? AND `status` = ?",
+		StatusSending,
+		at.UTC().Format(DateTimeLayout),
+		id,
+		StatusPending,
+	)
+	return changedOne(result, err)
+}
+
+// ReclaimStaleSending возвращает в retry уведомления, которые воркер забрал в sending раньше before
+// и так и не отметил отправленными. Такая попытка считается неудачной, поэтому уведомление,
+// исчерпавшее maxAttempts попыток, становится failed. Повтор сработает не раньше nextAttempt.
+// Возвращает число найденных уведомлений.
+func (repo *NotifyMysqlRepository) ReclaimStaleSending(before time.Time, maxAttempts int, nextAttempt time.Time) (int64, error) {
+	// MySQL выполняет присваивания по порядку, поэтому status считается по attempts до увеличения.
+	result, err := repo.DB.Exec(
+		"UPDATE notifications SET `status` = IF(`attempts` + 1 >= ?, ?, ?), `attempts` = `attempts` + 1, "+
+			"`nextAttempt` = ?, `lastError` = ? WHERE `status` = ? AND `claimedAt` < ?",
+		maxAttempts,
+		StatusFailed,
+		StatusRetry,
+		nextAttempt.UTC().Format(DateTimeLayout),
+		errStaleSending,
+		StatusSending,
+		before.UTC().Format(DateTimeLayout),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// changeStatus переводит уведомление в статус to, если сейчас у него один из статусов from.
+func (repo *NotifyMysqlRepository) changeStatus(id int64, to string, from ...string) (bool, error) {
+	args := []interface{}{to, id}
+	for _, status := range from {
+		args = append(args, status)
+	}
+
+	result, err := repo.DB.Exec(
+		"UPDATE notifications SET `status` = ? WHERE `id` = ? AND `status` IN (?"+strings.Repeat(", ?", len(from)-1)+")",
+		args...,
+	)
+	return changedOne(result, err)
+}
+
+// changedOne сообщает, изменил ли UPDATE запись; err - ошибка самого запроса.
+func changedOne(result sql.Result, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected == 1, nil
+}
+
+// GetUnreportedFailures возвращает недоставленные уведомления, о которых ещё не сообщили администраторам.
+func (repo *NotifyMysqlRepository) GetUnreportedFailures() ([]Notification, error) {
+	return repo.queryNotifications(`
+		SELECT id, userID, subscriberID, day, daysBefore, status, attempts, chatID, text, lastError
+		FROM notifications
+		WHERE status = ? AND reported = FALSE
+		ORDER BY id`, StatusFailed)
+}
+
+// MarkReported отмечает, что администраторы получили сообщение о недоставленном уведомлении.
+func (repo *NotifyMysqlRepository) MarkReported(id int64) error {
+	_, err := repo.DB.Exec("UPDATE notifications SET `reported` = TRUE WHERE `id` = ?", id)
+	return err
+}
+
+func (repo *NotifyMysqlRepository) queryNotifications(query string, args ...interface{}) ([]Notification, error) {
+	rows, err := repo.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []Notification
+	for rows.Next() {
+		var n Notification
+		var day string
+		var lastError sql.NullString
+		if err = rows.Scan(&n.ID, &n.UserID, &n.SubscriberID, &day, &n.DaysBefore, &n.Status, &n.Attempts, &n.ChatID, &n.Text, &lastError); err != nil {
+			return nil, err
+		}
+		if n.Day, err = time.Parse(DateLayout, day); err != nil {
+			return nil, err
+		}
+		n.LastError = lastError.String
+		notifications = append(notifications, n)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// truncate обрезает строку до max байт, не разрывая символы.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
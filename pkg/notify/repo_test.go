@@ -0,0 +1,414 @@
+package notify
+
+import (
+	"database/sql"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastRun(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db)
+
+	query := regexp.QuoteMeta("SELECT lastRun FROM job_runs WHERE name = ?")
+
+	tests := []struct {
+		name        string
+		mockFunc    func()
+		expected    time.Time
+		expectedErr error
+	}{
+		{
+			name: "Last run found",
+			mockFunc: func() {
+				mock.ExpectQuery(query).
+					WithArgs("notifications").
+					WillReturnRows(sqlmock.NewRows([]string{"lastRun"}).AddRow("2024-06-10"))
+			},
+			expected:    time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC),
+			expectedErr: nil,
+		},
+		{
+			name: "Never run",
+			mockFunc: func() {
+				mock.ExpectQuery(query).
+					WithArgs("notifications").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expected:    time.Time{},
+			expectedErr: ErrNoRun,
+		},
+		{
+			name: "Query error",
+			mockFunc: func() {
+				mock.ExpectQuery(query).
+					WithArgs("notifications").
+					WillReturnError(sql.ErrConnDone)
+			},
+			expected:    time.Time{},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc()
+			lastRun, err := repo.LastRun("notifications")
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expected, lastRun)
+		})
+	}
+}
+
+func TestSetLastRun(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db)
+
+	query := regexp.QuoteMeta("INSERT INTO job_runs (`name`, `lastRun`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `lastRun` = VALUES(`lastRun`)")
+	day := time.Date(2024, 6, 10, 0, 0, 0, 0, time.Local)
+
+	mock.ExpectExec(query).
+		WithArgs("notifications", "2024-06-10").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.SetLastRun("notifications", day))
+
+	mock.ExpectExec(query).
+		WithArgs("notifications", "2024-06-10").
+		WillReturnError(sql.ErrConnDone)
+	assert.Equal(t, sql.ErrConnDone, repo.SetLastRun("notifications", day))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateNotification(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db)
+
+	query := regexp.QuoteMeta("INSERT INTO notifications (`userID`, `subscriberID`, `day`, `kind`, `daysBefore`, `status`, `chatID`, `text`, `lastError`, `nextAttempt`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	day := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	n := &Notification{UserID: 1, SubscriberID: 2, Day: day, DaysBefore: 7, ChatID: 200, Text: "hello"}
+
+	tests := []struct {
+		name         string
+		notification *Notification
+		mockFunc     func()
+		expected     int64
+		expectedErr  error
+	}{
+		{
+			name: "Unlinked subscriber recorded",
+			notification: &Notification{
+				UserID: 1, SubscriberID: 2, Day: day, DaysBefore: 7, Text: "hello",
+				Status: StatusUnlinked, LastError: "telegram is not linked",
+			},
+			mockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs(1, 2, "2024-06-10", KindReminder, 7, StatusUnlinked, 0, "hello", "telegram is not linked", nil).
+					WillReturnResult(sqlmock.NewResult(4, 1))
+			},
+			expected:    4,
+			expectedErr: nil,
+		},
+		{
+			name:         "Notification created",
+			notification: n,
+			mockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs(1, 2, "2024-06-10", KindReminder, 7, StatusPending, 200, "hello", nil, nil).
+					WillReturnResult(sqlmock.NewResult(5, 1))
+			},
+			expected:    5,
+			expectedErr: nil,
+		},
+		{
+			name: "Scheduled by subscriber settings",
+			notification: &Notification{
+				UserID: 1, SubscriberID: 2, Day: day, DaysBefore: 7, ChatID: 200, Text: "hello",
+				Status: StatusScheduled, NextAttempt: time.Date(2024, 6, 10, 12, 0, 0, 0, time.FixedZone("MSK", 3*60*60)),
+			},
+			mockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs(1, 2, "2024-06-10", KindReminder, 7, StatusScheduled, 200, "hello", nil, "2024-06-10 09:00:00").
+					WillReturnResult(sqlmock.NewResult(6, 1))
+			},
+			expected:    6,
+			expectedErr: nil,
+		},
+		{
+			name: "Summary",
+			notification: &Notification{
+				UserID: 2, SubscriberID: 2, Day: day, Kind: KindSummary, ChatID: 200, Text: "hello",
+			},
+			mockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs(2, 2, "2024-06-10", KindSummary, 0, StatusPending, 200, "hello", nil, nil).
+					WillReturnResult(sqlmock.NewResult(7, 1))
+			},
+			expected:    7,
+			expectedErr: nil,
+		},
+		{
+			name:         "Already exists",
+			notification: n,
+			mockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs(1, 2, "2024-06-10", KindReminder, 7, StatusPending, 200, "hello", nil, nil).
+					WillReturnError(&mysql.MySQLError{Number: errDupEntry, Message: "Duplicate entry"})
+			},
+			expected:    0,
+			expectedErr: ErrDuplicate,
+		},
+		{
+			name:         "Insert error",
+			notification: n,
+			mockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs(1, 2, "2024-06-10", KindReminder, 7, StatusPending, 200, "hello", nil, nil).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expected:    0,
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc()
+			id, err := repo.CreateNotification(tt.notification)
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expected, id)
+		})
+	}
+}
+
+func TestMarkNotification(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db)
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE notifications SET `status` = ?, `attempts` = `attempts` + 1, `messageID` = ? WHERE `id` = ?")).
+		WithArgs(StatusSent, 42, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.MarkSent(5, 42))
+
+	nextAttempt := time.Date(2024, 6, 10, 12, 30, 0, 0, time.FixedZone("MSK", 3*60*60))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE notifications SET `status` = ?, `attempts` = `attempts` + 1, `nextAttempt` = ?, `lastError` = ? WHERE `id` = ?")).
+		WithArgs(StatusRetry, "2024-06-10 09:30:00", "Too Many Requests", 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.MarkRetry(5, nextAttempt, "Too Many Requests"))
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE notifications SET `status` = ?, `attempts` = `attempts` + 1, `lastError` = ? WHERE `id` = ?")).
+		WithArgs(StatusFailed, strings.Repeat("ы", maxErrorLen/2), 5).
+		WillReturnError(sql.ErrConnDone)
+	assert.Equal(t, sql.ErrConnDone, repo.MarkFailed(5, strings.Repeat("ы", maxErrorLen)))
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE notifications SET `reported` = TRUE WHERE `id` = ?")).
+		WithArgs(5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.MarkReported(5))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDueRetries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db)
+
+	query := regexp.QuoteMeta(`
+		SELECT id, userID, subscriberID, day, daysBefore, status, attempts, chatID, text, lastError
+		FROM notifications
+		WHERE status IN (?, ?) AND nextAttempt <= ?
+		ORDER BY nextAttempt`)
+	columns := []string{"id", "userID", "subscriberID", "day", "daysBefore", "status", "attempts", "chatID", "text", "lastError"}
+	at := time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		mockFunc    func()
+		expected    []Notification
+		expectedErr error
+	}{
+		{
+			name: "Due retries",
+			mockFunc: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(5, 1, 2, "2024-06-10", 0, StatusRetry, 2, 200, "hello", "Too Many Requests")
+				mock.ExpectQuery(query).
+					WithArgs(StatusRetry, StatusScheduled, "2024-06-10 09:00:00").
+					WillReturnRows(rows)
+			},
+			expected: []Notification{{
+				ID: 5, UserID: 1, SubscriberID: 2, Day: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC),
+				Status: StatusRetry, Attempts: 2, ChatID: 200, Text: "hello", LastError: "Too Many Requests",
+			}},
+			expectedErr: nil,
+		},
+		{
+			name: "Nothing to retry",
+			mockFunc: func() {
+				mock.ExpectQuery(query).
+					WithArgs(StatusRetry, StatusScheduled, "2024-06-10 09:00:00").
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expected:    nil,
+			expectedErr: nil,
+		},
+		{
+			name: "Query error",
+			mockFunc: func() {
+				mock.ExpectQuery(query).
+					WithArgs(StatusRetry, StatusScheduled, "2024-06-10 09:00:00").
+					WillReturnError(sql.ErrConnDone)
+			},
+			expected:    nil,
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc()
+			notifications, err := repo.GetDueRetries(at)
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expected, notifications)
+		})
+	}
+}
+
+func TestGetUnreportedFailures(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db)
+
+	rows := sqlmock.NewRows([]string{"id", "userID", "subscriberID", "day", "daysBefore", "status", "attempts", "chatID", "text", "lastError"}).
+		AddRow(6, 1, 3, "2024-06-10", 0, StatusFailed, 1, 300, "hello", nil)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE status = ? AND reported = FALSE")).
+		WithArgs(StatusFailed).
+		WillReturnRows(rows)
+
+	notifications, err := repo.GetUnreportedFailures()
+	assert.NoError(t, err)
+	assert.Equal(t, []Notification{{
+		ID: 6, UserID: 1, SubscriberID: 3, Day: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC),
+		Status: StatusFailed, Attempts: 1, ChatID: 300, Text: "hello",
+	}}, notifications)
+}
+
+func TestClaimRetry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db)
+
+	query := regexp.QuoteMeta("UPDATE notifications SET `status` = ? WHERE `id` = ? AND `status` IN (?, ?)")
+
+	mock.ExpectExec(query).
+		WithArgs(StatusPending, 5, StatusRetry, StatusScheduled).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	claimed, err := repo.ClaimRetry(5)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	mock.ExpectExec(query).
+		WithArgs(StatusPending, 5, StatusRetry, StatusScheduled).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	claimed, err = repo.ClaimRetry(5)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	mock.ExpectExec(query).
+		WithArgs(StatusPending, 5, StatusRetry, StatusScheduled).
+		WillReturnError(sql.ErrConnDone)
+	_, err = repo.ClaimRetry(5)
+	assert.Equal(t, sql.ErrConnDone, err)
+}
+
+func TestClaimSending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db)
+
+	query := regexp.QuoteMeta("UPDATE notifications SET `status` = ?, `claimedAt` = ? WHERE `id` = ? AND `status` = ?")
+	at := time.Date(2024, 6, 10, 12, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+	mock.ExpectExec(query).
+		WithArgs(StatusSending, "2024-06-10 09:00:00", 5, StatusPending).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	claimed, err := repo.ClaimSending(5, at)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	mock.ExpectExec(query).
+		WithArgs(StatusSending, "2024-06-10 09:00:00", 5, StatusPending).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	claimed, err = repo.ClaimSending(5, at)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+}
+
+func TestReclaimStaleSending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db)
+
+	query := regexp.QuoteMeta("UPDATE notifications SET `status` = IF(`attempts` + 1 >= ?, ?, ?), `attempts` = `attempts` + 1, " +
+		"`nextAttempt` = ?, `lastError` = ? WHERE `status` = ? AND `claimedAt` < ?")
+	current := time.Date(2024, 6, 10, 9, 10, 0, 0, time.UTC)
+
+	mock.ExpectExec(query).
+		WithArgs(6, StatusFailed, StatusRetry, "2024-06-10 09:10:00", errStaleSending, StatusSending, "2024-06-10 09:00:00").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	reclaimed, err := repo.ReclaimStaleSending(current.Add(-10*time.Minute), 6, current)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), reclaimed)
+
+	mock.ExpectExec(query).
+		WillReturnError(sql.ErrConnDone)
+	_, err = repo.ReclaimStaleSending(current.Add(-10*time.Minute), 6, current)
+	assert.Equal(t, sql.ErrConnDone, err)
+}
@@ -0,0 +1,25 @@
+package queue
+
+import (
+	"errors"
+)
+
+var ErrEmpty = errors.New("queue is empty")
+
+// Job - задача из очереди. Attempts - сколько раз задача уже выдавалась воркерам, включая текущий.
+type Job struct {
+	ID       string
+	Payload  []byte
+	Attempts int
+}
+
+// Queue - очередь задач с гарантией доставки хотя бы один раз. Выданная задача скрыта от других
+// воркеров на время visibility timeout; если её не подтвердили через Ack, она возвращается в очередь,
+// а после слишком большого числа выдач уходит в очередь недоставленных (dead letter).
+type Queue interface {
+	Enqueue(payload []byte) (string, error)
+	Dequeue() (*Job, error)
+	Ack(id string) error
+	Bury(id string) error
+	ReclaimExpired() (requeued int, buried int, err error)
+}
@@ -0,0 +1,158 @@
+package queue
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Ключи очереди name в redis:
+//
+//	queue:<name>:ready      - список id задач, ожидающих воркера;
+//	queue:<name>:processing - sorted set выданных задач, score - момент окончания visibility timeout в мс;
+//	queue:<name>:jobs       - hash id -> данные задачи;
+//	queue:<name>:attempts   - hash id -> сколько раз задача выдавалась;
+//	queue:<name>:dead       - список id задач, которые так и не удалось обработать;
+//	queue:<name>:seq        - счётчик для id задач.
+type RedisQueue struct {
+	pool              *redis.Pool
+	prefix            string
+	visibilityTimeout time.Duration
+	maxDeliveries     int
+}
+
+// NewRedisQueue создаёт очередь name. Задача, выданная maxDeliveries раз и ни разу не подтверждённая,
+// уходит в dead letter.
+func NewRedisQueue(pool *redis.Pool, name string, visibilityTimeout time.Duration, maxDeliveries int) *RedisQueue {
+	return &RedisQueue{
+		pool:              pool,
+		prefix:            "queue:" + name + ":",
+		visibilityTimeout: visibilityTimeout,
+		maxDeliveries:     maxDeliveries,
+	}
+}
+
+// dequeueScript атомарно забирает задачу из ready и кладёт её в processing,
+// чтобы задача не потерялась, если воркер упадёт между этими шагами.
+var dequeueScript = redis.NewScript(4, `
+local id = redis.call('RPOP', KEYS[1])
+if not id then
+	return false
+end
+redis.call('ZADD', KEYS[2], ARGV[1], id)
+local attempts = redis.call('HINCRBY', KEYS[4], id, 1)
+return {id, redis.call('HGET', KEYS[3], id), attempts}
+`)
+
+// reclaimScript возвращает в ready задачи с истёкшим visibility timeout,
+// а задачи, выданные maxDeliveries раз, переносит в dead.
+var reclaimScript = redis.NewScript(4, `
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+local requeued, buried = 0, 0
+for _, id in ipairs(ids) do
+	redis.call('ZREM', KEYS[1], id)
+	local attempts = tonumber(redis.call('HGET', KEYS[3], id) or '0')
+	if attempts >= tonumber(ARGV[2]) then
+		redis.call('LPUSH', KEYS[4], id)
+		buried = buried + 1
+	else
+		redis.call('RPUSH', KEYS[2], id)
+		requeued = requeued + 1
+	end
+end
+return {requeued, buried}
+`)
+
+func (q *RedisQueue) Enqueue(payload []byte) (string, error) {
+	conn := q.pool.Get()
+	defer conn.Close()
+
+	seq, err := redis.Int64(conn.Do("INCR", q.prefix+"seq"))
+	if err != nil {
+		return "", err
+	}
+	id := strconv.FormatInt(seq, 10)
+
+	// Данные пишутся раньше id, чтобы воркер не увидел задачу без данных.
+	if _, err = conn.Do("HSET", q.prefix+"jobs", id, payload); err != nil {
+		return "", err
+	}
+	if _, err = conn.Do("LPUSH", q.prefix+"ready", id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Dequeue выдаёт самую старую задачу или ErrEmpty, если очередь пуста.
+func (q *RedisQueue) Dequeue() (*Job, error) {
+	conn := q.pool.Get()
+	defer conn.Close()
+
+	deadline := time.Now().Add(q.visibilityTimeout).UnixMilli()
+	reply, err := redis.Values(dequeueScript.Do(conn,
+		q.prefix+"ready", q.prefix+"processing", q.prefix+"jobs", q.prefix+"attempts", deadline))
+	if err == redis.ErrNil {
+		return nil, ErrEmpty
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job := &Job{}
+	if _, err = redis.Scan(reply, &job.ID, &job.Payload, &job.Attempts); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Ack подтверждает, что задача обработана, и удаляет её.
+func (q *RedisQueue) Ack(id string) error {
+	return q.remove(id, "")
+}
+
+// Bury сразу переносит задачу в dead letter, например если её данные не удаётся разобрать.
+func (q *RedisQueue) Bury(id string) error {
+	return q.remove(id, q.prefix+"dead")
+}
+
+func (q *RedisQueue) remove(id string, deadKey string) error {
+	conn := q.pool.Get()
+	defer conn.Close()
+
+	if err := conn.Send("MULTI"); err != nil {
+		return err
+	}
+	if err := conn.Send("ZREM", q.prefix+"processing", id); err != nil {
+		return err
+	}
+	if deadKey != "" {
+		// Данные задачи остаются, чтобы её можно было разобрать вручную.
+		if err := conn.Send("LPUSH", deadKey, id); err != nil {
+			return err
+		}
+	} else {
+		if err := conn.Send("HDEL", q.prefix+"jobs", id); err != nil {
+			return err
+		}
+		if err := conn.Send("HDEL", q.prefix+"attempts", id); err != nil {
+			return err
+		}
+	}
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+// ReclaimExpired возвращает в очередь задачи, которые воркеры не подтвердили за visibility timeout.
+func (q *RedisQueue) ReclaimExpired() (int, int, error) {
+	conn := q.pool.Get()
+	defer conn.Close()
+
+	reply, err := redis.Ints(reclaimScript.Do(conn,
+		q.prefix+"processing", q.prefix+"ready", q.prefix+"attempts", q.prefix+"dead",
+		time.Now().UnixMilli(), q.maxDeliveries))
+	if err != nil {
+		return 0, 0, err
+	}
+	return reply[0], reply[1], nil
+}
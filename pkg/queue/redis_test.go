@@ -0,0 +1,135 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestQueue создаёт очередь "test" в miniredis: задача уходит в dead letter после второй выдачи.
+func newTestQueue(t *testing.T) (*RedisQueue, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	pool := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", mr.Addr())
+		},
+	}
+	t.Cleanup(func() { pool.Close() })
+	return NewRedisQueue(pool, "test", time.Minute, 2), mr
+}
+
+// expire переносит окончание visibility timeout задачи id в прошлое.
+func expire(t *testing.T, mr *miniredis.Miniredis, id string) {
+	_, err := mr.ZAdd("queue:test:processing", 0, id)
+	assert.NoError(t, err)
+}
+
+func TestRedisQueueDequeue(t *testing.T) {
+	q, mr := newTestQueue(t)
+
+	first, err := q.Enqueue([]byte("first"))
+	assert.NoError(t, err)
+	second, err := q.Enqueue([]byte("second"))
+	assert.NoError(t, err)
+
+	job, err := q.Dequeue()
+	assert.NoError(t, err)
+	assert.Equal(t, &Job{ID: first, Payload: []byte("first"), Attempts: 1}, job)
+
+	job, err = q.Dequeue()
+	assert.NoError(t, err)
+	assert.Equal(t, &Job{ID: second, Payload: []byte("second"), Attempts: 1}, job)
+
+	_, err = q.Dequeue()
+	assert.Equal(t, ErrEmpty, err)
+
+	// Выданные задачи ждут подтверждения в processing, пока не истечёт visibility timeout.
+	processing, err := mr.ZMembers("queue:test:processing")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{first, second}, processing)
+	score, err := mr.ZScore("queue:test:processing", first)
+	assert.NoError(t, err)
+	assert.Greater(t, score, float64(time.Now().UnixMilli()))
+}
+
+func TestRedisQueueAck(t *testing.T) {
+	q, mr := newTestQueue(t)
+
+	id, err := q.Enqueue([]byte("payload"))
+	assert.NoError(t, err)
+	_, err = q.Dequeue()
+	assert.NoError(t, err)
+
+	assert.NoError(t, q.Ack(id))
+
+	assert.False(t, mr.Exists("queue:test:processing"))
+	assert.False(t, mr.Exists("queue:test:jobs"))
+	assert.False(t, mr.Exists("queue:test:attempts"))
+
+	requeued, buried, err := q.ReclaimExpired()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, requeued)
+	assert.Equal(t, 0, buried)
+}
+
+func TestRedisQueueReclaimExpired(t *testing.T) {
+	q, mr := newTestQueue(t)
+
+	id, err := q.Enqueue([]byte("payload"))
+	assert.NoError(t, err)
+	_, err = q.Dequeue()
+	assert.NoError(t, err)
+
+	// Visibility timeout ещё не истёк - задача остаётся у воркера.
+	requeued, buried, err := q.ReclaimExpired()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, requeued)
+	assert.Equal(t, 0, buried)
+	_, err = q.Dequeue()
+	assert.Equal(t, ErrEmpty, err)
+
+	// Воркер не подтвердил задачу вовремя - она возвращается в очередь.
+	expire(t, mr, id)
+	requeued, buried, err = q.ReclaimExpired()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requeued)
+	assert.Equal(t, 0, buried)
+
+	job, err := q.Dequeue()
+	assert.NoError(t, err)
+	assert.Equal(t, &Job{ID: id, Payload: []byte("payload"), Attempts: 2}, job)
+
+	// Вторая выдача тоже не подтверждена - задача уходит в dead letter вместе с данными.
+	expire(t, mr, id)
+	requeued, buried, err = q.ReclaimExpired()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, requeued)
+	assert.Equal(t, 1, buried)
+
+	_, err = q.Dequeue()
+	assert.Equal(t, ErrEmpty, err)
+	dead, err := mr.List("queue:test:dead")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{id}, dead)
+	assert.Equal(t, "payload", mr.HGet("queue:test:jobs", id))
+}
+
+func TestRedisQueueBury(t *testing.T) {
+	q, mr := newTestQueue(t)
+
+	id, err := q.Enqueue([]byte("broken"))
+	assert.NoError(t, err)
+	_, err = q.Dequeue()
+	assert.NoError(t, err)
+
+	assert.NoError(t, q.Bury(id))
+
+	assert.False(t, mr.Exists("queue:test:processing"))
+	dead, err := mr.List("queue:test:dead")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{id}, dead)
+	assert.Equal(t, "broken", mr.HGet("queue:test:jobs", id))
+}
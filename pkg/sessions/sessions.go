@@ -13,6 +13,7 @@ type Session struct {
 	ID        int64
 	Login     string
 	Useragent string
+	Language  string // Язык, выбранный пользователем на момент входа; пустая строка - не выбран.
 }
 
 type SessionID struct {
@@ -0,0 +1,73 @@
+package user
+
+import (
+	"fmt"
+	"time"
+)
+
+// BirthdayLayout - формат, в котором дата рождения хранится в базе и приходит в API.
+const BirthdayLayout = "2006-01-02"
+
+// LeapDayPolicy задаёт, когда поздравлять родившихся 29 февраля в невисокосный год.
+// Пустое правило работает как LeapDayMar1.
+type LeapDayPolicy string
+
+const (
+	LeapDayFeb28 LeapDayPolicy = "feb28"
+	LeapDayMar1  LeapDayPolicy = "mar1"
+)
+
+// ParseLeapDayPolicy проверяет название правила для 29 февраля.
+func ParseLeapDayPolicy(name string) (LeapDayPolicy, error) {
+	switch policy := LeapDayPolicy(name); policy {
+	case LeapDayFeb28, LeapDayMar1:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown leap day policy %q", name)
+	}
+}
+
+// NextBirthday возвращает ближайший день рождения, начиная с дня from включительно.
+// Время в from отбрасывается, результат возвращается в том же часовом поясе.
+// День рождения 29 февраля в невисокосный год переносится по правилу leapDay.
+func NextBirthday(birthday string, from time.Time, leapDay LeapDayPolicy) (time.Time, error) {
+	born, err := time.Parse(BirthdayLayout, birthday)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	today := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	next := birthdayIn(born, today.Year(), from.Location(), leapDay)
+	if next.Before(today) {
+		next = birthdayIn(born, today.Year()+1, from.Location(), leapDay)
+	}
+	return next, nil
+}
+
+// birthdayIn возвращает день рождения born в году year.
+func birthdayIn(born time.Time, year int, loc *time.Location, leapDay LeapDayPolicy) time.Time {
+	if born.Month() == time.February && born.Day() == 29 && !isLeap(year) {
+		return leapDayIn(year, loc, leapDay)
+	}
+	return time.Date(year, born.Month(), born.Day(), 0, 0, 0, 0, loc)
+}
+
+// leapDayIn возвращает день, когда в невисокосный год year поздравляют родившихся 29 февраля по правилу leapDay.
+func leapDayIn(year int, loc *time.Location, leapDay LeapDayPolicy) time.Time {
+	if leapDay == LeapDayFeb28 {
+		return time.Date(year, time.February, 28, 0, 0, 0, 0, loc)
+	}
+	return time.Date(year, time.March, 1, 0, 0, 0, 0, loc)
+}
+
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// DaysUntil возвращает число дней от from до дня date; для прошедшего дня оно отрицательное.
+func DaysUntil(date, from time.Time) int {
+	// Даты сравниваются в UTC, где в сутках ровно 24 часа и переход на летнее время не сдвигает деление.
+	today := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return int(day.Sub(today) / (24 * time.Hour))
+}
@@ -0,0 +1,113 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextBirthday(t *testing.T) {
+	tests := []struct {
+		name        string
+		birthday    string
+		from        time.Time
+		leapDay     LeapDayPolicy
+		expected    time.Time
+		expectedErr bool
+	}{
+		{
+			name:     "Birthday later this year",
+			birthday: "1990-05-10",
+			from:     time.Date(2024, 3, 1, 15, 30, 0, 0, time.UTC),
+			expected: time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Birthday today",
+			birthday: "1990-03-01",
+			from:     time.Date(2024, 3, 1, 23, 59, 0, 0, time.UTC),
+			expected: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Birthday already passed",
+			birthday: "1990-01-05",
+			from:     time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "February 29 in a leap year",
+			birthday: "1992-02-29",
+			from:     time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "February 29 moves to March 1 in a non-leap year",
+			birthday: "1992-02-29",
+			from:     time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+			leapDay:  LeapDayMar1,
+			expected: time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "February 29 moves to February 28 in a non-leap year",
+			birthday: "1992-02-29",
+			from:     time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+			leapDay:  LeapDayFeb28,
+			expected: time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "February 29 after February 28 has passed goes to next year",
+			birthday: "1992-02-29",
+			from:     time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+			leapDay:  LeapDayFeb28,
+			expected: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "Invalid date",
+			birthday:    "10.05.1990",
+			from:        time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, err := NextBirthday(tt.birthday, tt.from, tt.leapDay)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, next)
+		})
+	}
+}
+
+func TestDaysUntil(t *testing.T) {
+	loc := time.FixedZone("MSK", 3*60*60)
+	from := time.Date(2024, 12, 30, 22, 0, 0, 0, loc)
+
+	assert.Equal(t, 0, DaysUntil(time.Date(2024, 12, 30, 0, 0, 0, 0, loc), from))
+	assert.Equal(t, 6, DaysUntil(time.Date(2025, 1, 5, 0, 0, 0, 0, loc), from))
+	assert.Equal(t, -1, DaysUntil(time.Date(2024, 12, 29, 0, 0, 0, 0, loc), from))
+	assert.Equal(t, -366, DaysUntil(time.Date(2023, 12, 30, 0, 0, 0, 0, loc), from))
+
+	// Переход на летнее время не меняет число дней.
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	spring := time.Date(2024, 3, 30, 12, 0, 0, 0, berlin)
+	assert.Equal(t, 1, DaysUntil(time.Date(2024, 3, 31, 0, 0, 0, 0, berlin), spring))
+	assert.Equal(t, -1, DaysUntil(time.Date(2024, 3, 29, 0, 0, 0, 0, berlin), time.Date(2024, 3, 30, 0, 0, 0, 0, berlin)))
+}
+
+func TestParseLeapDayPolicy(t *testing.T) {
+	policy, err := ParseLeapDayPolicy("feb28")
+	assert.NoError(t, err)
+	assert.Equal(t, LeapDayFeb28, policy)
+
+	policy, err = ParseLeapDayPolicy("mar1")
+	assert.NoError(t, err)
+	assert.Equal(t, LeapDayMar1, policy)
+
+	_, err = ParseLeapDayPolicy("feb29")
+	assert.Error(t, err)
+}
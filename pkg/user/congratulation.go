@@ -0,0 +1,31 @@
+package user
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxCongratulationLength - сколько символов может быть в поздравлении, которое подписчик оставляет имениннику.
+const MaxCongratulationLength = 500
+
+// Congratulation - поздравление, которое подписчик AuthorID оставил имениннику UserID. Бот пересылает его
+// в день рождения, даже если именинник выключил поздравления от бота (Settings.Greet), и после этого удаляет.
+type Congratulation struct {
+	ID       int64 `json:"id"`
+	UserID   int64 `json:"userID"`
+	AuthorID int64 `json:"authorID"`
+	// Author - имя и фамилия автора; не заполняется при сохранении.
+	Author string `json:"author"`
+	// Anonymous - не показывать имениннику, от кого поздравление.
+	Anonymous bool   `json:"anonymous"`
+	Text      string `json:"text"`
+}
+
+// CheckCongratulation проверяет, что текст поздравления не пустой и не длиннее MaxCongratulationLength.
+func CheckCongratulation(text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" || utf8.RuneCountInString(text) > MaxCongratulationLength {
+		return ErrBadCongratulation
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package user
+
+// Род пользователя; по нему выбираются местоимения и формы имени в поздравлениях.
+const (
+	GenderUnknown = ""
+	GenderMale    = "male"
+	GenderFemale  = "female"
+)
+
+// CheckGender проверяет род пользователя. Пустая строка допустима: род не указан.
+func CheckGender(gender string) error {
+	switch gender {
+	case GenderUnknown, GenderMale, GenderFemale:
+		return nil
+	default:
+		return ErrBadGender
+	}
+}
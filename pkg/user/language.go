@@ -0,0 +1,16 @@
+package user
+
+import (
+	"regexp"
+)
+
+var languageRe = regexp.MustCompile(`^[a-z]{2}$`)
+
+// CheckLanguage проверяет, что language - двухбуквенный код языка ISO 639-1 в нижнем регистре, например ru.
+// Пустая строка допустима и означает, что язык не выбран.
+func CheckLanguage(language string) error {
+	if language == "" || languageRe.MatchString(language) {
+		return nil
+	}
+	return ErrBadLanguage
+}
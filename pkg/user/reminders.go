@@ -0,0 +1,42 @@
+package user
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxReminderDays - за сколько дней до дня рождения можно запросить напоминание.
+const MaxReminderDays = 30
+
+// NormalizeReminders проверяет, что каждое смещение лежит в [0, MaxReminderDays],
+// убирает повторы и сортирует по убыванию, чтобы раньше шли более ранние напоминания.
+func NormalizeReminders(reminders []int) ([]int, error) {
+	if len(reminders) == 0 {
+		return nil, ErrBadReminders
+	}
+
+	seen := make(map[int]bool, len(reminders))
+	result := make([]int, 0, len(reminders))
+	for _, r := range reminders {
+		if r < 0 || r > MaxReminderDays {
+			return nil, ErrBadReminders
+		}
+		if !seen[r] {
+			seen[r] = true
+			result = append(result, r)
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(result)))
+	return result, nil
+}
+
+// formatReminders сериализует смещения для колонки subscribes.reminders, например "7,1,0".
+func formatReminders(reminders []int) string {
+	parts := make([]string, 0, len(reminders))
+	for _, r := range reminders {
+		parts = append(parts, strconv.Itoa(r))
+	}
+	return strings.Join(parts, ",")
+}
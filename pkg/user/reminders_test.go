@@ -0,0 +1,45 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeReminders(t *testing.T) {
+	tests := []struct {
+		name        string
+		reminders   []int
+		expected    []int
+		expectedErr error
+	}{
+		{
+			name:      "Sorted and deduplicated",
+			reminders: []int{1, 0, 7, 1},
+			expected:  []int{7, 1, 0},
+		},
+		{
+			name:        "Empty",
+			reminders:   nil,
+			expectedErr: ErrBadReminders,
+		},
+		{
+			name:        "Negative",
+			reminders:   []int{-1},
+			expectedErr: ErrBadReminders,
+		},
+		{
+			name:        "Too far",
+			reminders:   []int{MaxReminderDays + 1},
+			expectedErr: ErrBadReminders,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reminders, err := NormalizeReminders(tt.reminders)
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expected, reminders)
+		})
+	}
+}
@@ -5,6 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"golang.org/x/crypto/bcrypt"
+	"math"
+	"sort"
+	"strings"
+	"time"
 
 	_ "github.com/lib/pq"
 )
@@ -13,14 +17,26 @@ var (
 	ErrNoUser  = errors.New("no user found")
 	ErrBadPass = errors.New("invalid password")
 	ErrExists  = errors.New("already exists")
+	ErrBadDays = errors.New("days must not be negative")
+
+	ErrNoSubscription = errors.New("no subscription found")
+	ErrBadReminders   = errors.New("reminders must be days from 0 to 30")
+	ErrBadTimeZone    = errors.New("unknown time zone")
+	ErrBadSettings    = errors.New("hours must be from 0 to 23, mute date YYYY-MM-DD and summary weekly or monthly")
+	ErrBadLanguage    = errors.New("language must be a two-letter code")
+	ErrBadGender      = errors.New("gender must be male, female or empty")
+
+	ErrBadCongratulation = errors.New("congratulation must be from 1 to 500 characters")
 )
 
 type UserMysqlRepository struct {
 	DB *sql.DB
+	// LeapDay - когда поздравлять родившихся 29 февраля в невисокосный год.
+	LeapDay LeapDayPolicy
 }
 
-func NewMysqlRepo(db *sql.DB) *UserMysqlRepository {
-	return &UserMysqlRepository{DB: db}
+func NewMysqlRepo(db *sql.DB, leapDay LeapDayPolicy) *UserMysqlRepository {
+	return &UserMysqlRepository{DB: db, LeapDay: leapDay}
 }
 
 func (repo *UserMysqlRepository) Authorize(username, pass string) (*User, error) {
@@ -41,21 +57,36 @@ func (repo *UserMysqlRepository) Authorize(username, pass string) (*User, error)
 	return user, nil
 }
 
-func (repo *UserMysqlRepository) MakeUser(username, pass, firstname, middlename, lastname, birthday, telegram string) (*User, error) {
-	hashedPass, err := hashPassword(pass)
+// MakeUser создаёт пользователя u. u.Password - пароль в открытом виде, в базу пишется его хеш; ID не учитывается.
+func (repo *UserMysqlRepository) MakeUser(u User) (*User, error) {
+	if err := CheckTimeZone(u.TimeZone); err != nil {
+		return nil, err
+	}
+	if err := CheckLanguage(u.Language); err != nil {
+		return nil, err
+	}
+	if err := CheckGender(u.Gender); err != nil {
+		return nil, err
+	}
+
+	hashedPass, err := hashPassword(u.Password)
 	if err != nil {
 		return nil, err
 	}
 
 	result, err := repo.DB.Exec(
-		"INSERT INTO users (`username`, `password`, `firstname`, `middlename`, `lastname`, `birthday`, `telegram`) VALUES (?, ?, ?, ?, ?, ?, ?)",
-		username,
+		"INSERT INTO users (`username`, `password`, `firstname`, `middlename`, `lastname`, `birthday`, `telegram`, `timezone`, `department`, `language`, `gender`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		u.Username,
 		hashedPass,
-		firstname,
-		middlename,
-		lastname,
-		birthday,
-		telegram,
+		u.FirstName,
+		u.MiddleName,
+		u.LastName,
+		u.Birthday,
+		u.Telegram,
+		u.TimeZone,
+		u.Department,
+		u.Language,
+		u.Gender,
 	)
 	if err != nil {
 		return nil, ErrExists
@@ -65,7 +96,7 @@ func (repo *UserMysqlRepository) MakeUser(username, pass, firstname, middlename,
 	if err != nil {
 		return nil, err
 	}
-	return &User{ID: userID, Username: username}, nil
+	return &User{ID: userID, Username: u.Username}, nil
 }
 
 func hashPassword(password string) (string, error) {
@@ -154,9 +185,82 @@ func (repo *UserMysqlRepository) GetSubscribedUsers(userID int64) ([]User, error
 	var users []User
 	for rows.Next() {
 		var user User
-		if err = rows.Scan(&user.ID, &user.Username, &user.FirstName, &user.MiddleName, &user.LastName, &user.Birthday, &user.Telegram, &user.TelegramID); err != nil {
+		// telegramID равен NULL, пока пользователь не написал боту /start.
+		var telegramID sql.NullInt64
+		if err = rows.Scan(&user.ID, &user.Username, &user.FirstName, &user.MiddleName, &user.LastName, &user.Birthday, &user.Telegram, &telegramID); err != nil {
+			return nil, err
+		}
+		user.TelegramID = telegramID.Int64
+		users = append(users, user)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(users) == 0 {
+		return nil, ErrNoUser
+	}
+
+	return users, nil
+}
+
+func (repo *UserMysqlRepository) GetSubscriptions(subscriberID int64) ([]User, error) {
+	rows, err := repo.DB.Query(`
+		SELECT u.id, u.username, u.firstname, u.middlename, u.lastname, u.birthday, u.telegram
+		FROM users u
+		JOIN subscribes s ON u.id = s.userID
+		WHERE s.subscriberID = ?`, subscriberID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var user User
+		if err = rows.Scan(&user.ID, &user.Username, &user.FirstName, &user.MiddleName, &user.LastName, &user.Birthday, &user.Telegram); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(users) == 0 {
+		return nil, ErrNoUser
+	}
+
+	return users, nil
+}
+
+// GetSubscribersToRemind возвращает подписчиков userID из часового пояса timezone,
+// которые просили напомнить о его дне рождения за daysBefore дней.
+func (repo *UserMysqlRepository) GetSubscribersToRemind(userID int64, daysBefore int, timezone string) ([]User, error) {
+	rows, err := repo.DB.Query(`
+		SELECT u.id, u.username, u.firstname, u.middlename, u.lastname, u.birthday, u.telegram, u.telegramID, u.timezone, u.department, u.language,
+			st.userID, st.notifyHour, st.quietFrom, st.quietTo, st.mutedUntil, st.digest, st.summary, st.greet
+		FROM users u
+		JOIN subscribes s ON u.id = s.subscriberID
+		LEFT JOIN settings st ON u.id = st.userID
+		WHERE s.userID = ? AND FIND_IN_SET(?, s.reminders) > 0 AND u.timezone = ?`, userID, daysBefore, timezone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var user User
+		// telegramID равен NULL, пока пользователь не написал боту /start.
+		var telegramID sql.NullInt64
+		var st settingsRow
+		if err = rows.Scan(&user.ID, &user.Username, &user.FirstName, &user.MiddleName, &user.LastName, &user.Birthday, &user.Telegram, &telegramID, &user.TimeZone, &user.Department, &user.Language,
+			&st.userID, &st.notifyHour, &st.quietFrom, &st.quietTo, &st.mutedUntil, &st.digest, &st.summary, &st.greet); err != nil {
 			return nil, err
 		}
+		user.TelegramID = telegramID.Int64
+		user.Settings = st.settings()
 		users = append(users, user)
 	}
 	if err = rows.Err(); err != nil {
@@ -170,12 +274,39 @@ func (repo *UserMysqlRepository) GetSubscribedUsers(userID int64) ([]User, error
 	return users, nil
 }
 
+// SetReminders задаёт, за сколько дней до дня рождения userID напоминать подписчику subscriberID.
+func (repo *UserMysqlRepository) SetReminders(userID int64, subscriberID int64, reminders []int) ([]int, error) {
+	reminders, err := NormalizeReminders(reminders)
+	if err != nil {
+		return nil, err
+	}
+
+	var id int64
+	err = repo.DB.
+		QueryRow("SELECT id FROM subscribes WHERE `userID` = ? and `subscriberID` = ?", userID, subscriberID).
+		Scan(&id)
+	if err != nil {
+		return nil, ErrNoSubscription
+	}
+
+	_, err = repo.DB.Exec(
+		"UPDATE subscribes SET `reminders` = ? WHERE `id` = ?",
+		formatReminders(reminders),
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return reminders, nil
+}
+
 func (repo *UserMysqlRepository) GetUserByTelegram(telegram string) (*User, error) {
 	user := &User{}
 
 	err := repo.DB.
-		QueryRow("SELECT id, username, telegram FROM users WHERE telegram = ?", telegram).
-		Scan(&user.ID, &user.Username, &user.Telegram)
+		QueryRow("SELECT id, username, telegram, timezone, language FROM users WHERE telegram = ?", telegram).
+		Scan(&user.ID, &user.Username, &user.Telegram, &user.TimeZone, &user.Language)
 	if err != nil {
 		return nil, ErrNoUser
 	}
@@ -183,11 +314,23 @@ func (repo *UserMysqlRepository) GetUserByTelegram(telegram string) (*User, erro
 	return user, nil
 }
 
-func (repo *UserMysqlRepository) GetUserByBirthday(month, day int) ([]User, error) {
+// GetCelebrantsToGreet возвращает пользователей из часового пояса timezone, которых поздравляют в день day
+// и которые включили поздравления от бота или которым подписчики оставили поздравления.
+// В невисокосный год сюда попадают и родившиеся 29 февраля, если по правилу repo.LeapDay их поздравляют в day.
+func (repo *UserMysqlRepository) GetCelebrantsToGreet(day time.Time, timezone string) ([]User, error) {
+	birthday := "MONTH(u.birthday) = ? AND DAY(u.birthday) = ?"
+	if isLeapDaySubstitute(day, repo.LeapDay) {
+		birthday += " OR (MONTH(u.birthday) = 2 AND DAY(u.birthday) = 29)"
+	}
+
 	rows, err := repo.DB.Query(`
-		SELECT id, username, firstname, middlename, lastname, birthday, telegram
-		FROM users
-		WHERE MONTH(birthday) = ? AND DAY(birthday) = ?`, month, day)
+		SELECT u.id, u.username, u.firstname, u.middlename, u.lastname, u.birthday, u.telegram, u.telegramID, u.timezone,
+			u.department, u.language, u.gender,
+			st.userID, st.notifyHour, st.quietFrom, st.quietTo, st.mutedUntil, st.digest, st.summary, st.greet
+		FROM users u
+		LEFT JOIN settings st ON u.id = st.userID
+		WHERE (st.greet OR EXISTS (SELECT 1 FROM congratulations c WHERE c.userID = u.id))
+			AND u.timezone = ? AND (`+birthday+`)`, timezone, int(day.Month()), day.Day())
 	if err != nil {
 		return nil, err
 	}
@@ -196,7 +339,78 @@ func (repo *UserMysqlRepository) GetUserByBirthday(month, day int) ([]User, erro
 	var users []User
 	for rows.Next() {
 		var user User
-		if err = rows.Scan(&user.ID, &user.Username, &user.FirstName, &user.MiddleName, &user.LastName, &user.Birthday, &user.Telegram); err != nil {
+		var telegramID sql.NullInt64
+		var st settingsRow
+		if err = rows.Scan(&user.ID, &user.Username, &user.FirstName, &user.MiddleName, &user.LastName, &user.Birthday, &user.Telegram, &telegramID, &user.TimeZone,
+			&user.Department, &user.Language, &user.Gender,
+			&st.userID, &st.notifyHour, &st.quietFrom, &st.quietTo, &st.mutedUntil, &st.digest, &st.summary, &st.greet); err != nil {
+			return nil, err
+		}
+		user.TelegramID = telegramID.Int64
+		user.Settings = st.settings()
+		users = append(users, user)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(users) == 0 {
+		return nil, ErrNoUser
+	}
+
+	return users, nil
+}
+
+// GetUpcomingBirthdays возвращает пользователей, у которых день рождения в ближайшие days дней начиная с from,
+// отсортированных по близости дня рождения. Если subscriberID не 0, учитываются только его подписки.
+func (repo *UserMysqlRepository) GetUpcomingBirthdays(from time.Time, days int, subscriberID int64) ([]User, error) {
+	if days < 0 {
+		return nil, ErrBadDays
+	}
+
+	query := "SELECT u.id, u.username, u.firstname, u.middlename, u.lastname, u.birthday, u.telegram, u.timezone, u.department, u.gender FROM users u"
+	var conds []string
+	var args []interface{}
+
+	if subscriberID != 0 {
+		query += " JOIN subscribes s ON u.id = s.userID"
+		conds = append(conds, "s.subscriberID = ?")
+		args = append(args, subscriberID)
+	}
+
+	// Дата сравнивается как число MMDD; если интервал переходит через новый год, он разбивается на два.
+	if days < 365 {
+		start := monthDay(from)
+		end := monthDay(from.AddDate(0, 0, days))
+		var cond string
+		if start <= end {
+			cond = "(MONTH(u.birthday) * 100 + DAY(u.birthday)) BETWEEN ? AND ?"
+		} else {
+			cond = "((MONTH(u.birthday) * 100 + DAY(u.birthday)) >= ? OR (MONTH(u.birthday) * 100 + DAY(u.birthday)) <= ?)"
+		}
+		// В невисокосный год 0229 нет среди дат интервала, поэтому родившиеся 29 февраля добавляются отдельно,
+		// если день, в который их поздравляют, попал в интервал.
+		if leapDayInRange(from, days, repo.LeapDay) {
+			cond = "(" + cond + " OR (MONTH(u.birthday) = 2 AND DAY(u.birthday) = 29))"
+		}
+		conds = append(conds, cond)
+		args = append(args, start, end)
+	}
+
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	rows, err := repo.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var user User
+		if err = rows.Scan(&user.ID, &user.Username, &user.FirstName, &user.MiddleName, &user.LastName, &user.Birthday, &user.Telegram, &user.TimeZone, &user.Department, &user.Gender); err != nil {
 			return nil, err
 		}
 		users = append(users, user)
@@ -209,9 +423,52 @@ func (repo *UserMysqlRepository) GetUserByBirthday(month, day int) ([]User, erro
 		return nil, ErrNoUser
 	}
 
+	sort.SliceStable(users, func(i, j int) bool {
+		return upcomingOrder(users[i], from, repo.LeapDay) < upcomingOrder(users[j], from, repo.LeapDay)
+	})
+
 	return users, nil
 }
 
+// isLeapDaySubstitute сообщает, поздравляют ли в день day родившихся 29 февраля, потому что год невисокосный.
+func isLeapDaySubstitute(day time.Time, leapDay LeapDayPolicy) bool {
+	if isLeap(day.Year()) {
+		return false
+	}
+	substitute := leapDayIn(day.Year(), day.Location(), leapDay)
+	return day.Month() == substitute.Month() && day.Day() == substitute.Day()
+}
+
+// leapDayInRange сообщает, попадает ли в интервал из days дней начиная с from день,
+// в который невисокосного года поздравляют родившихся 29 февраля.
+func leapDayInRange(from time.Time, days int, leapDay LeapDayPolicy) bool {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	end := start.AddDate(0, 0, days)
+	for year := start.Year(); year <= end.Year(); year++ {
+		if isLeap(year) {
+			continue
+		}
+		day := leapDayIn(year, from.Location(), leapDay)
+		if !day.Before(start) && !day.After(end) {
+			return true
+		}
+	}
+	return false
+}
+
+func monthDay(t time.Time) int {
+	return int(t.Month())*100 + t.Day()
+}
+
+// upcomingOrder - сколько дней осталось до дня рождения; некорректные даты уходят в конец.
+func upcomingOrder(u User, from time.Time, leapDay LeapDayPolicy) int {
+	next, err := NextBirthday(u.Birthday, from, leapDay)
+	if err != nil {
+		return math.MaxInt
+	}
+	return DaysUntil(next, from)
+}
+
 func (repo *UserMysqlRepository) UpdateUser(telegramID int64, telegram string) error {
 	result, err := repo.DB.Exec(
 		"UPDATE users SET telegramID = ? WHERE telegram = ?",
@@ -233,3 +490,225 @@ func (repo *UserMysqlRepository) UpdateUser(telegramID int64, telegram string) e
 
 	return nil
 }
+
+// SetTimeZone задаёт часовой пояс пользователя; пустая строка возвращает часовой пояс сервиса.
+func (repo *UserMysqlRepository) SetTimeZone(userID int64, timezone string) error {
+	if err := CheckTimeZone(timezone); err != nil {
+		return err
+	}
+
+	_, err := repo.DB.Exec(
+		"UPDATE users SET `timezone` = ? WHERE `id` = ?",
+		timezone,
+		userID,
+	)
+	return err
+}
+
+// SetLanguage задаёт язык сообщений пользователя; пустая строка возвращает язык его телеграма.
+func (repo *UserMysqlRepository) SetLanguage(userID int64, language string) error {
+	if err := CheckLanguage(language); err != nil {
+		return err
+	}
+
+	_, err := repo.DB.Exec(
+		"UPDATE users SET `language` = ? WHERE `id` = ?",
+		language,
+		userID,
+	)
+	return err
+}
+
+// GetTimeZones возвращает все часовые пояса, заданные пользователями, включая пустой - часовой пояс сервиса.
+func (repo *UserMysqlRepository) GetTimeZones() ([]string, error) {
+	rows, err := repo.DB.Query("SELECT DISTINCT timezone FROM users")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var zones []string
+	for rows.Next() {
+		var zone string
+		if err = rows.Scan(&zone); err != nil {
+			return nil, err
+		}
+		zones = append(zones, zone)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return zones, nil
+}
+
+// GetSettings возвращает настройки напоминаний пользователя. Если он их не менял, возвращаются DefaultSettings.
+func (repo *UserMysqlRepository) GetSettings(userID int64) (*Settings, error) {
+	var st settingsRow
+	err := repo.DB.
+		QueryRow("SELECT userID, notifyHour, quietFrom, quietTo, mutedUntil, digest, summary, greet FROM settings WHERE userID = ?", userID).
+		Scan(&st.userID, &st.notifyHour, &st.quietFrom, &st.quietTo, &st.mutedUntil, &st.digest, &st.summary, &st.greet)
+	if errors.Is(err, sql.ErrNoRows) {
+		s := DefaultSettings()
+		return &s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return st.settings(), nil
+}
+
+// SaveSettings сохраняет настройки напоминаний пользователя.
+func (repo *UserMysqlRepository) SaveSettings(userID int64, s Settings) error {
+	if err := CheckSettings(s); err != nil {
+		return err
+	}
+
+	var mutedUntil sql.NullString
+	if s.MutedUntil != "" {
+		mutedUntil = sql.NullString{String: s.MutedUntil, Valid: true}
+	}
+
+	_, err := repo.DB.Exec(
+		"INSERT INTO settings (`userID`, `notifyHour`, `quietFrom`, `quietTo`, `mutedUntil`, `digest`, `summary`, `greet`) VALUES (?, ?, ?, ?, ?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE `notifyHour` = VALUES(`notifyHour`), `quietFrom` = VALUES(`quietFrom`), `quietTo` = VALUES(`quietTo`), "+
+			"`mutedUntil` = VALUES(`mutedUntil`), `digest` = VALUES(`digest`), `summary` = VALUES(`summary`), `greet` = VALUES(`greet`)",
+		userID,
+		s.NotifyHour,
+		s.QuietFrom,
+		s.QuietTo,
+		mutedUntil,
+		s.Digest,
+		s.Summary,
+		s.Greet,
+	)
+	return err
+}
+
+// GetSummarySubscribers возвращает пользователей из часового пояса timezone, выбравших сводку за период period.
+func (repo *UserMysqlRepository) GetSummarySubscribers(period, timezone string) ([]User, error) {
+	rows, err := repo.DB.Query(`
+		SELECT u.id, u.username, u.firstname, u.middlename, u.lastname, u.birthday, u.telegram, u.telegramID, u.timezone, u.language,
+			st.userID, st.notifyHour, st.quietFrom, st.quietTo, st.mutedUntil, st.digest, st.summary, st.greet
+		FROM users u
+		JOIN settings st ON u.id = st.userID
+		WHERE st.summary = ? AND u.timezone = ?`, period, timezone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var user User
+		var telegramID sql.NullInt64
+		var st settingsRow
+		if err = rows.Scan(&user.ID, &user.Username, &user.FirstName, &user.MiddleName, &user.LastName, &user.Birthday, &user.Telegram, &telegramID, &user.TimeZone, &user.Language,
+			&st.userID, &st.notifyHour, &st.quietFrom, &st.quietTo, &st.mutedUntil, &st.digest, &st.summary, &st.greet); err != nil {
+			return nil, err
+		}
+		user.TelegramID = telegramID.Int64
+		user.Settings = st.settings()
+		users = append(users, user)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(users) == 0 {
+		return nil, ErrNoUser
+	}
+
+	return users, nil
+}
+
+// settingsRow - строка таблицы settings; все колонки могут быть NULL, если она присоединена через LEFT JOIN.
+type settingsRow struct {
+	userID     sql.NullInt64
+	notifyHour sql.NullInt64
+	quietFrom  sql.NullInt64
+	quietTo    sql.NullInt64
+	mutedUntil sql.NullString
+	digest     sql.NullBool
+	summary    sql.NullString
+	greet      sql.NullBool
+}
+
+// settings возвращает настройки из строки или nil, если пользователь их не задавал.
+func (st settingsRow) settings() *Settings {
+	if !st.userID.Valid {
+		return nil
+	}
+	return &Settings{
+		NotifyHour: int(st.notifyHour.Int64),
+		QuietFrom:  int(st.quietFrom.Int64),
+		QuietTo:    int(st.quietTo.Int64),
+		MutedUntil: st.mutedUntil.String,
+		Digest:     st.digest.Bool,
+		Summary:    st.summary.String,
+		Greet:      st.greet.Bool,
+	}
+}
+
+// SaveCongratulation сохраняет поздравление, которое подписчик c.AuthorID оставил имениннику c.UserID.
+// Поздравление того же автора тому же имениннику заменяется.
+func (repo *UserMysqlRepository) SaveCongratulation(c Congratulation) error {
+	if err := CheckCongratulation(c.Text); err != nil {
+		return err
+	}
+
+	var id int64
+	err := repo.DB.
+		QueryRow("SELECT id FROM subscribes WHERE `userID` = ? and `subscriberID` = ?", c.UserID, c.AuthorID).
+		Scan(&id)
+	if err != nil {
+		return ErrNoSubscription
+	}
+
+	_, err = repo.DB.Exec(
+		"INSERT INTO congratulations (`userID`, `authorID`, `anonymous`, `text`) VALUES (?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE `anonymous` = VALUES(`anonymous`), `text` = VALUES(`text`)",
+		c.UserID,
+		c.AuthorID,
+		c.Anonymous,
+		strings.TrimSpace(c.Text),
+	)
+	return err
+}
+
+// GetCongratulations возвращает поздравления, которые оставили имениннику userID, в порядке сохранения.
+func (repo *UserMysqlRepository) GetCongratulations(userID int64) ([]Congratulation, error) {
+	rows, err := repo.DB.Query(`
+		SELECT c.id, c.userID, c.authorID, u.firstname, u.lastname, c.anonymous, c.text
+		FROM congratulations c
+		JOIN users u ON u.id = c.authorID
+		WHERE c.userID = ?
+		ORDER BY c.id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	congratulations := []Congratulation{}
+	for rows.Next() {
+		var c Congratulation
+		var firstname, lastname string
+		if err = rows.Scan(&c.ID, &c.UserID, &c.AuthorID, &firstname, &lastname, &c.Anonymous, &c.Text); err != nil {
+			return nil, err
+		}
+		c.Author = strings.TrimSpace(firstname + " " + lastname)
+		congratulations = append(congratulations, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return congratulations, nil
+}
+
+// DeleteCongratulation удаляет поздравление, которое уже переслано имениннику.
+func (repo *UserMysqlRepository) DeleteCongratulation(id int64) error {
+	_, err := repo.DB.Exec("DELETE FROM congratulations WHERE `id` = ?", id)
+	return err
+}
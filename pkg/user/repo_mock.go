@@ -6,6 +6,7 @@ package user
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -48,6 +49,65 @@ func (mr *MockUserRepoMockRecorder) Authorize(username, pass interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockUserRepo)(nil).Authorize), username, pass)
 }
 
+// DeleteCongratulation mocks base method.
+func (m *MockUserRepo) DeleteCongratulation(id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCongratulation", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCongratulation indicates an expected call of DeleteCongratulation.
+func (mr *MockUserRepoMockRecorder) DeleteCongratulation(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCongratulation", reflect.TypeOf((*MockUserRepo)(nil).DeleteCongratulation), id)
+}
+
+// GetCelebrantsToGreet mocks base method.
+func (m *MockUserRepo) GetCelebrantsToGreet(day time.Time, timezone string) ([]User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCelebrantsToGreet", day, timezone)
+	ret0, _ := ret[0].([]User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCelebrantsToGreet indicates an expected call of GetCelebrantsToGreet.
+func (mr *MockUserRepoMockRecorder) GetCelebrantsToGreet(day, timezone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCelebrantsToGreet", reflect.TypeOf((*MockUserRepo)(nil).GetCelebrantsToGreet), day, timezone)
+}
+
+// GetCongratulations mocks base method.
+func (m *MockUserRepo) GetCongratulations(userID int64) ([]Congratulation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCongratulations", userID)
+	ret0, _ := ret[0].([]Congratulation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCongratulations indicates an expected call of GetCongratulations.
+func (mr *MockUserRepoMockRecorder) GetCongratulations(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCongratulations", reflect.TypeOf((*MockUserRepo)(nil).GetCongratulations), userID)
+}
+
+// GetSettings mocks base method.
+func (m *MockUserRepo) GetSettings(userID int64) (*Settings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSettings", userID)
+	ret0, _ := ret[0].(*Settings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSettings indicates an expected call of GetSettings.
+func (mr *MockUserRepoMockRecorder) GetSettings(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSettings", reflect.TypeOf((*MockUserRepo)(nil).GetSettings), userID)
+}
+
 // GetSubscribedUsers mocks base method.
 func (m *MockUserRepo) GetSubscribedUsers(userID int64) ([]User, error) {
 	m.ctrl.T.Helper()
@@ -63,6 +123,81 @@ func (mr *MockUserRepoMockRecorder) GetSubscribedUsers(userID interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscribedUsers", reflect.TypeOf((*MockUserRepo)(nil).GetSubscribedUsers), userID)
 }
 
+// GetSubscribersToRemind mocks base method.
+func (m *MockUserRepo) GetSubscribersToRemind(userID int64, daysBefore int, timezone string) ([]User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscribersToRemind", userID, daysBefore, timezone)
+	ret0, _ := ret[0].([]User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscribersToRemind indicates an expected call of GetSubscribersToRemind.
+func (mr *MockUserRepoMockRecorder) GetSubscribersToRemind(userID, daysBefore, timezone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscribersToRemind", reflect.TypeOf((*MockUserRepo)(nil).GetSubscribersToRemind), userID, daysBefore, timezone)
+}
+
+// GetSubscriptions mocks base method.
+func (m *MockUserRepo) GetSubscriptions(subscriberID int64) ([]User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptions", subscriberID)
+	ret0, _ := ret[0].([]User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptions indicates an expected call of GetSubscriptions.
+func (mr *MockUserRepoMockRecorder) GetSubscriptions(subscriberID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptions", reflect.TypeOf((*MockUserRepo)(nil).GetSubscriptions), subscriberID)
+}
+
+// GetSummarySubscribers mocks base method.
+func (m *MockUserRepo) GetSummarySubscribers(period, timezone string) ([]User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSummarySubscribers", period, timezone)
+	ret0, _ := ret[0].([]User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSummarySubscribers indicates an expected call of GetSummarySubscribers.
+func (mr *MockUserRepoMockRecorder) GetSummarySubscribers(period, timezone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSummarySubscribers", reflect.TypeOf((*MockUserRepo)(nil).GetSummarySubscribers), period, timezone)
+}
+
+// GetTimeZones mocks base method.
+func (m *MockUserRepo) GetTimeZones() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTimeZones")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTimeZones indicates an expected call of GetTimeZones.
+func (mr *MockUserRepoMockRecorder) GetTimeZones() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimeZones", reflect.TypeOf((*MockUserRepo)(nil).GetTimeZones))
+}
+
+// GetUpcomingBirthdays mocks base method.
+func (m *MockUserRepo) GetUpcomingBirthdays(from time.Time, days int, subscriberID int64) ([]User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUpcomingBirthdays", from, days, subscriberID)
+	ret0, _ := ret[0].([]User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUpcomingBirthdays indicates an expected call of GetUpcomingBirthdays.
+func (mr *MockUserRepoMockRecorder) GetUpcomingBirthdays(from, days, subscriberID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUpcomingBirthdays", reflect.TypeOf((*MockUserRepo)(nil).GetUpcomingBirthdays), from, days, subscriberID)
+}
+
 // GetUserByTelegram mocks base method.
 func (m *MockUserRepo) GetUserByTelegram(telegram string) (*User, error) {
 	m.ctrl.T.Helper()
@@ -94,18 +229,89 @@ func (mr *MockUserRepoMockRecorder) GetUsers() *gomock.Call {
 }
 
 // MakeUser mocks base method.
-func (m *MockUserRepo) MakeUser(username, pass, firstname, middlename, lastname, birthday, telegram string) (*User, error) {
+func (m *MockUserRepo) MakeUser(u User) (*User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "MakeUser", username, pass, firstname, middlename, lastname, birthday, telegram)
+	ret := m.ctrl.Call(m, "MakeUser", u)
 	ret0, _ := ret[0].(*User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // MakeUser indicates an expected call of MakeUser.
-func (mr *MockUserRepoMockRecorder) MakeUser(username, pass, firstname, middlename, lastname, birthday, telegram interface{}) *gomock.Call {
+func (mr *MockUserRepoMockRecorder) MakeUser(u interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MakeUser", reflect.TypeOf((*MockUserRepo)(nil).MakeUser), u)
+}
+
+// SaveCongratulation mocks base method.
+func (m *MockUserRepo) SaveCongratulation(c Congratulation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCongratulation", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCongratulation indicates an expected call of SaveCongratulation.
+func (mr *MockUserRepoMockRecorder) SaveCongratulation(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCongratulation", reflect.TypeOf((*MockUserRepo)(nil).SaveCongratulation), c)
+}
+
+// SaveSettings mocks base method.
+func (m *MockUserRepo) SaveSettings(userID int64, s Settings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSettings", userID, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSettings indicates an expected call of SaveSettings.
+func (mr *MockUserRepoMockRecorder) SaveSettings(userID, s interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSettings", reflect.TypeOf((*MockUserRepo)(nil).SaveSettings), userID, s)
+}
+
+// SetLanguage mocks base method.
+func (m *MockUserRepo) SetLanguage(userID int64, language string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLanguage", userID, language)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLanguage indicates an expected call of SetLanguage.
+func (mr *MockUserRepoMockRecorder) SetLanguage(userID, language interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLanguage", reflect.TypeOf((*MockUserRepo)(nil).SetLanguage), userID, language)
+}
+
+// SetReminders mocks base method.
+func (m *MockUserRepo) SetReminders(userID, subscriberID int64, reminders []int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReminders", userID, subscriberID, reminders)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetReminders indicates an expected call of SetReminders.
+func (mr *MockUserRepoMockRecorder) SetReminders(userID, subscriberID, reminders interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReminders", reflect.TypeOf((*MockUserRepo)(nil).SetReminders), userID, subscriberID, reminders)
+}
+
+// SetTimeZone mocks base method.
+func (m *MockUserRepo) SetTimeZone(userID int64, timezone string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTimeZone", userID, timezone)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTimeZone indicates an expected call of SetTimeZone.
+func (mr *MockUserRepoMockRecorder) SetTimeZone(userID, timezone interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MakeUser", reflect.TypeOf((*MockUserRepo)(nil).MakeUser), username, pass, firstname, middlename, lastname, birthday, telegram)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTimeZone", reflect.TypeOf((*MockUserRepo)(nil).SetTimeZone), userID, timezone)
 }
 
 // Subscribe mocks base method.
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
@@ -19,7 +21,7 @@ func TestAuthorize(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := NewMysqlRepo(db)
+	repo := NewMysqlRepo(db, LeapDayMar1)
 
 	tests := []struct {
 		name     string
@@ -91,12 +93,15 @@ func TestMakeUser(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := NewMysqlRepo(db)
+	repo := NewMysqlRepo(db, LeapDayMar1)
 
 	tests := []struct {
 		name     string
 		username string
 		password string
+		timezone string
+		language string
+		gender   string
 		mockFunc func()
 		expected error
 	}{
@@ -105,8 +110,8 @@ func TestMakeUser(t *testing.T) {
 			username: "user1",
 			password: "password1",
 			mockFunc: func() {
-				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (`username`, `password`, `firstname`, `middlename`, `lastname`, `birthday`, `telegram`) VALUES (?, ?, ?, ?, ?, ?, ?)")).
-					WithArgs("user1", sqlmock.AnyArg(), "John", "M", "Doe", "1990-01-01", "@john").
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (`username`, `password`, `firstname`, `middlename`, `lastname`, `birthday`, `telegram`, `timezone`, `department`, `language`, `gender`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")).
+					WithArgs("user1", sqlmock.AnyArg(), "John", "M", "Doe", "1990-01-01", "@john", "Europe/Moscow", "QA", "en", "male").
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			expected: nil,
@@ -116,18 +121,55 @@ func TestMakeUser(t *testing.T) {
 			username: "user1",
 			password: "password1",
 			mockFunc: func() {
-				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (`username`, `password`, `firstname`, `middlename`, `lastname`, `birthday`, `telegram`) VALUES (?, ?, ?, ?, ?, ?, ?)")).
-					WithArgs("user1", sqlmock.AnyArg(), "John", "M", "Doe", "1990-01-01", "@john").
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (`username`, `password`, `firstname`, `middlename`, `lastname`, `birthday`, `telegram`, `timezone`, `department`, `language`, `gender`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")).
+					WithArgs("user1", sqlmock.AnyArg(), "John", "M", "Doe", "1990-01-01", "@john", "Europe/Moscow", "QA", "en", "male").
 					WillReturnError(ErrExists)
 			},
 			expected: ErrExists,
 		},
+		{
+			name:     "Unknown time zone",
+			username: "user1",
+			password: "password1",
+			timezone: "Mars/Olympus",
+			mockFunc: func() {},
+			expected: ErrBadTimeZone,
+		},
+		{
+			name:     "Unknown language",
+			username: "user1",
+			password: "password1",
+			language: "english",
+			mockFunc: func() {},
+			expected: ErrBadLanguage,
+		},
+		{
+			name:     "Unknown gender",
+			username: "user1",
+			password: "password1",
+			gender:   "robot",
+			mockFunc: func() {},
+			expected: ErrBadGender,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFunc()
-			_, err := repo.MakeUser(tt.username, tt.password, "John", "M", "Doe", "1990-01-01", "@john")
+			timezone := tt.timezone
+			if timezone == "" {
+				timezone = "Europe/Moscow"
+			}
+			language := tt.language
+			if language == "" {
+				language = "en"
+			}
+			gender := tt.gender
+			if gender == "" {
+				gender = GenderMale
+			}
+			_, err := repo.MakeUser(User{Username: tt.username, Password: tt.password, FirstName: "John", MiddleName: "M", LastName: "Doe",
+				Birthday: "1990-01-01", Telegram: "@john", TimeZone: timezone, Department: "QA", Language: language, Gender: gender})
 			assert.Equal(t, tt.expected, err)
 		})
 	}
@@ -140,7 +182,7 @@ func TestGetUsers(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := NewMysqlRepo(db)
+	repo := NewMysqlRepo(db, LeapDayMar1)
 
 	tests := []struct {
 		name     string
@@ -184,7 +226,7 @@ func TestSubscribe(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := NewMysqlRepo(db)
+	repo := NewMysqlRepo(db, LeapDayMar1)
 
 	tests := []struct {
 		name         string
@@ -272,7 +314,7 @@ func TestGetSubscribedUsers(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := NewMysqlRepo(db)
+	repo := NewMysqlRepo(db, LeapDayMar1)
 
 	tests := []struct {
 		name        string
@@ -302,6 +344,25 @@ func TestGetSubscribedUsers(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		{
+			name:   "Subscriber without linked telegram",
+			userID: 1,
+			mockFunc: func() {
+				rows := sqlmock.NewRows([]string{"id", "username", "firstname", "middlename", "lastname", "birthday", "telegram", "telegramID"}).
+					AddRow(4, "user4", "Ivan", "I", "Petrov", "1992-03-03", "@ivan", nil)
+				mock.ExpectQuery(regexp.QuoteMeta(`
+					SELECT u.id, u.username, u.firstname, u.middlename, u.lastname, u.birthday, u.telegram, u.telegramID
+					FROM users u
+					JOIN subscribes s ON u.id = s.subscriberID
+					WHERE s.userID = ?`)).
+					WithArgs(1).
+					WillReturnRows(rows)
+			},
+			expected: []User{
+				{ID: 4, Username: "user4", FirstName: "Ivan", MiddleName: "I", LastName: "Petrov", Birthday: "1992-03-03", Telegram: "@ivan", TelegramID: 0},
+			},
+			expectedErr: nil,
+		},
 		{
 			name:   "No subscribed users",
 			userID: 1,
@@ -344,6 +405,235 @@ func TestGetSubscribedUsers(t *testing.T) {
 	}
 }
 
+func TestGetSubscriptions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db, LeapDayMar1)
+
+	query := regexp.QuoteMeta(`
+		SELECT u.id, u.username, u.firstname, u.middlename, u.lastname, u.birthday, u.telegram
+		FROM users u
+		JOIN subscribes s ON u.id = s.userID
+		WHERE s.subscriberID = ?`)
+
+	tests := []struct {
+		name         string
+		subscriberID int64
+		mockFunc     func()
+		expected     []User
+		expectedErr  error
+	}{
+		{
+			name:         "Get subscriptions",
+			subscriberID: 1,
+			mockFunc: func() {
+				rows := sqlmock.NewRows([]string{"id", "username", "firstname", "middlename", "lastname", "birthday", "telegram"}).
+					AddRow(2, "user2", "John", "M", "Doe", "1990-01-01", "@john")
+				mock.ExpectQuery(query).
+					WithArgs(1).
+					WillReturnRows(rows)
+			},
+			expected: []User{
+				{ID: 2, Username: "user2", FirstName: "John", MiddleName: "M", LastName: "Doe", Birthday: "1990-01-01", Telegram: "@john"},
+			},
+			expectedErr: nil,
+		},
+		{
+			name:         "No subscriptions",
+			subscriberID: 1,
+			mockFunc: func() {
+				mock.ExpectQuery(query).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(nil))
+			},
+			expected:    nil,
+			expectedErr: ErrNoUser,
+		},
+		{
+			name:         "Query error",
+			subscriberID: 1,
+			mockFunc: func() {
+				mock.ExpectQuery(query).
+					WithArgs(1).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expected:    nil,
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc()
+			users, err := repo.GetSubscriptions(tt.subscriberID)
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expected, users)
+		})
+	}
+}
+
+func TestGetSubscribersToRemind(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db, LeapDayMar1)
+
+	query := regexp.QuoteMeta(`
+		SELECT u.id, u.username, u.firstname, u.middlename, u.lastname, u.birthday, u.telegram, u.telegramID, u.timezone, u.department, u.language,
+			st.userID, st.notifyHour, st.quietFrom, st.quietTo, st.mutedUntil, st.digest, st.summary, st.greet
+		FROM users u
+		JOIN subscribes s ON u.id = s.subscriberID
+		LEFT JOIN settings st ON u.id = st.userID
+		WHERE s.userID = ? AND FIND_IN_SET(?, s.reminders) > 0 AND u.timezone = ?`)
+
+	tests := []struct {
+		name        string
+		userID      int64
+		daysBefore  int
+		mockFunc    func()
+		expected    []User
+		expectedErr error
+	}{
+		{
+			name:       "Subscribers to remind",
+			userID:     1,
+			daysBefore: 7,
+			mockFunc: func() {
+				rows := sqlmock.NewRows([]string{"id", "username", "firstname", "middlename", "lastname", "birthday", "telegram", "telegramID", "timezone", "department", "language",
+					"userID", "notifyHour", "quietFrom", "quietTo", "mutedUntil", "digest", "summary", "greet"}).
+					AddRow(2, "user2", "John", "M", "Doe", "1990-01-01", "@john", 1234, "Asia/Vladivostok", "QA", "en", 2, 8, 22, 7, "2024-07-01", true, "", false).
+					AddRow(3, "user3", "Jane", "D", "Smith", "1991-02-02", "@jane", nil, "Asia/Vladivostok", "", "", nil, nil, nil, nil, nil, nil, nil, nil)
+				mock.ExpectQuery(query).
+					WithArgs(1, 7, "Asia/Vladivostok").
+					WillReturnRows(rows)
+			},
+			expected: []User{
+				{ID: 2, Username: "user2", FirstName: "John", MiddleName: "M", LastName: "Doe", Birthday: "1990-01-01", Telegram: "@john", TelegramID: 1234, TimeZone: "Asia/Vladivostok",
+					Department: "QA", Language: "en", Settings: &Settings{NotifyHour: 8, QuietFrom: 22, QuietTo: 7, MutedUntil: "2024-07-01", Digest: true}},
+				{ID: 3, Username: "user3", FirstName: "Jane", MiddleName: "D", LastName: "Smith", Birthday: "1991-02-02", Telegram: "@jane", TelegramID: 0, TimeZone: "Asia/Vladivostok"},
+			},
+			expectedErr: nil,
+		},
+		{
+			name:       "Nobody to remind",
+			userID:     1,
+			daysBefore: 3,
+			mockFunc: func() {
+				mock.ExpectQuery(query).
+					WithArgs(1, 3, "Asia/Vladivostok").
+					WillReturnRows(sqlmock.NewRows(nil))
+			},
+			expected:    nil,
+			expectedErr: ErrNoUser,
+		},
+		{
+			name:       "Query error",
+			userID:     1,
+			daysBefore: 0,
+			mockFunc: func() {
+				mock.ExpectQuery(query).
+					WithArgs(1, 0, "Asia/Vladivostok").
+					WillReturnError(sql.ErrConnDone)
+			},
+			expected:    nil,
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc()
+			users, err := repo.GetSubscribersToRemind(tt.userID, tt.daysBefore, "Asia/Vladivostok")
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expected, users)
+		})
+	}
+}
+
+func TestSetReminders(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db, LeapDayMar1)
+
+	selectQuery := regexp.QuoteMeta("SELECT id FROM subscribes WHERE `userID` = ? and `subscriberID` = ?")
+	updateQuery := regexp.QuoteMeta("UPDATE subscribes SET `reminders` = ? WHERE `id` = ?")
+
+	tests := []struct {
+		name        string
+		reminders   []int
+		mockFunc    func()
+		expected    []int
+		expectedErr error
+	}{
+		{
+			name:      "Set reminders",
+			reminders: []int{0, 7, 1, 7},
+			mockFunc: func() {
+				mock.ExpectQuery(selectQuery).
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+				mock.ExpectExec(updateQuery).
+					WithArgs("7,1,0", 10).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expected:    []int{7, 1, 0},
+			expectedErr: nil,
+		},
+		{
+			name:        "Invalid reminders",
+			reminders:   []int{31},
+			mockFunc:    func() {},
+			expected:    nil,
+			expectedErr: ErrBadReminders,
+		},
+		{
+			name:      "No subscription",
+			reminders: []int{1},
+			mockFunc: func() {
+				mock.ExpectQuery(selectQuery).
+					WithArgs(1, 2).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expected:    nil,
+			expectedErr: ErrNoSubscription,
+		},
+		{
+			name:      "Update error",
+			reminders: []int{1},
+			mockFunc: func() {
+				mock.ExpectQuery(selectQuery).
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+				mock.ExpectExec(updateQuery).
+					WithArgs("1", 10).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expected:    nil,
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc()
+			reminders, err := repo.SetReminders(1, 2, tt.reminders)
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expected, reminders)
+		})
+	}
+}
+
 func TestGetUserByTelegram(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -351,7 +641,7 @@ func TestGetUserByTelegram(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := NewMysqlRepo(db)
+	repo := NewMysqlRepo(db, LeapDayMar1)
 
 	tests := []struct {
 		name        string
@@ -364,20 +654,20 @@ func TestGetUserByTelegram(t *testing.T) {
 			name:     "User exists",
 			telegram: "@john",
 			mockFunc: func() {
-				rows := sqlmock.NewRows([]string{"id", "username", "telegram"}).
-					AddRow(1, "user1", "@john")
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, username, telegram FROM users WHERE telegram = ?")).
+				rows := sqlmock.NewRows([]string{"id", "username", "telegram", "timezone", "language"}).
+					AddRow(1, "user1", "@john", "Europe/Moscow", "en")
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, username, telegram, timezone, language FROM users WHERE telegram = ?")).
 					WithArgs("@john").
 					WillReturnRows(rows)
 			},
-			expected:    &User{ID: 1, Username: "user1", Telegram: "@john"},
+			expected:    &User{ID: 1, Username: "user1", Telegram: "@john", TimeZone: "Europe/Moscow", Language: "en"},
 			expectedErr: nil,
 		},
 		{
 			name:     "User does not exist",
 			telegram: "@nonexistent",
 			mockFunc: func() {
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, username, telegram FROM users WHERE telegram = ?")).
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, username, telegram, timezone, language FROM users WHERE telegram = ?")).
 					WithArgs("@nonexistent").
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -396,68 +686,124 @@ func TestGetUserByTelegram(t *testing.T) {
 	}
 }
 
-func TestGetUserByBirthday(t *testing.T) {
+func TestGetUpcomingBirthdays(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 	defer db.Close()
 
-	repo := NewMysqlRepo(db)
+	repo := NewMysqlRepo(db, LeapDayMar1)
+
+	columns := []string{"id", "username", "firstname", "middlename", "lastname", "birthday", "telegram", "timezone", "department", "gender"}
+	selectUsers := "SELECT u.id, u.username, u.firstname, u.middlename, u.lastname, u.birthday, u.telegram, u.timezone, u.department, u.gender FROM users u"
 
 	tests := []struct {
-		name        string
-		month, day  int
-		mockFunc    func()
-		expected    []User
-		expectedErr error
+		name         string
+		from         time.Time
+		days         int
+		subscriberID int64
+		leapDay      LeapDayPolicy
+		mockFunc     func()
+		expected     []User
+		expectedErr  error
 	}{
 		{
-			name:  "Users with birthday",
-			month: 1,
-			day:   1,
+			name: "Interval inside one year",
+			from: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+			days: 7,
 			mockFunc: func() {
-				rows := sqlmock.NewRows([]string{"id", "username", "firstname", "middlename", "lastname", "birthday", "telegram"}).
-					AddRow(1, "user1", "John", "M", "Doe", "1990-01-01", "@john").
-					AddRow(2, "user2", "Jane", "D", "Smith", "1991-01-01", "@jane")
-				mock.ExpectQuery(regexp.QuoteMeta(`
-					SELECT id, username, firstname, middlename, lastname, birthday, telegram
-					FROM users
-					WHERE MONTH(birthday) = ? AND DAY(birthday) = ?`)).
-					WithArgs(1, 1).
+				rows := sqlmock.NewRows(columns).
+					AddRow(2, "user2", "Jane", "D", "Smith", "1991-03-08", "@jane", "", "", "").
+					AddRow(1, "user1", "John", "M", "Doe", "1990-03-02", "@john", "Asia/Vladivostok", "QA", "male")
+				mock.ExpectQuery(regexp.QuoteMeta(selectUsers+" WHERE (MONTH(u.birthday) * 100 + DAY(u.birthday)) BETWEEN ? AND ?")).
+					WithArgs(301, 308).
 					WillReturnRows(rows)
 			},
 			expected: []User{
-				{ID: 1, Username: "user1", FirstName: "John", MiddleName: "M", LastName: "Doe", Birthday: "1990-01-01", Telegram: "@john"},
-				{ID: 2, Username: "user2", FirstName: "Jane", MiddleName: "D", LastName: "Smith", Birthday: "1991-01-01", Telegram: "@jane"},
+				{ID: 1, Username: "user1", FirstName: "John", MiddleName: "M", LastName: "Doe", Birthday: "1990-03-02", Telegram: "@john", TimeZone: "Asia/Vladivostok", Department: "QA", Gender: GenderMale},
+				{ID: 2, Username: "user2", FirstName: "Jane", MiddleName: "D", LastName: "Smith", Birthday: "1991-03-08", Telegram: "@jane"},
 			},
 			expectedErr: nil,
 		},
 		{
-			name:  "No users with birthday",
-			month: 1,
-			day:   2,
+			name:         "Interval across new year for subscriptions",
+			from:         time.Date(2024, 12, 28, 0, 0, 0, 0, time.UTC),
+			days:         10,
+			subscriberID: 5,
 			mockFunc: func() {
-				mock.ExpectQuery(regexp.QuoteMeta(`
-					SELECT id, username, firstname, middlename, lastname, birthday, telegram
-					FROM users
-					WHERE MONTH(birthday) = ? AND DAY(birthday) = ?`)).
-					WithArgs(1, 2).
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, "user1", "John", "M", "Doe", "1990-01-03", "@john", "", "", "").
+					AddRow(2, "user2", "Jane", "D", "Smith", "1991-12-31", "@jane", "", "", "")
+				mock.ExpectQuery(regexp.QuoteMeta(selectUsers+" JOIN subscribes s ON u.id = s.userID"+
+					" WHERE s.subscriberID = ? AND ((MONTH(u.birthday) * 100 + DAY(u.birthday)) >= ? OR (MONTH(u.birthday) * 100 + DAY(u.birthday)) <= ?)")).
+					WithArgs(5, 1228, 107).
+					WillReturnRows(rows)
+			},
+			expected: []User{
+				{ID: 2, Username: "user2", FirstName: "Jane", MiddleName: "D", LastName: "Smith", Birthday: "1991-12-31", Telegram: "@jane"},
+				{ID: 1, Username: "user1", FirstName: "John", MiddleName: "M", LastName: "Doe", Birthday: "1990-01-03", Telegram: "@john"},
+			},
+			expectedErr: nil,
+		},
+		{
+			name:    "February 29 in a non-leap year when its substitute day is in range",
+			from:    time.Date(2023, 2, 26, 0, 0, 0, 0, time.UTC),
+			days:    2,
+			leapDay: LeapDayFeb28,
+			mockFunc: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, "user1", "John", "M", "Doe", "1992-02-29", "@john", "", "", "").
+					AddRow(2, "user2", "Jane", "D", "Smith", "1991-02-27", "@jane", "", "", "")
+				mock.ExpectQuery(regexp.QuoteMeta(selectUsers+
+					" WHERE ((MONTH(u.birthday) * 100 + DAY(u.birthday)) BETWEEN ? AND ? OR (MONTH(u.birthday) = 2 AND DAY(u.birthday) = 29))")).
+					WithArgs(226, 228).
+					WillReturnRows(rows)
+			},
+			expected: []User{
+				{ID: 2, Username: "user2", FirstName: "Jane", MiddleName: "D", LastName: "Smith", Birthday: "1991-02-27", Telegram: "@jane"},
+				{ID: 1, Username: "user1", FirstName: "John", MiddleName: "M", LastName: "Doe", Birthday: "1992-02-29", Telegram: "@john"},
+			},
+			expectedErr: nil,
+		},
+		{
+			name:    "February 29 is not added when its substitute day is out of range",
+			from:    time.Date(2023, 2, 26, 0, 0, 0, 0, time.UTC),
+			days:    2,
+			leapDay: LeapDayMar1,
+			mockFunc: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(selectUsers+" WHERE (MONTH(u.birthday) * 100 + DAY(u.birthday)) BETWEEN ? AND ?")).
+					WithArgs(226, 228).
 					WillReturnRows(sqlmock.NewRows(nil))
 			},
 			expected:    nil,
 			expectedErr: ErrNoUser,
 		},
 		{
-			name:  "Query error",
-			month: 1,
-			day:   1,
+			name: "Whole year",
+			from: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			days: 400,
 			mockFunc: func() {
-				mock.ExpectQuery(regexp.QuoteMeta(`
-					SELECT id, username, firstname, middlename, lastname, birthday, telegram
-					FROM users
-					WHERE MONTH(birthday) = ? AND DAY(birthday) = ?`)).
-					WithArgs(1, 1).
+				mock.ExpectQuery(regexp.QuoteMeta(selectUsers)).
+					WillReturnRows(sqlmock.NewRows(nil))
+			},
+			expected:    nil,
+			expectedErr: ErrNoUser,
+		},
+		{
+			name:        "Negative days",
+			from:        time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			days:        -1,
+			mockFunc:    func() {},
+			expected:    nil,
+			expectedErr: ErrBadDays,
+		},
+		{
+			name: "Query error",
+			from: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			days: 7,
+			mockFunc: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(selectUsers)).
 					WillReturnError(sql.ErrConnDone)
 			},
 			expected:    nil,
@@ -467,8 +813,9 @@ func TestGetUserByBirthday(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			repo.LeapDay = tt.leapDay
 			tt.mockFunc()
-			users, err := repo.GetUserByBirthday(tt.month, tt.day)
+			users, err := repo.GetUpcomingBirthdays(tt.from, tt.days, tt.subscriberID)
 			assert.Equal(t, tt.expectedErr, err)
 			assert.Equal(t, tt.expected, users)
 		})
@@ -482,7 +829,7 @@ func TestUpdateUser(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := NewMysqlRepo(db)
+	repo := NewMysqlRepo(db, LeapDayMar1)
 
 	tests := []struct {
 		name        string
@@ -534,3 +881,479 @@ func TestUpdateUser(t *testing.T) {
 		})
 	}
 }
+
+func TestSetTimeZone(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db, LeapDayMar1)
+
+	query := regexp.QuoteMeta("UPDATE users SET `timezone` = ? WHERE `id` = ?")
+
+	tests := []struct {
+		name        string
+		timezone    string
+		mockFunc    func()
+		expectedErr error
+	}{
+		{
+			name:     "Set time zone",
+			timezone: "Asia/Vladivostok",
+			mockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs("Asia/Vladivostok", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expectedErr: nil,
+		},
+		{
+			name:     "Reset to service time zone",
+			timezone: "",
+			mockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs("", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expectedErr: nil,
+		},
+		{
+			name:        "Unknown time zone",
+			timezone:    "Mars/Olympus",
+			mockFunc:    func() {},
+			expectedErr: ErrBadTimeZone,
+		},
+		{
+			name:        "Server local time zone",
+			timezone:    "Local",
+			mockFunc:    func() {},
+			expectedErr: ErrBadTimeZone,
+		},
+		{
+			name:     "Update error",
+			timezone: "UTC",
+			mockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs("UTC", 1).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc()
+			err := repo.SetTimeZone(1, tt.timezone)
+			assert.Equal(t, tt.expectedErr, err)
+		})
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db, LeapDayMar1)
+
+	query := regexp.QuoteMeta("UPDATE users SET `language` = ? WHERE `id` = ?")
+
+	tests := []struct {
+		name        string
+		language    string
+		mockFunc    func()
+		expectedErr error
+	}{
+		{
+			name:     "Set language",
+			language: "en",
+			mockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs("en", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expectedErr: nil,
+		},
+		{
+			name:     "Reset language",
+			language: "",
+			mockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs("", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expectedErr: nil,
+		},
+		{
+			name:        "Bad language",
+			language:    "English",
+			mockFunc:    func() {},
+			expectedErr: ErrBadLanguage,
+		},
+		{
+			name:     "Update error",
+			language: "ru",
+			mockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs("ru", 1).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc()
+			err := repo.SetLanguage(1, tt.language)
+			assert.Equal(t, tt.expectedErr, err)
+		})
+	}
+}
+
+func TestGetTimeZones(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db, LeapDayMar1)
+
+	query := regexp.QuoteMeta("SELECT DISTINCT timezone FROM users")
+
+	mock.ExpectQuery(query).
+		WillReturnRows(sqlmock.NewRows([]string{"timezone"}).AddRow("").AddRow("Asia/Vladivostok"))
+	zones, err := repo.GetTimeZones()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "Asia/Vladivostok"}, zones)
+
+	mock.ExpectQuery(query).
+		WillReturnError(sql.ErrConnDone)
+	_, err = repo.GetTimeZones()
+	assert.Equal(t, sql.ErrConnDone, err)
+}
+
+func TestGetSettings(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db, LeapDayMar1)
+
+	query := regexp.QuoteMeta("SELECT userID, notifyHour, quietFrom, quietTo, mutedUntil, digest, summary, greet FROM settings WHERE userID = ?")
+	columns := []string{"userID", "notifyHour", "quietFrom", "quietTo", "mutedUntil", "digest", "summary", "greet"}
+
+	mock.ExpectQuery(query).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 8, 22, 7, nil, true, SummaryWeekly, true))
+	settings, err := repo.GetSettings(1)
+	assert.NoError(t, err)
+	assert.Equal(t, &Settings{NotifyHour: 8, QuietFrom: 22, QuietTo: 7, Digest: true, Summary: SummaryWeekly, Greet: true}, settings)
+
+	mock.ExpectQuery(query).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(columns))
+	settings, err = repo.GetSettings(1)
+	assert.NoError(t, err)
+	assert.Equal(t, &Settings{NotifyHour: DefaultHour}, settings)
+
+	mock.ExpectQuery(query).
+		WithArgs(1).
+		WillReturnError(sql.ErrConnDone)
+	_, err = repo.GetSettings(1)
+	assert.Equal(t, sql.ErrConnDone, err)
+}
+
+func TestSaveSettings(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db, LeapDayMar1)
+
+	query := regexp.QuoteMeta("INSERT INTO settings (`userID`, `notifyHour`, `quietFrom`, `quietTo`, `mutedUntil`, `digest`, `summary`, `greet`) VALUES (?, ?, ?, ?, ?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE `notifyHour` = VALUES(`notifyHour`), `quietFrom` = VALUES(`quietFrom`), `quietTo` = VALUES(`quietTo`), " +
+		"`mutedUntil` = VALUES(`mutedUntil`), `digest` = VALUES(`digest`), `summary` = VALUES(`summary`), `greet` = VALUES(`greet`)")
+
+	tests := []struct {
+		name        string
+		settings    Settings
+		mockFunc    func()
+		expectedErr error
+	}{
+		{
+			name:     "Save settings",
+			settings: Settings{NotifyHour: 8, QuietFrom: 22, QuietTo: 7, MutedUntil: "2024-07-01", Digest: true, Summary: SummaryMonthly, Greet: true},
+			mockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs(1, 8, 22, 7, "2024-07-01", true, SummaryMonthly, true).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expectedErr: nil,
+		},
+		{
+			name:     "Reset settings",
+			settings: DefaultSettings(),
+			mockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs(1, DefaultHour, 0, 0, nil, false, "", false).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			expectedErr: nil,
+		},
+		{
+			name:        "Bad hour",
+			settings:    Settings{NotifyHour: 24},
+			mockFunc:    func() {},
+			expectedErr: ErrBadSettings,
+		},
+		{
+			name:     "Insert error",
+			settings: DefaultSettings(),
+			mockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs(1, DefaultHour, 0, 0, nil, false, "", false).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc()
+			err := repo.SaveSettings(1, tt.settings)
+			assert.Equal(t, tt.expectedErr, err)
+		})
+	}
+}
+
+func TestGetSummarySubscribers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db, LeapDayMar1)
+
+	query := regexp.QuoteMeta(`
+		SELECT u.id, u.username, u.firstname, u.middlename, u.lastname, u.birthday, u.telegram, u.telegramID, u.timezone, u.language,
+			st.userID, st.notifyHour, st.quietFrom, st.quietTo, st.mutedUntil, st.digest, st.summary, st.greet
+		FROM users u
+		JOIN settings st ON u.id = st.userID
+		WHERE st.summary = ? AND u.timezone = ?`)
+	columns := []string{"id", "username", "firstname", "middlename", "lastname", "birthday", "telegram", "telegramID", "timezone", "language",
+		"userID", "notifyHour", "quietFrom", "quietTo", "mutedUntil", "digest", "summary", "greet"}
+
+	mock.ExpectQuery(query).
+		WithArgs(SummaryWeekly, "").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(2, "user2", "John", "M", "Doe", "1990-01-01", "@john", 1234, "", "en", 2, -1, 0, 0, nil, false, SummaryWeekly, false))
+	users, err := repo.GetSummarySubscribers(SummaryWeekly, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []User{{ID: 2, Username: "user2", FirstName: "John", MiddleName: "M", LastName: "Doe", Birthday: "1990-01-01",
+		Telegram: "@john", TelegramID: 1234, Language: "en", Settings: &Settings{NotifyHour: DefaultHour, Summary: SummaryWeekly}}}, users)
+
+	mock.ExpectQuery(query).
+		WithArgs(SummaryMonthly, "").
+		WillReturnRows(sqlmock.NewRows(columns))
+	_, err = repo.GetSummarySubscribers(SummaryMonthly, "")
+	assert.Equal(t, ErrNoUser, err)
+
+	mock.ExpectQuery(query).
+		WithArgs(SummaryWeekly, "").
+		WillReturnError(sql.ErrConnDone)
+	_, err = repo.GetSummarySubscribers(SummaryWeekly, "")
+	assert.Equal(t, sql.ErrConnDone, err)
+}
+
+func TestGetCelebrantsToGreet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db, LeapDayMar1)
+
+	query := func(birthday string) string {
+		return regexp.QuoteMeta(`
+		SELECT u.id, u.username, u.firstname, u.middlename, u.lastname, u.birthday, u.telegram, u.telegramID, u.timezone,
+			u.department, u.language, u.gender,
+			st.userID, st.notifyHour, st.quietFrom, st.quietTo, st.mutedUntil, st.digest, st.summary, st.greet
+		FROM users u
+		LEFT JOIN settings st ON u.id = st.userID
+		WHERE (st.greet OR EXISTS (SELECT 1 FROM congratulations c WHERE c.userID = u.id))
+			AND u.timezone = ? AND (` + birthday + `)`)
+	}
+	columns := []string{"id", "username", "firstname", "middlename", "lastname", "birthday", "telegram", "telegramID", "timezone",
+		"department", "language", "gender",
+		"userID", "notifyHour", "quietFrom", "quietTo", "mutedUntil", "digest", "summary", "greet"}
+
+	mock.ExpectQuery(query("MONTH(u.birthday) = ? AND DAY(u.birthday) = ?")).
+		WithArgs("Asia/Vladivostok", 6, 11).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(2, "user2", "John", "M", "Doe", "1990-06-11", "@john", 1234, "Asia/Vladivostok", "QA", "en", GenderMale, 2, 8, 0, 0, nil, false, "", true))
+	users, err := repo.GetCelebrantsToGreet(time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC), "Asia/Vladivostok")
+	assert.NoError(t, err)
+	assert.Equal(t, []User{{ID: 2, Username: "user2", FirstName: "John", MiddleName: "M", LastName: "Doe", Birthday: "1990-06-11",
+		Telegram: "@john", TelegramID: 1234, TimeZone: "Asia/Vladivostok", Department: "QA", Language: "en", Gender: GenderMale,
+		Settings: &Settings{NotifyHour: 8, Greet: true}}}, users)
+
+	mock.ExpectQuery(query("MONTH(u.birthday) = ? AND DAY(u.birthday) = ? OR (MONTH(u.birthday) = 2 AND DAY(u.birthday) = 29)")).
+		WithArgs("", 3, 1).
+		WillReturnRows(sqlmock.NewRows(columns))
+	_, err = repo.GetCelebrantsToGreet(time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC), "")
+	assert.Equal(t, ErrNoUser, err)
+
+	repo.LeapDay = LeapDayFeb28
+	mock.ExpectQuery(query("MONTH(u.birthday) = ? AND DAY(u.birthday) = ? OR (MONTH(u.birthday) = 2 AND DAY(u.birthday) = 29)")).
+		WithArgs("", 2, 28).
+		WillReturnRows(sqlmock.NewRows(columns))
+	_, err = repo.GetCelebrantsToGreet(time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC), "")
+	assert.Equal(t, ErrNoUser, err)
+	repo.LeapDay = LeapDayMar1
+
+	mock.ExpectQuery(query("MONTH(u.birthday) = ? AND DAY(u.birthday) = ?")).
+		WithArgs("", 6, 11).
+		WillReturnError(sql.ErrConnDone)
+	_, err = repo.GetCelebrantsToGreet(time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC), "")
+	assert.Equal(t, sql.ErrConnDone, err)
+}
+
+func TestSaveCongratulation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db, LeapDayMar1)
+
+	subscription := regexp.QuoteMeta("SELECT id FROM subscribes WHERE `userID` = ? and `subscriberID` = ?")
+	query := regexp.QuoteMeta("INSERT INTO congratulations (`userID`, `authorID`, `anonymous`, `text`) VALUES (?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE `anonymous` = VALUES(`anonymous`), `text` = VALUES(`text`)")
+
+	tests := []struct {
+		name           string
+		congratulation Congratulation
+		mockFunc       func()
+		expectedErr    error
+	}{
+		{
+			name:           "Save congratulation",
+			congratulation: Congratulation{UserID: 2, AuthorID: 1, Anonymous: true, Text: " Happy birthday! "},
+			mockFunc: func() {
+				mock.ExpectQuery(subscription).
+					WithArgs(2, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+				mock.ExpectExec(query).
+					WithArgs(2, 1, true, "Happy birthday!").
+					WillReturnResult(sqlmock.NewResult(3, 1))
+			},
+			expectedErr: nil,
+		},
+		{
+			name:           "Not subscribed",
+			congratulation: Congratulation{UserID: 2, AuthorID: 1, Text: "Happy birthday!"},
+			mockFunc: func() {
+				mock.ExpectQuery(subscription).
+					WithArgs(2, 1).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: ErrNoSubscription,
+		},
+		{
+			name:           "Empty text",
+			congratulation: Congratulation{UserID: 2, AuthorID: 1, Text: "  "},
+			mockFunc:       func() {},
+			expectedErr:    ErrBadCongratulation,
+		},
+		{
+			name:           "Text too long",
+			congratulation: Congratulation{UserID: 2, AuthorID: 1, Text: strings.Repeat("я", MaxCongratulationLength+1)},
+			mockFunc:       func() {},
+			expectedErr:    ErrBadCongratulation,
+		},
+		{
+			name:           "Insert error",
+			congratulation: Congratulation{UserID: 2, AuthorID: 1, Text: "Happy birthday!"},
+			mockFunc: func() {
+				mock.ExpectQuery(subscription).
+					WithArgs(2, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+				mock.ExpectExec(query).
+					WithArgs(2, 1, false, "Happy birthday!").
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc()
+			err := repo.SaveCongratulation(tt.congratulation)
+			assert.Equal(t, tt.expectedErr, err)
+		})
+	}
+}
+
+func TestGetCongratulations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewMysqlRepo(db, LeapDayMar1)
+
+	query := regexp.QuoteMeta(`
+		SELECT c.id, c.userID, c.authorID, u.firstname, u.lastname, c.anonymous, c.text
+		FROM congratulations c
+		JOIN users u ON u.id = c.authorID
+		WHERE c.userID = ?
+		ORDER BY c.id`)
+	columns := []string{"id", "userID", "authorID", "firstname", "lastname", "anonymous", "text"}
+
+	mock.ExpectQuery(query).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(3, 2, 1, "Jane", "Smith", false, "Happy birthday!").
+			AddRow(4, 2, 5, "Ivan", "Petrov", true, "Всего наилучшего!"))
+	congratulations, err := repo.GetCongratulations(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []Congratulation{
+		{ID: 3, UserID: 2, AuthorID: 1, Author: "Jane Smith", Text: "Happy birthday!"},
+		{ID: 4, UserID: 2, AuthorID: 5, Author: "Ivan Petrov", Anonymous: true, Text: "Всего наилучшего!"},
+	}, congratulations)
+
+	mock.ExpectQuery(query).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows(columns))
+	congratulations, err = repo.GetCongratulations(2)
+	assert.NoError(t, err)
+	assert.Empty(t, congratulations)
+
+	mock.ExpectQuery(query).
+		WithArgs(2).
+		WillReturnError(sql.ErrConnDone)
+	_, err = repo.GetCongratulations(2)
+	assert.Equal(t, sql.ErrConnDone, err)
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM congratulations WHERE `id` = ?")).
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.DeleteCongratulation(3))
+}
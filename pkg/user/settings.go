@@ -0,0 +1,98 @@
+package user
+
+import (
+	"time"
+)
+
+// DefaultHour - значение Settings.NotifyHour, при котором напоминания приходят во время рассылки сервиса.
+const DefaultHour = -1
+
+// Периоды сводки дней рождения, которая приходит вместо отдельных напоминаний.
+const (
+	SummaryNone    = ""
+	SummaryWeekly  = "weekly"  // По понедельникам о днях рождения на неделе.
+	SummaryMonthly = "monthly" // Первого числа о днях рождения в месяце.
+)
+
+// Settings - настройки напоминаний подписчика. Часы считаются по его часовому поясу.
+type Settings struct {
+	// NotifyHour - час, в который приходят напоминания, или DefaultHour.
+	NotifyHour int `json:"notifyHour"`
+	// QuietFrom и QuietTo - тихие часы [QuietFrom, QuietTo), могут переходить через полночь.
+	// Равные значения отключают тихие часы.
+	QuietFrom int `json:"quietFrom"`
+	QuietTo   int `json:"quietTo"`
+	// MutedUntil - по какой день включительно (YYYY-MM-DD) напоминания не приходят; пустая строка - не отключены.
+	MutedUntil string `json:"mutedUntil"`
+	// Digest - присылать напоминания за день одним сообщением.
+	Digest bool `json:"digest"`
+	// Summary - период сводки; если он задан, отдельные напоминания не приходят.
+	Summary string `json:"summary"`
+	// Greet - в свой день рождения получить поздравление от бота и поздравления, которые оставили подписчики.
+	Greet bool `json:"greet"`
+}
+
+// DefaultSettings возвращает настройки пользователя, который их не менял.
+func DefaultSettings() Settings {
+	return Settings{NotifyHour: DefaultHour}
+}
+
+// CheckSettings проверяет часы, дату отключения напоминаний и период сводки.
+func CheckSettings(s Settings) error {
+	if s.NotifyHour < DefaultHour || s.NotifyHour > 23 {
+		return ErrBadSettings
+	}
+	if s.QuietFrom < 0 || s.QuietFrom > 23 || s.QuietTo < 0 || s.QuietTo > 23 {
+		return ErrBadSettings
+	}
+	if s.MutedUntil != "" {
+		if _, err := time.Parse(BirthdayLayout, s.MutedUntil); err != nil {
+			return ErrBadSettings
+		}
+	}
+	switch s.Summary {
+	case SummaryNone, SummaryWeekly, SummaryMonthly:
+		return nil
+	default:
+		return ErrBadSettings
+	}
+}
+
+// Muted сообщает, отключены ли напоминания в день day.
+func (s Settings) Muted(day time.Time) bool {
+	return s.MutedUntil != "" && day.Format(BirthdayLayout) <= s.MutedUntil
+}
+
+// DeliverAt возвращает, когда отправить напоминание за день day: в NotifyHour, а если он не задан - в at.
+// Время не раньше now и переносится на конец тихих часов.
+func (s Settings) DeliverAt(day, at, now time.Time) time.Time {
+	t := at
+	if s.NotifyHour != DefaultHour {
+		t = time.Date(day.Year(), day.Month(), day.Day(), s.NotifyHour, 0, 0, 0, day.Location())
+	}
+	if t.Before(now) {
+		t = now
+	}
+
+	if !s.quiet(t.Hour()) {
+		return t
+	}
+	end := time.Date(t.Year(), t.Month(), t.Day(), s.QuietTo, 0, 0, 0, t.Location())
+	if !end.After(t) {
+		// Тихие часы переходят через полночь и закончатся завтра.
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// quiet сообщает, попадает ли час hour в тихие часы.
+func (s Settings) quiet(hour int) bool {
+	switch {
+	case s.QuietFrom == s.QuietTo:
+		return false
+	case s.QuietFrom < s.QuietTo:
+		return hour >= s.QuietFrom && hour < s.QuietTo
+	default:
+		return hour >= s.QuietFrom || hour < s.QuietTo
+	}
+}
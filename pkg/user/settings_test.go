@@ -0,0 +1,83 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSettings(t *testing.T) {
+	assert.NoError(t, CheckSettings(DefaultSettings()))
+	assert.NoError(t, CheckSettings(Settings{NotifyHour: 0, QuietFrom: 23, QuietTo: 7, MutedUntil: "2024-07-01"}))
+	assert.Equal(t, ErrBadSettings, CheckSettings(Settings{NotifyHour: -2}))
+	assert.Equal(t, ErrBadSettings, CheckSettings(Settings{NotifyHour: 24}))
+	assert.Equal(t, ErrBadSettings, CheckSettings(Settings{NotifyHour: DefaultHour, QuietTo: 24}))
+	assert.Equal(t, ErrBadSettings, CheckSettings(Settings{NotifyHour: DefaultHour, MutedUntil: "01.07.2024"}))
+	assert.NoError(t, CheckSettings(Settings{NotifyHour: DefaultHour, Summary: SummaryWeekly}))
+	assert.Equal(t, ErrBadSettings, CheckSettings(Settings{NotifyHour: DefaultHour, Summary: "daily"}))
+}
+
+func TestSettingsMuted(t *testing.T) {
+	day := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+
+	assert.False(t, DefaultSettings().Muted(day))
+	assert.True(t, Settings{MutedUntil: "2024-06-10"}.Muted(day))
+	assert.False(t, Settings{MutedUntil: "2024-06-09"}.Muted(day))
+}
+
+func TestSettingsDeliverAt(t *testing.T) {
+	day := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	at := day.Add(9 * time.Hour)
+	hour := func(h int) time.Time { return day.Add(time.Duration(h) * time.Hour) }
+
+	tests := []struct {
+		name     string
+		settings Settings
+		now      time.Time
+		expected time.Time
+	}{
+		{
+			name:     "Service time",
+			settings: DefaultSettings(),
+			now:      day,
+			expected: at,
+		},
+		{
+			name:     "Preferred hour",
+			settings: Settings{NotifyHour: 12},
+			now:      day,
+			expected: hour(12),
+		},
+		{
+			name:     "Preferred hour has passed",
+			settings: Settings{NotifyHour: 8},
+			now:      hour(10),
+			expected: hour(10),
+		},
+		{
+			name:     "Quiet hours",
+			settings: Settings{NotifyHour: DefaultHour, QuietFrom: 8, QuietTo: 11},
+			now:      day,
+			expected: hour(11),
+		},
+		{
+			name:     "Quiet hours over midnight",
+			settings: Settings{NotifyHour: 23, QuietFrom: 22, QuietTo: 7},
+			now:      day,
+			expected: hour(24 + 7),
+		},
+		{
+			name:     "Outside quiet hours",
+			settings: Settings{NotifyHour: DefaultHour, QuietFrom: 22, QuietTo: 7},
+			now:      day,
+			expected: at,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.settings.DeliverAt(day, at, tt.now))
+		})
+	}
+}
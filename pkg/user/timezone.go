@@ -0,0 +1,21 @@
+package user
+
+import (
+	"time"
+)
+
+// CheckTimeZone проверяет, что name - часовой пояс из базы IANA, например Europe/Moscow.
+// Пустая строка допустима и означает часовой пояс сервиса.
+func CheckTimeZone(name string) error {
+	if name == "" {
+		return nil
+	}
+	// Local зависит от сервера, поэтому пользователю его задать нельзя.
+	if name == "Local" {
+		return ErrBadTimeZone
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return ErrBadTimeZone
+	}
+	return nil
+}
@@ -1,5 +1,9 @@
 package user
 
+import (
+	"time"
+)
+
 type User struct {
 	ID         int64  `json:"id"`
 	Username   string `json:"username"`
@@ -10,14 +14,40 @@ type User struct {
 	Birthday   string `json:"birthday"`
 	Telegram   string `json:"telegram"`
 	TelegramID int64  `json:"telegramid"`
+	// TimeZone - часовой пояс IANA; пустая строка - часовой пояс сервиса.
+	TimeZone string `json:"timezone"`
+	// Department - отдел пользователя; по нему выбираются шаблоны поздравлений для его команды.
+	Department string `json:"department"`
+	// Language - язык сообщений (например, ru или en); пустая строка - язык телеграма пользователя,
+	// а если он неизвестен - язык сервиса.
+	Language string `json:"language"`
+	// Gender - род: GenderMale, GenderFemale или GenderUnknown, если пользователь его не указал.
+	Gender string `json:"gender"`
+	// Settings - настройки напоминаний; nil, если пользователь их не менял. Заполняется только для подписчиков,
+	// которым нужно отправить напоминание.
+	Settings *Settings `json:"-"`
 }
 
 type UserRepo interface {
 	Authorize(username, pass string) (*User, error)
-	MakeUser(username, pass, firstname, middlename, lastname, birthday, telegram string) (*User, error)
+	MakeUser(u User) (*User, error)
 	GetUsers() ([]User, error)
 	Subscribe(userID int64, subscriberID int64, typeOf int) (*User, error)
 	GetSubscribedUsers(userID int64) ([]User, error)
+	GetSubscriptions(subscriberID int64) ([]User, error)
+	GetSubscribersToRemind(userID int64, daysBefore int, timezone string) ([]User, error)
+	SetReminders(userID int64, subscriberID int64, reminders []int) ([]int, error)
 	GetUserByTelegram(telegram string) (*User, error)
+	GetUpcomingBirthdays(from time.Time, days int, subscriberID int64) ([]User, error)
 	UpdateUser(telegramID int64, telegram string) error
+	SetTimeZone(userID int64, timezone string) error
+	SetLanguage(userID int64, language string) error
+	GetTimeZones() ([]string, error)
+	GetSettings(userID int64) (*Settings, error)
+	SaveSettings(userID int64, s Settings) error
+	GetSummarySubscribers(period, timezone string) ([]User, error)
+	GetCelebrantsToGreet(day time.Time, timezone string) ([]User, error)
+	SaveCongratulation(c Congratulation) error
+	GetCongratulations(userID int64) ([]Congratulation, error)
+	DeleteCongratulation(id int64) error
 }